@@ -1,91 +1,453 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
 
 	"mp3len"
+	"mp3len/openers"
 )
 
 var errInvalidInput = fmt.Errorf("first argument must be a path or HTTP URL")
 
-func openFile(location *url.URL) (io.ReadCloser, int64, error) {
-	f, err := os.Open(location.Path)
+type authFlags struct {
+	bearer string
+	basic  string
+	cookie string
+	netrc  bool
+}
 
-	if err != nil {
-		return nil, 0, err
-	}
+// decorator returns a RequestDecorator adding credentials to requests sent to
+// location, or nil when no credentials are configured.
+func (a *authFlags) decorator(location *url.URL) (mp3len.RequestDecorator, error) {
+	var user, password string
+	var hasBasic bool
 
-	stat, err := f.Stat()
+	if a.basic != "" {
+		parts := strings.SplitN(a.basic, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("-basic must be in the form user:pass")
+		}
+		user, password, hasBasic = parts[0], parts[1], true
+	} else if a.netrc {
+		entry, err := lookupNetrc(defaultNetrcPath(), location.Hostname())
+		if err != nil {
+			return nil, fmt.Errorf("reading netrc: %v", err)
+		}
+		if entry != nil {
+			user, password, hasBasic = entry.login, entry.password, true
+		}
+	}
 
-	if err != nil {
-		return nil, 0, err
+	if a.bearer == "" && a.cookie == "" && !hasBasic {
+		return nil, nil
 	}
 
-	length := stat.Size()
+	return func(req *http.Request) {
+		if a.bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+a.bearer)
+		} else if hasBasic {
+			req.SetBasicAuth(user, password)
+		}
 
-	return f, length, nil
+		if a.cookie != "" {
+			req.Header.Set("Cookie", a.cookie)
+		}
+	}, nil
 }
 
-func openHTTP(location *url.URL) (io.ReadCloser, int64, error) {
-	req, err := http.NewRequest("GET", location.String(), nil)
-	if err != nil {
-		return nil, 0, err
+// processInput measures the input at location. Files and HTTP URLs are read
+// by the library, with seeks and ranges, and other schemes by the opener
+// registered for them, if any.
+func processInput(location *url.URL, opts []mp3len.Option) (*mp3len.Metadata, error) {
+	switch location.Scheme {
+	case "http", "https":
+		return mp3len.GetInfoFromURL(location.String(), opts...)
+	case "file", "":
+		return mp3len.GetInfoFromFile(location.Path, opts...)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	rc, size, err := openers.Open(context.Background(), location)
 
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	return resp.Body, resp.ContentLength, nil
+	defer rc.Close()
+
+	return mp3len.GetInfo(rc, size, opts...)
 }
 
-func processInput(location *url.URL) (*mp3len.Metadata, error) {
-	var r io.ReadCloser
-	var totalLength int64
-	var err error
+// forEachInput calls fn with arg, or with every MP3 file under arg if it's a
+// local directory.
+func forEachInput(arg string, fn func(input string)) error {
+	stat, err := os.Stat(arg)
 
-	if location.Scheme == "http" || location.Scheme == "https" {
-		r, totalLength, err = openHTTP(location)
-	} else if location.Scheme == "file" || location.Scheme == "" {
-		r, totalLength, err = openFile(location)
-	} else {
-		return nil, errInvalidInput
+	if err != nil || !stat.IsDir() {
+		// let processInput report errors, URLs are not on disk anyway
+		fn(arg)
+		return nil
 	}
 
+	return filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".mp3") {
+			fn(path)
+		}
+
+		return nil
+	})
+}
+
+// timestampValue is a flag.Value for times in any form mp3len.ParseTimestamp
+// accepts, e.g. "1:30:00", "5400" or "1h30m".
+type timestampValue time.Duration
+
+func (v *timestampValue) String() string {
+	return time.Duration(*v).String()
+}
+
+func (v *timestampValue) Set(s string) error {
+	d, err := mp3len.ParseTimestamp(s)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	defer r.Close()
+	*v = timestampValue(d)
+	return nil
+}
 
-	return mp3len.GetInfo(r, totalLength)
+// timestamp defines a flag like flag.Duration, parsed with
+// mp3len.ParseTimestamp.
+func timestamp(name string, value time.Duration, usage string) *time.Duration {
+	flag.Var((*timestampValue)(&value), name, usage)
+	return &value
+}
+
+type jsonResult struct {
+	Path     string         `json:"path"`
+	Metadata *mp3len.Report `json:"metadata,omitempty"`
+	Error    string         `json:"error,omitempty"`
 }
 
 func main() {
 	verbose := flag.Bool("verbose", false, "show verbose info such as id3 tags and mp3 format")
 
-	flag.Parse()
+	var auth authFlags
+	flag.StringVar(&auth.bearer, "bearer", "", "send `TOKEN` as a bearer token with HTTP requests")
+	flag.StringVar(&auth.basic, "basic", "", "send `user:pass` as HTTP basic authentication")
+	flag.StringVar(&auth.cookie, "cookie", "", "send `name=value` as the Cookie header with HTTP requests")
+	flag.BoolVar(&auth.netrc, "netrc", false, "read HTTP basic authentication for the host from ~/.netrc")
+	strict := flag.Bool("strict", false, "fail instead of warning when the result looks wrong")
+	fullScan := flag.Bool("full-scan", false, "read the whole input and count every frame for an exact duration")
+	samples := flag.Bool("samples", false, "print the total PCM sample count (implies -full-scan)")
+	maxDuration := timestamp("max-duration", mp3len.DefaultMaxDuration, "longest `time` considered plausible, e.g. 24h, 3:00:00 or 10800")
+	jsonOutput := flag.Bool("json", false, "print results as JSON, one object per line")
+	showStats := flag.Bool("stats", false, "print a summary of formats across all inputs instead of each result")
+	pretty := flag.Bool("pretty", false, "print aligned, colorized columns for reading in a terminal")
+	showTotal := flag.Bool("total", false, "print the total duration of all inputs at the end")
+	requireSpec := flag.String("require", "", "fail unless the audio meets `requirements`, e.g. mpeg1,layer3,minrate=128,minfreq=44100")
+	watch := flag.Bool("watch", false, "keep polling the given directories and measure new files as they arrive, until interrupted")
+	pollInterval := timestamp("poll-interval", 2*time.Second, "`interval` at which -watch scans the directories")
+	quietPeriod := timestamp("quiet-period", 5*time.Second, "`time` a file must stay unchanged before -watch measures it")
+	templateText := flag.String("template", "", "print each result with a Go `template`, e.g. '{{.Duration}} {{.Bitrate}}kbps'")
+	sortKey := flag.String("sort", "", "print the results at the end, ordered by `key`: path, duration, size or bitrate, with failures last")
+	reverse := flag.Bool("reverse", false, "with -sort, print in descending order")
+	showTags := flag.Bool("tags", false, "print the title, artist and other tag values after each result")
+	artwork := flag.Bool("artwork", false, "with -tags, also read embedded pictures and other large binary frames, skipped by default")
+	var seconds bool
+	flag.BoolVar(&seconds, "seconds", false, "print only the duration as a whole number of seconds, e.g. 2533, without warnings")
+	flag.BoolVar(&seconds, "quiet", false, "same as -seconds")
 
-	location, err := url.Parse(flag.Arg(0))
+	flag.Parse()
 
-	if location == nil || location.Path == "" || err != nil {
+	if flag.NArg() == 0 {
 		fmt.Fprintln(os.Stderr, errInvalidInput)
 		os.Exit(1)
 	}
 
-	info, err := processInput(location)
+	if *sortKey != "" {
+		if err := parseSortKey(*sortKey); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		if *watch {
+			fmt.Fprintln(os.Stderr, "-sort can't be used with -watch, whose results never end")
+			os.Exit(1)
+		}
+	} else if *reverse {
+		fmt.Fprintln(os.Stderr, "-reverse needs -sort")
+		os.Exit(1)
+	}
+
+	if seconds && *samples {
+		fmt.Fprintln(os.Stderr, "-seconds and -samples can't be used together")
+		os.Exit(1)
+	}
+
+	var tmpl *template.Template
+	if *templateText != "" {
+		var err error
+		if tmpl, err = parseOutputTemplate(*templateText); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	opts := []mp3len.Option{mp3len.WithMaxDuration(*maxDuration)}
+
+	if *requireSpec != "" {
+		require, err := parseRequire(*requireSpec)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts = append(opts, mp3len.WithRequire(require))
+	}
+
+	if *strict {
+		opts = append(opts, mp3len.WithStrict())
+	}
+
+	if *fullScan || *samples {
+		opts = append(opts, mp3len.WithFullScan())
+	}
+
+	if *verbose {
+		logger := log.New(os.Stderr, "", 0)
+		opts = append(opts, mp3len.WithLogger(logger.Printf))
+	}
+
+	var stats *mp3len.Stats
+	if *showStats {
+		stats = mp3len.NewStats()
+	}
+
+	multiple := flag.NArg() > 1
+	failed := false
+	encoder := json.NewEncoder(os.Stdout)
+
+	var formatter *prettyFormatter
+	if *pretty && !*jsonOutput && stats == nil {
+		formatter = &prettyFormatter{width: terminalWidth(), color: useColor(os.Stdout)}
+	}
+
+	var total time.Duration
+	var totalCount int
+
+	// results kept for -sort
+	var results []measured
+
+	emit := func(input string, info *mp3len.Metadata, err error) {
+		var report *mp3len.Report
+		if err == nil {
+			report = info.Report()
+		}
+
+		switch {
+		case *jsonOutput && stats == nil:
+			result := jsonResult{Path: input, Metadata: report}
+			if err != nil {
+				result = jsonResult{Path: input, Error: err.Error()}
+			}
+			encoder.Encode(result)
+			return
+		case formatter != nil:
+			row := prettyRow{Path: input, Err: err}
+			if err == nil {
+				row.Duration = report.Duration
+				row.BitRate = report.Header.BitRate
+				row.VBR = report.VBR
+				row.Warnings = report.Warnings
+			}
+			fmt.Print(formatter.Row(row))
+			return
+		case err != nil && multiple:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", input, err)
+			return
+		case err != nil:
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		if !seconds {
+			for _, warning := range report.Warnings {
+				fmt.Fprintln(os.Stderr, "WARNING:", warning)
+			}
+		}
+
+		if stats != nil {
+			return
+		}
+
+		if tmpl != nil {
+			output, err := executeTemplate(tmpl, input, report)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+				return
+			}
+			fmt.Println(output)
+			return
+		}
+
+		output := report.Duration.String()
+		if *verbose {
+			output = report.String()
+		}
+		if *samples {
+			output = fmt.Sprint(report.TotalSamples)
+		} else if seconds {
+			output = formatSeconds(report.Duration)
+		}
+
+		if multiple {
+			fmt.Printf("%s\t%s\n", input, output)
+		} else {
+			fmt.Println(output)
+		}
+
+		if *showTags {
+			indent := ""
+			if multiple {
+				indent = "\t"
+			}
+			tag, err := readLeadingTag(input, *artwork)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", input, err)
+			}
+			fmt.Print(formatID3v2Tag(tag, indent))
+			fmt.Print(formatTags(report.Tags, indent))
+		}
+	}
+
+	measure := func(input string) {
+		info, err := measureInput(input, opts, &auth)
+
+		if stats != nil {
+			if err != nil {
+				stats.AddFailure()
+			} else {
+				stats.Add(info)
+			}
+		}
+
+		if err != nil {
+			failed = true
+		} else {
+			total += info.Duration()
+			totalCount++
+		}
+
+		if *sortKey != "" {
+			results = append(results, measured{input: input, info: info, err: err, size: localSize(input)})
+			return
+		}
+
+		emit(input, info, err)
+	}
+
+	if *watch {
+		multiple = true
+
+		interrupted := make(chan os.Signal, 1)
+		signal.Notify(interrupted, os.Interrupt)
+
+		stop := make(chan struct{})
+		go func() {
+			<-interrupted
+			close(stop)
+		}()
+
+		newWatcher(flag.Args(), *pollInterval, *quietPeriod).run(stop, measure, func(err error) {
+			fmt.Fprintln(os.Stderr, err)
+		})
+	} else {
+		for _, arg := range flag.Args() {
+			if stat, err := os.Stat(arg); err == nil && stat.IsDir() {
+				multiple = true
+			}
+
+			if err := forEachInput(arg, measure); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+			}
+		}
+	}
+
+	sortMeasured(results, *sortKey, *reverse)
+
+	for _, result := range results {
+		emit(result.input, result.info, result.err)
+	}
+
+	if stats != nil {
+		if *jsonOutput {
+			encoder.Encode(stats)
+		} else {
+			fmt.Print(stats.String())
+		}
+	}
+
+	if *showTotal && stats == nil && !*jsonOutput {
+		if formatter != nil {
+			fmt.Print(formatter.Footer(total, totalCount))
+		} else {
+			fmt.Printf("Total: %s (%d files)\n", total, totalCount)
+		}
+	}
+
+	if failed {
 		os.Exit(1)
 	}
+}
+
+// formatSeconds formats d for -seconds, as a whole number of seconds.
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprint(int64(d.Round(time.Second).Seconds()))
+}
+
+// measureInput measures input, a path on disk, such as those found walking a
+// directory, or else a URL.
+func measureInput(input string, opts []mp3len.Option, auth *authFlags) (*mp3len.Metadata, error) {
+	// taken as is, a path may not parse as a URL, e.g. "track #1.mp3"
+	if _, err := os.Stat(input); err == nil {
+		return mp3len.GetInfoFromFile(input, opts...)
+	}
+
+	location, err := url.Parse(input)
+
+	// e.g. gs://bucket has no path
+	if err != nil || location.Path == "" && location.Host == "" {
+		return nil, errInvalidInput
+	}
+
+	if location.Scheme == "http" || location.Scheme == "https" {
+		decorate, err := auth.decorator(location)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if decorate != nil {
+			opts = append(opts[:len(opts):len(opts)], mp3len.WithRequestDecorator(decorate))
+		}
+	}
 
-	fmt.Println(info.String(*verbose))
+	return processInput(location, opts)
 }
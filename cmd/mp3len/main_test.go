@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mp3len/openers"
+)
+
+func init() {
+	// 100 frames of MPEG-1 Layer III at 128 kbps, 44.1 kHz
+	frame := make([]byte, 417)
+	binary.BigEndian.PutUint32(frame, 0xFFFB9044)
+	episode := bytes.Repeat(frame, 100)
+
+	openers.Register("mem", func(_ context.Context, u *url.URL) (io.ReadCloser, int64, error) {
+		if u.Host != "episode.mp3" {
+			return nil, 0, os.ErrNotExist
+		}
+		return ioutil.NopCloser(bytes.NewReader(episode)), int64(len(episode)), nil
+	})
+}
+
+func Test_measureInput_Openers(t *testing.T) {
+	metadata, err := measureInput("mem://episode.mp3", nil, &authFlags{})
+
+	if err != nil {
+		t.Fatalf("measureInput() error = %v", err)
+	}
+
+	if metadata.Duration() != 2606*time.Millisecond {
+		t.Errorf("measureInput() Duration() = %v, want %v", metadata.Duration(), 2606*time.Millisecond)
+	}
+
+	if _, err := measureInput("mem://missing.mp3", nil, &authFlags{}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("measureInput() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func Test_measureInput_LocalPaths(t *testing.T) {
+	frame := make([]byte, 417)
+	binary.BigEndian.PutUint32(frame, 0xFFFB9044)
+	episode := bytes.Repeat(frame, 100)
+
+	dir := t.TempDir()
+
+	for _, name := range []string{"track #1.mp3", "100%zz.mp3"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), episode, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var inputs []string
+	if err := forEachInput(dir, func(input string) { inputs = append(inputs, input) }); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inputs) != 2 {
+		t.Fatalf("forEachInput() found %v, want 2 files", inputs)
+	}
+
+	for _, input := range inputs {
+		metadata, err := measureInput(input, nil, &authFlags{})
+
+		if err != nil {
+			t.Errorf("measureInput(%q) error = %v", input, err)
+			continue
+		}
+
+		if metadata.Duration() != 2606*time.Millisecond {
+			t.Errorf("measureInput(%q) Duration() = %v, want %v", input, metadata.Duration(), 2606*time.Millisecond)
+		}
+	}
+}
+
+func Test_measureInput_UnsupportedScheme(t *testing.T) {
+	_, err := measureInput("s3://bucket/episode.mp3", nil, &authFlags{})
+
+	var unsupported openers.ErrUnsupportedScheme
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("measureInput() error = %v, want openers.ErrUnsupportedScheme", err)
+	}
+
+	if !strings.Contains(err.Error(), "file, http, https, mem") {
+		t.Errorf("measureInput() error = %q, want the registered schemes", err)
+	}
+}
+
+func Test_formatSeconds(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{42*time.Minute + 13*time.Second, "2533"},
+		{2606 * time.Millisecond, "3"},
+		{2499 * time.Millisecond, "2"},
+		{0, "0"},
+	}
+	for _, tt := range tests {
+		if got := formatSeconds(tt.d); got != tt.want {
+			t.Errorf("formatSeconds(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
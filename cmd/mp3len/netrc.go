@@ -0,0 +1,88 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type netrcEntry struct {
+	login    string
+	password string
+}
+
+// lookupNetrc finds the credentials for host in the netrc file at path. The
+// "default" entry is used when no machine matches.
+func lookupNetrc(path string, host string) (*netrcEntry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNetrc(string(data), host), nil
+}
+
+func parseNetrc(data string, host string) *netrcEntry {
+	var found, fallback *netrcEntry
+	var current *netrcEntry
+
+	fields := strings.Fields(data)
+
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			current = nil
+			if i+1 < len(fields) {
+				i++
+				if fields[i] == host && found == nil {
+					found = new(netrcEntry)
+					current = found
+				}
+			}
+		case "default":
+			current = nil
+			if fallback == nil {
+				fallback = new(netrcEntry)
+				current = fallback
+			}
+		case "login", "password", "account":
+			if i+1 >= len(fields) {
+				break
+			}
+			i++
+			if current == nil {
+				continue
+			}
+			if fields[i-1] == "login" {
+				current.login = fields[i]
+			} else if fields[i-1] == "password" {
+				current.password = fields[i]
+			}
+		case "macdef":
+			// Macro definitions run until an empty line, which strings.Fields
+			// cannot see. Nothing after a macdef is trusted.
+			current = nil
+			i = len(fields)
+		}
+	}
+
+	if found != nil {
+		return found
+	}
+
+	return fallback
+}
+
+func defaultNetrcPath() string {
+	if p := os.Getenv("NETRC"); p != "" {
+		return p
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".netrc")
+}
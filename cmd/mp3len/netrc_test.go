@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseNetrc(t *testing.T) {
+	const data = `
+machine example.com login alice password wonderland
+machine cdn.example.com
+	login bob
+	password builder
+default login guest password anonymous
+`
+	tests := []struct {
+		name string
+		host string
+		want *netrcEntry
+	}{
+		{"first machine", "example.com", &netrcEntry{"alice", "wonderland"}},
+		{"multi-line machine", "cdn.example.com", &netrcEntry{"bob", "builder"}},
+		{"default", "other.example.com", &netrcEntry{"guest", "anonymous"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseNetrc(data, tt.host); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNetrc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no match without default", func(t *testing.T) {
+		if got := parseNetrc("machine example.com login a password b", "other"); got != nil {
+			t.Errorf("parseNetrc() = %v, want nil", got)
+		}
+	})
+}
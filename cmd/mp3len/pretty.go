@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// prettyRow is one input in -pretty output. Err is set when it failed.
+type prettyRow struct {
+	Path     string
+	Duration time.Duration
+	BitRate  int // kbps
+	VBR      bool
+	Warnings []string
+	Err      error
+}
+
+// prettyFormatter lays out rows in aligned columns fitting width.
+type prettyFormatter struct {
+	width int
+	color bool
+}
+
+const (
+	prettyDurationWidth = 10
+	prettyBitRateWidth  = 8
+	prettyModeWidth     = 3
+	prettyGap           = "  "
+	prettyMinPathWidth  = 10
+)
+
+func (f *prettyFormatter) pathWidth() int {
+	w := f.width - prettyDurationWidth - prettyBitRateWidth - prettyModeWidth - 3*len(prettyGap)
+
+	if w < prettyMinPathWidth {
+		return prettyMinPathWidth
+	}
+
+	return w
+}
+
+// Row formats a row, plus one line per warning.
+func (f *prettyFormatter) Row(row prettyRow) string {
+	path := padRight(truncateLeft(row.Path, f.pathWidth()), f.pathWidth())
+
+	if row.Err != nil {
+		return path + prettyGap + f.paint(colorRed, "error: "+row.Err.Error()) + "\n"
+	}
+
+	mode := "CBR"
+	if row.VBR {
+		mode = "VBR"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(path)
+	sb.WriteString(prettyGap)
+	sb.WriteString(padLeft(formatClock(row.Duration), prettyDurationWidth))
+	sb.WriteString(prettyGap)
+	sb.WriteString(padLeft(fmt.Sprintf("%d kbps", row.BitRate), prettyBitRateWidth))
+	sb.WriteString(prettyGap)
+	sb.WriteString(mode)
+	sb.WriteByte('\n')
+
+	for _, warning := range row.Warnings {
+		sb.WriteString(prettyGap)
+		sb.WriteString(f.paint(colorYellow, "warning: "+warning))
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// Footer formats the -total summary below a rule.
+func (f *prettyFormatter) Footer(total time.Duration, count int) string {
+	label := padRight(fmt.Sprintf("Total (%d files)", count), f.pathWidth())
+
+	return strings.Repeat("-", f.width) + "\n" +
+		label + prettyGap + padLeft(formatClock(total), prettyDurationWidth) + "\n"
+}
+
+// Format lays out all rows, for tests and callers holding every row.
+func (f *prettyFormatter) Format(rows []prettyRow, withTotal bool) string {
+	var sb strings.Builder
+	var total time.Duration
+	count := 0
+
+	for _, row := range rows {
+		sb.WriteString(f.Row(row))
+
+		if row.Err == nil {
+			total += row.Duration
+			count++
+		}
+	}
+
+	if withTotal {
+		sb.WriteString(f.Footer(total, count))
+	}
+
+	return sb.String()
+}
+
+func (f *prettyFormatter) paint(color string, s string) string {
+	if !f.color {
+		return s
+	}
+
+	return color + s + colorReset
+}
+
+// formatClock formats d as h:mm:ss or m:ss, truncated to seconds.
+func formatClock(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	h := int(d / time.Hour)
+	m := int(d % time.Hour / time.Minute)
+	s := int(d % time.Minute / time.Second)
+
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// truncateLeft shortens s to width runes, replacing its start with an ellipsis
+// so the file name stays visible.
+func truncateLeft(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+
+	if n <= width {
+		return s
+	}
+
+	runes := []rune(s)
+
+	return "…" + string(runes[n-width+1:])
+}
+
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+
+	return s
+}
+
+func padLeft(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+
+	return s
+}
+
+// useColor tells whether f is a terminal and the user didn't opt out of color.
+func useColor(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	stat, err := f.Stat()
+
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns $COLUMNS, or 80.
+func terminalWidth() int {
+	var width int
+
+	if _, err := fmt.Sscan(os.Getenv("COLUMNS"), &width); err == nil && width > 0 {
+		return width
+	}
+
+	return 80
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestPrettyFormatter_Format(t *testing.T) {
+	rows := []prettyRow{
+		{Path: "episodes/EP01.mp3", Duration: 49*time.Minute + 17*time.Second + 122*time.Millisecond, BitRate: 128},
+		{Path: "episodes/EP02.mp3", Duration: time.Hour + 2*time.Minute + 3*time.Second, BitRate: 192, VBR: true},
+		{
+			Path:     "/very/long/path/to/a/directory/holding/many/podcast/episodes/of/a/show/with/a/long/name/EP03.mp3",
+			Duration: 59 * time.Second,
+			BitRate:  64,
+			Warnings: []string{"duration 59s looks short"},
+		},
+		{Path: "episodes/broken.mp3", Err: errors.New("MP3 frame sync not found")},
+	}
+
+	for _, width := range []int{80, 120} {
+		t.Run(fmt.Sprintf("width %d", width), func(t *testing.T) {
+			f := &prettyFormatter{width: width}
+			got := f.Format(rows, true)
+
+			golden := filepath.Join("testdata", fmt.Sprintf("pretty_%d.golden", width))
+
+			if *update {
+				if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != string(want) {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestPrettyFormatter_Color(t *testing.T) {
+	f := &prettyFormatter{width: 80, color: true}
+	got := f.Row(prettyRow{Path: "a.mp3", Err: errors.New("failed")})
+
+	if want := colorRed + "error: failed" + colorReset; !strings.Contains(got, want) {
+		t.Errorf("Row() = %q, want it to contain %q", got, want)
+	}
+}
+
+func Test_truncateLeft(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short.mp3", 20, "short.mp3"},
+		{"a/long/path/file.mp3", 10, "…/file.mp3"},
+		{"日本語/ファイル.mp3", 9, "…ファイル.mp3"},
+	}
+	for _, tt := range tests {
+		if got := truncateLeft(tt.s, tt.width); got != tt.want {
+			t.Errorf("truncateLeft(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func Test_formatClock(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0:00"},
+		{59*time.Second + 999*time.Millisecond, "0:59"},
+		{49*time.Minute + 17*time.Second, "49:17"},
+		{26*time.Hour + 3*time.Second, "26:00:03"},
+	}
+	for _, tt := range tests {
+		if got := formatClock(tt.d); got != tt.want {
+			t.Errorf("formatClock(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mp3len/internal/mp3header"
+)
+
+var layerNames = map[string]int{
+	"layer1": mp3header.Layer1,
+	"layer2": mp3header.Layer2,
+	"layer3": mp3header.Layer3,
+}
+
+// parseRequire parses the -require flag, a comma-separated list of
+// "mpeg1", "layer1" to "layer3", "minrate=KBPS" and "minfreq=HZ", into a
+// function rejecting headers that don't meet all of them.
+func parseRequire(spec string) (func(mp3header.MP3Header) error, error) {
+	var checks []func(h mp3header.MP3Header) error
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(strings.ToLower(item))
+		key, value := item, ""
+
+		if i := strings.IndexByte(item, '='); i >= 0 {
+			key, value = item[:i], item[i+1:]
+		}
+
+		switch key {
+		case "":
+			continue
+		case "mpeg1":
+			checks = append(checks, func(h mp3header.MP3Header) error {
+				if !h.IsMPEG1() {
+					return fmt.Errorf("MPEG-1 required, got MPEG-%s", h.VersionName())
+				}
+				return nil
+			})
+		case "layer1", "layer2", "layer3":
+			layer := layerNames[key]
+			want := mp3header.MP3Header{Layer: layer}
+
+			checks = append(checks, func(h mp3header.MP3Header) error {
+				if h.Layer != layer {
+					return fmt.Errorf("Layer %s required, got Layer %s", want.LayerName(), h.LayerName())
+				}
+				return nil
+			})
+		case "minrate", "minfreq":
+			min, err := strconv.Atoi(value)
+
+			if err != nil || min <= 0 {
+				return nil, fmt.Errorf("-require: %s needs a positive number, got %q", key, value)
+			}
+
+			if key == "minrate" {
+				checks = append(checks, func(h mp3header.MP3Header) error {
+					if h.BitRate < min {
+						return fmt.Errorf("bit rate of at least %d kbps required, got %d kbps", min, h.BitRate)
+					}
+					return nil
+				})
+			} else {
+				checks = append(checks, func(h mp3header.MP3Header) error {
+					if h.SampleFreq < min {
+						return fmt.Errorf("sample rate of at least %d Hz required, got %d Hz", min, h.SampleFreq)
+					}
+					return nil
+				})
+			}
+		default:
+			return nil, fmt.Errorf("-require: unknown requirement %q", item)
+		}
+	}
+
+	return func(h mp3header.MP3Header) error {
+		for _, check := range checks {
+			if err := check(h); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
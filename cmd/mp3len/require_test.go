@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"mp3len/internal/mp3header"
+)
+
+func Test_parseRequire(t *testing.T) {
+	good := mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer3, BitRate: 128, SampleFreq: 44100}
+	const spec = "mpeg1,layer3,minrate=128,minfreq=44100"
+
+	tests := []struct {
+		name    string
+		spec    string
+		header  mp3header.MP3Header
+		wantErr string
+	}{
+		{"accepted", spec, good, ""},
+		{"empty spec", "", mp3header.MP3Header{AudioVersion: mp3header.Version2}, ""},
+		{
+			"MPEG-2", spec,
+			mp3header.MP3Header{AudioVersion: mp3header.Version2, Layer: mp3header.Layer3, BitRate: 128, SampleFreq: 22050},
+			"MPEG-1 required, got MPEG-2",
+		},
+		{
+			"Layer II", spec,
+			mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer2, BitRate: 128, SampleFreq: 44100},
+			"Layer III required, got Layer II",
+		},
+		{
+			"low bit rate", spec,
+			mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer3, BitRate: 96, SampleFreq: 44100},
+			"bit rate of at least 128 kbps required, got 96 kbps",
+		},
+		{
+			"low sample rate", spec,
+			mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer3, BitRate: 128, SampleFreq: 32000},
+			"sample rate of at least 44100 Hz required, got 32000 Hz",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require, err := parseRequire(tt.spec)
+			if err != nil {
+				t.Fatalf("parseRequire() error = %v", err)
+			}
+
+			err = require(tt.header)
+
+			if got := errorString(err); got != tt.wantErr {
+				t.Errorf("require() error = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_parseRequire_Invalid(t *testing.T) {
+	for _, spec := range []string{"mpeg3", "minrate", "minrate=abc", "minfreq=-1"} {
+		if _, err := parseRequire(spec); err == nil {
+			t.Errorf("parseRequire(%q) error = nil, want error", spec)
+		}
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
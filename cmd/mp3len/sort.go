@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"mp3len"
+)
+
+// measured is the result of measuring an input, kept to be printed in order
+// with -sort.
+type measured struct {
+	input string
+	info  *mp3len.Metadata
+	err   error
+	size  int64 // of local files, -1 otherwise
+}
+
+// sortKeys compare two successful results by the key of -sort. Sizes of
+// inputs that aren't local files are unknown, and ordered after the others.
+var sortKeys = map[string]func(a, b *measured) int{
+	"path": func(a, b *measured) int {
+		return strings.Compare(a.input, b.input)
+	},
+	"duration": func(a, b *measured) int {
+		return compareInt64(int64(a.info.Duration()), int64(b.info.Duration()))
+	},
+	"size": func(a, b *measured) int {
+		return compareInt64(a.size, b.size)
+	},
+	"bitrate": func(a, b *measured) int {
+		return compareInt64(int64(a.info.Header().BitRate), int64(b.info.Header().BitRate))
+	},
+}
+
+// parseSortKey checks the key of -sort.
+func parseSortKey(key string) error {
+	if _, ok := sortKeys[key]; ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(sortKeys))
+	for k := range sortKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return fmt.Errorf("-sort must be one of %s, got %q", strings.Join(keys, ", "), key)
+}
+
+// sortMeasured orders results by key, descending when reverse, with ties
+// broken by path. Failures are kept at the end, in the order they happened.
+func sortMeasured(results []measured, key string, reverse bool) {
+	compare := sortKeys[key]
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := &results[i], &results[j]
+
+		if (a.err != nil) != (b.err != nil) {
+			return b.err != nil
+		}
+
+		if a.err != nil {
+			return false
+		}
+
+		if key == "size" && (a.size < 0) != (b.size < 0) {
+			return b.size < 0
+		}
+
+		c := compare(a, b)
+
+		if c == 0 {
+			return a.input < b.input
+		}
+
+		if reverse {
+			return c > 0
+		}
+
+		return c < 0
+	})
+}
+
+// localSize returns the size of input if it's a local file, or -1.
+func localSize(input string) int64 {
+	stat, err := os.Stat(input)
+
+	if err != nil || stat.IsDir() {
+		return -1
+	}
+
+	return stat.Size()
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_sortMeasured(t *testing.T) {
+	dir := t.TempDir()
+
+	writeMP3 := func(name string, headerBits uint32, frameLength, count int) {
+		frame := make([]byte, frameLength)
+		binary.BigEndian.PutUint32(frame, headerBits)
+
+		if err := ioutil.WriteFile(filepath.Join(dir, name), bytes.Repeat(frame, count), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeMP3("a.mp3", 0xFFFB9044, 417, 200) // 128 kbps, 5.2s
+	writeMP3("b.mp3", 0xFFFBE444, 960, 20)  // 320 kbps, 0.48s
+	writeMP3("c.mp3", 0xFFFB9044, 417, 100) // 128 kbps, 2.606s
+
+	// mem://episode.mp3 is like c.mp3, but its size is unknown
+	inputs := []string{"z-missing.mp3", "mem://episode.mp3", "c.mp3", "missing.mp3", "a.mp3", "b.mp3"}
+
+	tests := []struct {
+		key     string
+		reverse bool
+		want    []string
+	}{
+		{"path", false, []string{"a.mp3", "b.mp3", "c.mp3", "mem://episode.mp3", "z-missing.mp3", "missing.mp3"}},
+		{"path", true, []string{"mem://episode.mp3", "c.mp3", "b.mp3", "a.mp3", "z-missing.mp3", "missing.mp3"}},
+		{"duration", false, []string{"b.mp3", "c.mp3", "mem://episode.mp3", "a.mp3", "z-missing.mp3", "missing.mp3"}},
+		{"duration", true, []string{"a.mp3", "c.mp3", "mem://episode.mp3", "b.mp3", "z-missing.mp3", "missing.mp3"}},
+		{"size", false, []string{"b.mp3", "c.mp3", "a.mp3", "mem://episode.mp3", "z-missing.mp3", "missing.mp3"}},
+		{"size", true, []string{"a.mp3", "c.mp3", "b.mp3", "mem://episode.mp3", "z-missing.mp3", "missing.mp3"}},
+		{"bitrate", false, []string{"a.mp3", "c.mp3", "mem://episode.mp3", "b.mp3", "z-missing.mp3", "missing.mp3"}},
+		{"bitrate", true, []string{"b.mp3", "a.mp3", "c.mp3", "mem://episode.mp3", "z-missing.mp3", "missing.mp3"}},
+	}
+	for _, tt := range tests {
+		name := tt.key
+		if tt.reverse {
+			name += " reverse"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			var results []measured
+
+			for _, input := range inputs {
+				if !strings.Contains(input, "://") {
+					input = filepath.Join(dir, input)
+				}
+
+				info, err := measureInput(input, nil, &authFlags{})
+				results = append(results, measured{input: input, info: info, err: err, size: localSize(input)})
+			}
+
+			sortMeasured(results, tt.key, tt.reverse)
+
+			var got []string
+			for _, result := range results {
+				got = append(got, strings.TrimPrefix(result.input, dir+string(filepath.Separator)))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortMeasured() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSortKey(t *testing.T) {
+	for _, key := range []string{"path", "duration", "size", "bitrate"} {
+		if err := parseSortKey(key); err != nil {
+			t.Errorf("parseSortKey(%q) error = %v", key, err)
+		}
+	}
+
+	err := parseSortKey("length")
+
+	if err == nil || err.Error() != `-sort must be one of bitrate, duration, path, size, got "length"` {
+		t.Errorf("parseSortKey() error = %v", err)
+	}
+}
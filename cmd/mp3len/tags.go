@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"mp3len"
+	"mp3len/internal/id3"
+)
+
+// maxTagBinaryFrameBytes is the size above which -tags skips the payload of
+// binary frames, such as embedded pictures, unless -artwork.
+const maxTagBinaryFrameBytes = 64 << 10
+
+// formatTags renders the tag summary of a report for -tags, one "Name: value"
+// line per field set, each prefixed with indent. Empty when tags is nil.
+func formatTags(tags *mp3len.TagReport, indent string) string {
+	if tags == nil {
+		return ""
+	}
+
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"Title", tags.Title},
+		{"Artist", tags.Artist},
+		{"Album", tags.Album},
+		{"Year", tags.Year},
+		{"Track", ""},
+		{"Genre", tags.Genre},
+		{"Comment", tags.Comment},
+	}
+
+	if track := tags.Track; track > 0 {
+		fields[4].value = fmt.Sprint(track)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%sTags: %s\n", indent, tags.Source)
+
+	for _, field := range fields {
+		if field.value != "" {
+			fmt.Fprintf(&sb, "%s%s: %s\n", indent, field.name, field.value)
+		}
+	}
+
+	return sb.String()
+}
+
+// readLeadingTag decodes the ID3v2 tag at the start of the file at path for
+// -tags. Unless artwork, binary frames larger than maxTagBinaryFrameBytes are
+// skipped rather than read into memory. It returns nil when path isn't a
+// regular file, e.g. a URL, or has no such tag.
+func readLeadingTag(path string, artwork bool) (*id3.Tag, error) {
+	if stat, err := os.Stat(path); err != nil || !stat.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+
+	if start, _ := br.Peek(6); !id3.HasLeadingTag(start, false) {
+		return nil, nil
+	}
+
+	decoder := id3.NewDecoder(br)
+
+	if !artwork {
+		decoder.FrameFilter = id3.SkipLargeBinaryFrames(maxTagBinaryFrameBytes)
+	}
+
+	return decoder.Decode()
+}
+
+// formatID3v2Tag is like formatTags, for an ID3v2 tag. Pictures skipped by
+// readLeadingTag are listed with their size only.
+func formatID3v2Tag(tag *id3.Tag, indent string) string {
+	if tag == nil {
+		return ""
+	}
+
+	fields := []struct {
+		name string
+		key  string
+	}{
+		{"Title", id3.KeyTitle},
+		{"Artist", id3.KeyArtist},
+		{"Album", id3.KeyAlbum},
+		{"Year", id3.KeyYear},
+		{"Track", id3.KeyTrack},
+		{"Genre", id3.KeyGenre},
+		{"Comment", id3.KeyComment},
+	}
+
+	values := tag.ToMap()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%sTags: ID3v2.%d\n", indent, tag.Version)
+
+	for _, field := range fields {
+		if value := values[field.key]; value != "" {
+			fmt.Fprintf(&sb, "%s%s: %s\n", indent, field.name, value)
+		}
+	}
+
+	for i := range tag.Frames {
+		frame := &tag.Frames[i]
+
+		if frame.ID != "APIC" {
+			continue
+		}
+
+		if frame.Data == nil {
+			fmt.Fprintf(&sb, "%sPicture: %d bytes, not read without -artwork\n", indent, frame.Size)
+			continue
+		}
+
+		if meta, _, err := frame.Picture(); err == nil {
+			fmt.Fprintf(&sb, "%sPicture: %s, %d bytes\n", indent, meta.MIME, meta.Size)
+		}
+	}
+
+	return sb.String()
+}
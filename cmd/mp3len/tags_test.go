@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"mp3len"
+	"mp3len/internal/id3"
+)
+
+// testTaggedMetadata is testMetadata, with an ID3v1 tag.
+func testTaggedMetadata(t *testing.T) *mp3len.Metadata {
+	frame := make([]byte, 417)
+	binary.BigEndian.PutUint32(frame, 0xFFFB9044)
+
+	v1 := make([]byte, 128)
+	copy(v1, "TAG")
+	copy(v1[3:], "Title")
+	copy(v1[33:], "Artist")
+	v1[126], v1[127] = 3, 8 // track 3, Jazz
+
+	data := append(bytes.Repeat(frame, 100), v1...)
+
+	info, err := mp3len.GetInfoAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return info
+}
+
+func Test_formatTags(t *testing.T) {
+	info := testTaggedMetadata(t)
+
+	want := "\tTags: ID3v1\n\tTitle: Title\n\tArtist: Artist\n\tTrack: 3\n\tGenre: Jazz\n"
+
+	if got := formatTags(info.Report().Tags, "\t"); got != want {
+		t.Errorf("formatTags() = %q, want %q", got, want)
+	}
+
+	if got := formatTags(testMetadata(t).Report().Tags, ""); got != "" {
+		t.Errorf("formatTags() = %q, want none", got)
+	}
+}
+
+func Test_readLeadingTag(t *testing.T) {
+	title := id3.Frame{ID: "TIT2"}
+	if err := title.SetText("Title"); err != nil {
+		t.Fatal(err)
+	}
+
+	image := make([]byte, 2*maxTagBinaryFrameBytes)
+	picture := id3.Frame{ID: "APIC", Data: append([]byte("\x00image/png\x00\x03\x00"), image...)}
+
+	var data bytes.Buffer
+	if err := id3.NewEncoder(&data).Encode(&id3.Tag{Version: 3, Frames: []id3.Frame{title, picture}}); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := make([]byte, 417)
+	binary.BigEndian.PutUint32(frame, 0xFFFB9044)
+	data.Write(bytes.Repeat(frame, 10))
+
+	path := filepath.Join(t.TempDir(), "tagged.mp3")
+	if err := ioutil.WriteFile(path, data.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		artwork bool
+		want    string
+	}{
+		{"default", false, "Tags: ID3v2.3\nTitle: Title\nPicture: 131085 bytes, not read without -artwork\n"},
+		{"artwork", true, "Tags: ID3v2.3\nTitle: Title\nPicture: image/png, 131072 bytes\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, err := readLeadingTag(path, tt.artwork)
+			if err != nil {
+				t.Fatalf("readLeadingTag() error = %v", err)
+			}
+
+			if got := tag.Frames[1].Data == nil; got == tt.artwork {
+				t.Errorf("readLeadingTag() APIC skipped = %v, want %v", got, !tt.artwork)
+			}
+
+			if got := formatID3v2Tag(tag, ""); got != tt.want {
+				t.Errorf("formatID3v2Tag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if tag, err := readLeadingTag(filepath.Join(t.TempDir(), "missing.mp3"), false); tag != nil || err != nil {
+		t.Errorf("readLeadingTag() of a missing file = %v, %v, want nil, nil", tag, err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"mp3len"
+	"mp3len/internal/id3"
+)
+
+// templateData is what -template is executed against.
+type templateData struct {
+	Path         string
+	Duration     time.Duration
+	Seconds      float64
+	Bitrate      int // kbps
+	SampleRate   int // Hz
+	Version      string
+	Layer        string
+	ChannelMode  string
+	VBR          bool
+	Confidence   string
+	TotalSamples int64
+	Warnings     []string
+
+	// Tags has the keys of id3.Tag.ToMap for the leading ID3v2 tag, or of
+	// Metadata.Tags without one, e.g. {{.Tags.title}}, all of them even
+	// without value.
+	Tags map[string]string
+}
+
+// templateTagKeys are always in templateData.Tags, so that templates don't
+// fail on inputs without them.
+var templateTagKeys = []string{"title", "artist", "album", "track", "disc", "year", "genre", "comment"}
+
+// newTemplateData returns the data of one input. tag is its leading ID3v2
+// tag, if any, see readLeadingTag; the ID3v1 values of report are used
+// without it.
+func newTemplateData(path string, report *mp3len.Report, tag *id3.Tag) templateData {
+	tags := make(map[string]string)
+	if tag != nil {
+		tags = tag.ToMap()
+	} else if report.Tags != nil {
+		for key, value := range report.Tags.Values {
+			tags[key] = value
+		}
+	}
+
+	for _, key := range templateTagKeys {
+		if _, ok := tags[key]; !ok {
+			tags[key] = ""
+		}
+	}
+
+	return templateData{
+		Path:         path,
+		Duration:     report.Duration,
+		Seconds:      report.Duration.Seconds(),
+		Bitrate:      report.Header.BitRate,
+		SampleRate:   report.Header.SampleFreq,
+		Version:      report.Header.VersionName(),
+		Layer:        report.Header.LayerName(),
+		ChannelMode:  report.Header.ChannelModeName(),
+		VBR:          report.VBR,
+		Confidence:   report.Confidence.String(),
+		TotalSamples: report.TotalSamples,
+		Warnings:     report.Warnings,
+		Tags:         tags,
+	}
+}
+
+// parseOutputTemplate parses the -template flag.
+func parseOutputTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid -template: %v", err)
+	}
+
+	return tmpl, nil
+}
+
+// executeTemplate renders tmpl for one input, reading the leading ID3v2 tag
+// of path for .Tags when it's a local file.
+func executeTemplate(tmpl *template.Template, path string, report *mp3len.Report) (string, error) {
+	tag, err := readLeadingTag(path, false)
+
+	if err != nil {
+		return "", fmt.Errorf("reading tags for -template: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, newTemplateData(path, report, tag)); err != nil {
+		return "", fmt.Errorf("executing -template: %v", err)
+	}
+
+	return buf.String(), nil
+}
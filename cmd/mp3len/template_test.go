@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"mp3len"
+)
+
+func testMetadata(t *testing.T) *mp3len.Metadata {
+	// MPEG-1 Layer III, 128 kbps, 44100Hz, joint stereo; 100 frames
+	frame := make([]byte, 417)
+	binary.BigEndian.PutUint32(frame, 0xFFFB9044)
+	data := bytes.Repeat(frame, 100)
+
+	info, err := mp3len.GetInfo(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return info
+}
+
+func Test_executeTemplate(t *testing.T) {
+	info := testMetadata(t)
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"fields", "{{.Path}} {{.Duration}} {{.Bitrate}}kbps", "a.mp3 2.606s 128kbps", false},
+		{"format", `{{printf "%.1f" .Seconds}} {{.Version}} {{.Layer}} {{.ChannelMode}}`, "2.6 1 III joint stereo", false},
+		{"unknown field", "{{.Nope}}", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseOutputTemplate(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := executeTemplate(tmpl, "a.mp3", info.Report())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("executeTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("executeTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// taggedCorpusFile has an ID3v2.3 tag titled "Corpus".
+const taggedCorpusFile = "../../testdata/corpus/tagged.mp3"
+
+func Test_executeTemplate_Tags(t *testing.T) {
+	tmpl, err := parseOutputTemplate("{{.Tags.title}} by {{.Tags.artist}} ({{.Tags.genre}}){{.Tags.album}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tagged, err := mp3len.GetInfoFromFile(taggedCorpusFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		info *mp3len.Metadata
+		want string
+	}{
+		{"ID3v1", "a.mp3", testTaggedMetadata(t), "Title by Artist (Jazz)"},
+		{"ID3v2", taggedCorpusFile, tagged, "Corpus by  ()"},
+		{"untagged", "a.mp3", testMetadata(t), " by  ()"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := executeTemplate(tmpl, tt.path, tt.info.Report())
+
+			if err != nil {
+				t.Fatalf("executeTemplate() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("executeTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseOutputTemplate(t *testing.T) {
+	if _, err := parseOutputTemplate("{{.Duration"); err == nil {
+		t.Errorf("parseOutputTemplate() error = nil, want error")
+	}
+}
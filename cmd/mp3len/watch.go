@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"mp3len/internal/clock"
+)
+
+// watcher polls directories for new MP3 files, reporting each once its size
+// and modification time stay unchanged for the quiet period, so that files
+// still being copied are not measured.
+type watcher struct {
+	dirs     []string
+	interval time.Duration
+	quiet    time.Duration
+	clock    clock.Clock
+
+	files map[string]*watchedFile
+}
+
+type watchedFile struct {
+	size        int64
+	modTime     time.Time
+	stableSince time.Time
+	reported    bool
+}
+
+func newWatcher(dirs []string, interval, quiet time.Duration) *watcher {
+	return &watcher{
+		dirs:     dirs,
+		interval: interval,
+		quiet:    quiet,
+		clock:    clock.Real,
+		files:    map[string]*watchedFile{},
+	}
+}
+
+// poll scans the directories once and returns the files that became ready at
+// now, sorted by path.
+func (w *watcher) poll(now time.Time) ([]string, error) {
+	var ready []string
+
+	for _, dir := range w.dirs {
+		err := forEachInput(dir, func(path string) {
+			stat, err := os.Stat(path)
+			if err != nil {
+				// removed, or not there yet
+				return
+			}
+
+			f, ok := w.files[path]
+
+			if !ok || f.size != stat.Size() || !f.modTime.Equal(stat.ModTime()) {
+				if !ok {
+					f = new(watchedFile)
+					w.files[path] = f
+				}
+
+				f.size, f.modTime, f.stableSince = stat.Size(), stat.ModTime(), now
+				f.reported = false
+				return
+			}
+
+			if !f.reported && now.Sub(f.stableSince) >= w.quiet {
+				f.reported = true
+				ready = append(ready, path)
+			}
+		})
+
+		if err != nil {
+			return ready, err
+		}
+	}
+
+	sort.Strings(ready)
+
+	return ready, nil
+}
+
+// run polls every interval, calling fn for each ready file, until stop is
+// closed. Errors from polling are given to onError and don't stop watching.
+func (w *watcher) run(stop <-chan struct{}, fn func(path string), onError func(error)) {
+	for {
+		ready, err := w.poll(w.clock.Now())
+
+		if err != nil {
+			onError(err)
+		}
+
+		for _, path := range ready {
+			fn(path)
+		}
+
+		timer := w.clock.NewTimer(w.interval)
+
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
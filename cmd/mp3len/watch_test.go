@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"mp3len/internal/testutil"
+)
+
+func TestWatcher_poll(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "incoming.mp3")
+	other := filepath.Join(dir, "notes.txt")
+	w := newWatcher([]string{dir}, time.Second, 5*time.Second)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mustWrite := func(path string, data []byte, flag int) {
+		f, err := os.OpenFile(path, flag|os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		if _, err = f.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		name  string
+		at    time.Duration
+		write func()
+		want  []string
+	}{
+		{"partial file", 0, func() { mustWrite(path, make([]byte, 100), 0) }, nil},
+		{"partial file, quiet", 3 * time.Second, nil, nil},
+		{"complete file", 4 * time.Second, func() { mustWrite(path, make([]byte, 100), os.O_APPEND) }, nil},
+		{"before the quiet period", 8 * time.Second, nil, nil},
+		{"after the quiet period", 9 * time.Second, func() { mustWrite(other, []byte("x"), 0) }, []string{path}},
+		{"already reported", 20 * time.Second, nil, nil},
+	}
+	for _, step := range steps {
+		if step.write != nil {
+			step.write()
+		}
+
+		got, err := w.poll(start.Add(step.at))
+
+		if err != nil {
+			t.Fatalf("%s: poll() error = %v", step.name, err)
+		}
+
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: poll() = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestWatcher_run(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.mp3")
+
+	if err := os.WriteFile(path, make([]byte, 10), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := newWatcher([]string{dir}, time.Second, time.Second)
+	w.clock = c
+
+	stop := make(chan struct{})
+	reported := make(chan string, 1)
+	done := make(chan struct{})
+
+	go func() {
+		w.run(stop, func(path string) { reported <- path }, func(err error) { t.Error(err) })
+		close(done)
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(time.Second)
+
+	if got := <-reported; got != path {
+		t.Errorf("run() reported %q, want %q", got, path)
+	}
+
+	close(stop)
+	<-done
+}
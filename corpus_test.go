@@ -0,0 +1,181 @@
+package mp3len
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// TestCorpus measures every MP3 under testdata/corpus, or MP3LEN_CORPUS_DIR
+// when set, and compares the JSON of the result with the .golden.json file
+// next to it. Run with -update to write the golden files.
+func TestCorpus(t *testing.T) {
+	dir := os.Getenv("MP3LEN_CORPUS_DIR")
+	if dir == "" {
+		dir = filepath.Join("testdata", "corpus")
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".mp3") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		t.Run(filepath.ToSlash(rel), func(t *testing.T) {
+			got, err := corpusResult(path, info.Size())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := path + ".golden.json"
+
+			if *update {
+				if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+
+			if bytes.Equal(got, want) {
+				return
+			}
+
+			diffs, err := jsonDiff(want, got)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, diff := range diffs {
+				t.Error(diff)
+			}
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// corpusResult returns the indented JSON of the metadata of the file at path,
+// or of the error measuring it.
+func corpusResult(path string, size int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var result interface{}
+
+	if metadata, err := GetInfoAt(f, size); err != nil {
+		result = map[string]string{"error": err.Error()}
+	} else {
+		result = metadata
+	}
+
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// jsonDiff lists the values that differ between two JSON documents, by path.
+func jsonDiff(want, got []byte) ([]string, error) {
+	var wantValue, gotValue interface{}
+
+	if err := json.Unmarshal(want, &wantValue); err != nil {
+		return nil, fmt.Errorf("golden file: %w", err)
+	}
+
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	diffJSONValues("$", wantValue, gotValue, &diffs)
+
+	return diffs, nil
+}
+
+func diffJSONValues(path string, want, got interface{}, diffs *[]string) {
+	wantObject, wantIsObject := want.(map[string]interface{})
+	gotObject, gotIsObject := got.(map[string]interface{})
+
+	if !wantIsObject || !gotIsObject {
+		if !reflect.DeepEqual(want, got) {
+			*diffs = append(*diffs, fmt.Sprintf("%s = %v, want %v", path, got, want))
+		}
+
+		return
+	}
+
+	keys := make(map[string]bool)
+	for key := range wantObject {
+		keys[key] = true
+	}
+	for key := range gotObject {
+		keys[key] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		diffJSONValues(path+"."+key, wantObject[key], gotObject[key], diffs)
+	}
+}
+
+func Test_jsonDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff []string
+	}{
+		{"equal", `{"a": 1, "b": {"c": "x"}}`, `{"b": {"c": "x"}, "a": 1}`, nil},
+		{"changed", `{"a": 1, "b": {"c": "x"}}`, `{"a": 1, "b": {"c": "y"}}`, []string{"$.b.c = y, want x"}},
+		{"added and removed", `{"a": 1}`, `{"b": 2}`, []string{"$.a = <nil>, want 1", "$.b = 2, want <nil>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := jsonDiff([]byte(tt.want), []byte(tt.got))
+
+			if err != nil {
+				t.Fatalf("jsonDiff() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(diff, tt.diff) {
+				t.Errorf("jsonDiff() = %q, want %q", diff, tt.diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package mp3len
+
+import (
+	"errors"
+	"time"
+
+	"mp3len/internal/mp3header"
+)
+
+var (
+	errZeroBitRate    = errors.New("cannot estimate duration: bit rate is unknown (free format?)")
+	errZeroSampleFreq = errors.New("cannot compute duration: sample rate is unknown (reserved sample rate index?)")
+)
+
+// EstimateDuration estimates the duration of audioBytes bytes of audio, assuming
+// every frame has the same bit rate as h (i.e. constant bit rate).
+//
+// The result is truncated to whole milliseconds. Returns an error when the bit
+// rate of h is not positive, which is the case for free format streams.
+//
+// Algorithm from https://www.factorialcomplexity.com/blog/how-to-get-a-duration-of-a-remote-mp3-file
+func EstimateDuration(h mp3header.MP3Header, audioBytes int64) (time.Duration, error) {
+	if h.BitRate <= 0 {
+		return 0, errZeroBitRate
+	}
+
+	// bytes * 8 / kbps = milliseconds
+	ms := audioBytes * 8 / int64(h.BitRate)
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// ExactDuration computes the duration of frameCount frames like h, minus the
+// encoderDelay and encoderPadding samples declared by a LAME tag.
+//
+// The result is truncated to whole nanoseconds. Returns 0 when the sample rate
+// of h is not positive or there are fewer samples than the delay and padding.
+func ExactDuration(h mp3header.MP3Header, frameCount int64, encoderDelay, encoderPadding int) time.Duration {
+	if h.SampleFreq <= 0 {
+		return 0
+	}
+
+	samples := frameCount*int64(h.SamplesPerFrame()) - int64(encoderDelay+encoderPadding)
+
+	if samples <= 0 {
+		return 0
+	}
+
+	sampleFreq := int64(h.SampleFreq)
+
+	// split to avoid overflowing time.Duration in the multiplication
+	return time.Duration(samples/sampleFreq)*time.Second +
+		time.Duration(samples%sampleFreq)*time.Second/time.Duration(sampleFreq)
+}
+
+// DurationFromFrameCount computes the duration of frameCount frames like
+// header, e.g. from the frame count of a Xing header, without reading the
+// audio. It's ExactDuration without encoder delay and padding, the VBR
+// counterpart of EstimateDuration.
+func DurationFromFrameCount(frameCount int, header mp3header.MP3Header) time.Duration {
+	return ExactDuration(header, int64(frameCount), 0, 0)
+}
@@ -0,0 +1,121 @@
+package mp3len
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"mp3len/internal/mp3header"
+)
+
+func TestEstimateDuration(t *testing.T) {
+	mpeg1 := mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer3, BitRate: 128, SampleFreq: 44100}
+	mono := mpeg1
+	mono.ChannelMode = mp3header.ChannelModeMono
+	layer1 := mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer1, BitRate: 92, SampleFreq: 44100}
+
+	tests := []struct {
+		name       string
+		header     mp3header.MP3Header
+		audioBytes int64
+		want       time.Duration
+		wantErr    bool
+	}{
+		{"one second", mpeg1, 16000, time.Second, false},
+		{"truncated to milliseconds", mpeg1, 16015, 1000 * time.Millisecond, false},
+		{"channel mode does not matter", mono, 16000, time.Second, false},
+		{"bit rate not divisible by 8", layer1, 11500, time.Second, false},
+		{"no audio", mpeg1, 0, 0, false},
+		{"negative audio size", mpeg1, -16000, -time.Second, false},
+		{"Error: free format", mp3header.MP3Header{BitRate: 0, SampleFreq: 44100}, 16000, 0, true},
+		{"Error: bad bit rate", mp3header.MP3Header{BitRate: -1, SampleFreq: 44100}, 16000, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EstimateDuration(tt.header, tt.audioBytes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EstimateDuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EstimateDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExactDuration(t *testing.T) {
+	mpeg1 := mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer3, BitRate: 128, SampleFreq: 44100}
+	mpeg25 := mp3header.MP3Header{AudioVersion: mp3header.Version2_5, Layer: mp3header.Layer3, BitRate: 8, SampleFreq: 8000}
+
+	tests := []struct {
+		name       string
+		header     mp3header.MP3Header
+		frameCount int64
+		delay      int
+		padding    int
+		want       time.Duration
+	}{
+		{"MPEG-1 Layer III", mpeg1, 100, 0, 0, 2612244897 * time.Nanosecond},
+		{"with encoder delay and padding", mpeg1, 100, 576, 1000, 2576507936 * time.Nanosecond},
+		{"MPEG-2.5 uses 576 samples per frame", mpeg25, 1000, 0, 0, 72 * time.Second},
+		{"does not overflow for long inputs", mpeg1, 50_000_000, 0, 0, 1306122448979591},
+		{"delay and padding exceed samples", mpeg1, 1, 1000, 1000, 0},
+		{"zero sample rate", mp3header.MP3Header{Layer: mp3header.Layer3}, 100, 0, 0, 0},
+		{"reserved sample rate", mp3header.MP3Header{Layer: mp3header.Layer3, BitRate: 128, SampleFreq: -1}, 100, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExactDuration(tt.header, tt.frameCount, tt.delay, tt.padding); got != tt.want {
+				t.Errorf("ExactDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurationFromFrameCount(t *testing.T) {
+	mpeg1 := mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer3, BitRate: 128, SampleFreq: 44100}
+	mpeg2 := mp3header.MP3Header{AudioVersion: mp3header.Version2, Layer: mp3header.Layer3, BitRate: 64, SampleFreq: 24000}
+
+	tests := []struct {
+		name       string
+		header     mp3header.MP3Header
+		frameCount int
+		want       time.Duration
+	}{
+		{"MPEG-1 Layer III", mpeg1, 100, 2612244897 * time.Nanosecond},
+		{"MPEG-2 Layer III", mpeg2, 1000, 24 * time.Second},
+		{"bit rate does not matter", mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer3, SampleFreq: 44100}, 100, 2612244897 * time.Nanosecond},
+		{"no frames", mpeg1, 0, 0},
+		{"unknown sample rate", mp3header.MP3Header{Layer: mp3header.Layer3}, 100, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DurationFromFrameCount(tt.frameCount, tt.header); got != tt.want {
+				t.Errorf("DurationFromFrameCount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetInfo_ReservedSampleFreq(t *testing.T) {
+	// sample rate index 11, reserved
+	data := generateMP3(emptyTag, 0xFFFB9C44, testFrameLength, 100)
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"estimate", nil},
+		{"full scan", []Option{WithFullScan()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetInfo(bytes.NewReader(data), int64(len(data)), tt.opts...)
+
+			if !errors.Is(err, errZeroSampleFreq) {
+				t.Errorf("GetInfo() error = %v, want %v", err, errZeroSampleFreq)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package mp3len_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"mp3len"
+)
+
+// bucket stands in for a cloud object store, whose GET requests take a byte
+// range.
+type bucket map[string][]byte
+
+func (b bucket) getRange(key string, start, end int64) ([]byte, error) {
+	object := b[key]
+
+	if end > int64(len(object)) {
+		end = int64(len(object))
+	}
+
+	return object[start:end], nil
+}
+
+// objectReaderAt reads an object with one ranged GET per ReadAt.
+type objectReaderAt struct {
+	bucket bucket
+	key    string
+}
+
+func (o objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	// e.g. Range: bytes=off-(off+len(p)-1)
+	data, err := o.bucket.getRange(o.key, off, off+int64(len(p)))
+
+	if err != nil {
+		return 0, err
+	}
+
+	n := copy(p, data)
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+func ExampleGetInfoAt() {
+	// 100 frames of MPEG-1 Layer III at 128 kbps
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x44})
+	b := bucket{"episode.mp3": bytes.Repeat(frame, 100)}
+
+	// the size would come from a HEAD request
+	size := int64(len(b["episode.mp3"]))
+
+	metadata, err := mp3len.GetInfoAt(objectReaderAt{b, "episode.mp3"}, size)
+
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(metadata.Duration())
+	// Output: 2.606s
+}
@@ -0,0 +1,118 @@
+package mp3len
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+
+	"mp3len/internal/id3"
+	"mp3len/internal/readers"
+)
+
+const lenOfID3v1 = 128
+
+var id3v1Flag = []byte("TAG")
+var apeFlag = []byte("APETAGEX")
+
+const lenOfAPEHeader = 32
+const apeFlagHasHeader = 1 << 31
+
+type audioExtractor struct {
+	br      *bufio.Reader
+	started bool
+	pending []byte // bytes read but held back, they may be an ID3v1 tag
+	err     error
+}
+
+// NewAudioExtractor returns a reader yielding only the audio of r: leading
+// ID3v2 and APE tags are skipped, and a trailing 128-byte ID3v1 tag is
+// dropped. When totalSize is positive, no more than totalSize bytes are read.
+//
+// The last 128 bytes read are held back until EOF to find out whether they
+// are an ID3v1 tag.
+func NewAudioExtractor(r io.Reader, totalSize int64) io.Reader {
+	r = readers.GuardProgress(r)
+
+	if totalSize > 0 {
+		r = io.LimitReader(r, totalSize)
+	}
+
+	return &audioExtractor{br: bufio.NewReader(r)}
+}
+
+func (a *audioExtractor) Read(p []byte) (int, error) {
+	if !a.started {
+		a.started = true
+
+		if err := a.skipLeadingTags(); err != nil {
+			a.err = err
+		}
+	}
+
+	// read until more than the last 128 bytes are at hand, or EOF
+	chunk := make([]byte, lenOfID3v1+len(p))
+
+	for len(a.pending) <= lenOfID3v1+len(p) && a.err == nil {
+		n, err := a.br.Read(chunk)
+		a.pending = append(a.pending, chunk[:n]...)
+		a.err = err
+	}
+
+	available := len(a.pending) - lenOfID3v1
+
+	if a.err != nil {
+		available = len(a.pending)
+
+		if a.err == io.EOF && hasID3v1(a.pending) {
+			available -= lenOfID3v1
+		}
+	}
+
+	if available <= 0 {
+		a.pending = nil
+		return 0, a.err
+	}
+
+	n := copy(p, a.pending[:available])
+	a.pending = a.pending[n:]
+
+	if n == available && a.err != nil {
+		a.pending = nil
+		return n, a.err
+	}
+
+	return n, nil
+}
+
+// skipLeadingTags discards every ID3v2 or APE tag at the current position.
+func (a *audioExtractor) skipLeadingTags() error {
+	for {
+		start, _ := a.br.Peek(lenOfAPEHeader)
+
+		switch {
+		case id3.HasLeadingTag(start, false):
+			if _, err := id3.NewSkipReader(a.br).ReadThrough(); err != nil {
+				return err
+			}
+		case bytes.HasPrefix(start, apeFlag) && len(start) == lenOfAPEHeader:
+			// the size excludes the header, if any
+			size := int64(binary.LittleEndian.Uint32(start[12:16]))
+			if binary.LittleEndian.Uint32(start[20:24])&apeFlagHasHeader != 0 {
+				size += lenOfAPEHeader
+			}
+
+			if _, err := io.CopyN(ioutil.Discard, a.br, size); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// hasID3v1 tells whether data ends with an ID3v1 tag.
+func hasID3v1(data []byte) bool {
+	return len(data) >= lenOfID3v1 && bytes.HasPrefix(data[len(data)-lenOfID3v1:], id3v1Flag)
+}
@@ -0,0 +1,87 @@
+package mp3len
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+)
+
+func generateID3v1(title string) []byte {
+	tag := make([]byte, lenOfID3v1)
+	copy(tag, "TAG")
+	copy(tag[3:], title)
+	return tag
+}
+
+func generateAPETag(body []byte) []byte {
+	header := make([]byte, lenOfAPEHeader)
+	copy(header, apeFlag)
+	binary.LittleEndian.PutUint32(header[8:], 2000)
+	binary.LittleEndian.PutUint32(header[12:], uint32(len(body)))
+	binary.LittleEndian.PutUint32(header[20:], apeFlagHasHeader)
+	return append(header, body...)
+}
+
+func TestNewAudioExtractor(t *testing.T) {
+	audio := generateMP3(nil, testHeaderBits, testFrameLength, 10)
+	v2, err := ioutil.ReadFile("./internal/id3/testdata/id3_compact.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := generateID3v1("Title")
+
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"neither", audio},
+		{"ID3v2 only", join(v2, audio)},
+		{"ID3v1 only", join(audio, v1)},
+		{"both", join(v2, audio, v1)},
+		{"APE and ID3v2", join(generateAPETag(make([]byte, 100)), v2, audio, v1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, totalSize := range []int64{0, int64(len(tt.data))} {
+				got, err := ioutil.ReadAll(NewAudioExtractor(bytes.NewReader(tt.data), totalSize))
+
+				if err != nil {
+					t.Fatalf("ReadAll() error = %v", err)
+				}
+
+				if !bytes.Equal(got, audio) {
+					t.Errorf("ReadAll() = %d bytes, want the %d bytes of audio", len(got), len(audio))
+				}
+			}
+
+			got, err := ioutil.ReadAll(NewAudioExtractor(iotest.OneByteReader(bytes.NewReader(tt.data)), -1))
+
+			if err != nil || !bytes.Equal(got, audio) {
+				t.Errorf("ReadAll() one byte at a time = (%d bytes, %v), want the %d bytes of audio", len(got), err, len(audio))
+			}
+		})
+	}
+
+	t.Run("shorter than an ID3v1 tag", func(t *testing.T) {
+		got, err := ioutil.ReadAll(NewAudioExtractor(bytes.NewReader([]byte("TAG")), 3))
+
+		if err != nil || string(got) != "TAG" {
+			t.Errorf("ReadAll() = (%q, %v), want (\"TAG\", nil)", got, err)
+		}
+	})
+
+	t.Run("totalSize cuts the input", func(t *testing.T) {
+		data := join(audio, v1, []byte("garbage after the declared size"))
+		got, err := ioutil.ReadAll(NewAudioExtractor(bytes.NewReader(data), int64(len(audio)+len(v1))))
+
+		if err != nil || !bytes.Equal(got, audio) {
+			t.Errorf("ReadAll() = (%d bytes, %v), want the %d bytes of audio", len(got), err, len(audio))
+		}
+	})
+}
@@ -0,0 +1,43 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package mp3len
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestGetInfoFromFile_FIFO(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.mp3")
+
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Skipf("cannot create a FIFO: %v", err)
+	}
+
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+
+	go func() {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			t.Errorf("opening FIFO for writing: %v", err)
+			return
+		}
+		defer f.Close()
+
+		f.Write(data)
+	}()
+
+	metadata, err := GetInfoFromFile(path)
+
+	if err != nil {
+		t.Fatalf("GetInfoFromFile() error = %v", err)
+	}
+
+	if want := 2612244897 * time.Nanosecond; metadata.Duration() != want {
+		t.Errorf("GetInfoFromFile() Duration() = %v, want %v", metadata.Duration(), want)
+	}
+}
@@ -0,0 +1,120 @@
+package mp3len
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1F, 0x8B}
+
+// lenOfGzipTrailer is the size of the ISIZE field ending a gzip stream, the
+// uncompressed size modulo 2^32, little-endian.
+const lenOfGzipTrailer = 4
+
+// hasGzipMagic tells whether start, the first bytes of an input, is that of a
+// gzip stream.
+func hasGzipMagic(start []byte) bool {
+	return bytes.HasPrefix(start, gzipMagic)
+}
+
+// gzipSize returns the uncompressed size declared by trailer, the ISIZE field
+// ending a gzip stream of compressedSize bytes, or -1 when it can't be trusted.
+//
+// ISIZE only holds the size modulo 2^32, so inputs of 4 GiB or more aren't
+// told apart from smaller ones: it's only trusted for compressed streams under
+// 4 GiB, MP3 audio hardly compressing. It's also that of the last member of a
+// stream of several, which this doesn't detect.
+func gzipSize(trailer []byte, compressedSize int64) int64 {
+	if len(trailer) < lenOfGzipTrailer || compressedSize >= 1<<32 {
+		return -1
+	}
+
+	return int64(binary.LittleEndian.Uint32(trailer))
+}
+
+// getInfoGzip measures ra, a gzip stream of size bytes, from its uncompressed
+// content, whose size is read from the trailer. Tags at the end of the
+// content can't be looked for.
+func getInfoGzip(ra io.ReaderAt, size int64, o *options) (*Metadata, error) {
+	total := int64(-1)
+
+	if size >= lenOfGzipTrailer {
+		trailer := make([]byte, lenOfGzipTrailer)
+
+		if _, err := ra.ReadAt(trailer, size-lenOfGzipTrailer); err != nil {
+			return new(Metadata), err
+		}
+
+		total = gzipSize(trailer, size)
+	}
+
+	gz, err := gzip.NewReader(io.NewSectionReader(ra, 0, size))
+
+	if err != nil {
+		return new(Metadata), err
+	}
+
+	defer gz.Close()
+
+	return getInfo(gz, total, o, new(Metadata))
+}
+
+// isGzip tells whether the response body is gzip-compressed as a whole, which
+// the client doesn't undo when asking for a range.
+func isGzip(resp *http.Response) bool {
+	return resp.Header.Get("Content-Encoding") == "gzip"
+}
+
+// gunzip returns the uncompressed content of rr, a gzip stream, with its size
+// read from the trailer when the server honours ranges, or else -1.
+func (rr *rangeReader) gunzip() (io.Reader, int64, error) {
+	total := int64(-1)
+
+	if rr.more && rr.total >= lenOfGzipTrailer {
+		trailer, err := rr.readTrailer()
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		total = gzipSize(trailer, rr.total)
+	}
+
+	gz, err := gzip.NewReader(rr)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return gz, total, nil
+}
+
+// readTrailer asks for the last lenOfGzipTrailer bytes of the file, or returns
+// nil when the server answers with something else.
+func (rr *rangeReader) readTrailer() ([]byte, error) {
+	first := rr.total - lenOfGzipTrailer
+	resp, err := rr.get(fmt.Sprintf("bytes=%d-%d", first, rr.total-1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if start, _, err := parseContentRange(resp.Header.Get("Content-Range")); resp.StatusCode != http.StatusPartialContent || err != nil || start != first {
+		return nil, nil
+	}
+
+	trailer := make([]byte, lenOfGzipTrailer)
+
+	if _, err := io.ReadFull(resp.Body, trailer); err != nil {
+		return nil, err
+	}
+
+	return trailer, nil
+}
@@ -0,0 +1,101 @@
+package mp3len
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mp3len/mp3lentest"
+)
+
+// gzipData compresses data as a single gzip member.
+func gzipData(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func Test_gzipSize(t *testing.T) {
+	tests := []struct {
+		name           string
+		trailer        []byte
+		compressedSize int64
+		want           int64
+	}{
+		{"small", []byte{0x10, 0x27, 0x00, 0x00}, 1000, 10000},
+		{"4 GiB compressed", []byte{0x10, 0x27, 0x00, 0x00}, 1 << 32, -1},
+		{"truncated", []byte{0x10, 0x27}, 1000, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gzipSize(tt.trailer, tt.compressedSize); got != tt.want {
+				t.Errorf("gzipSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetInfoAt_Gzip(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	compressed := gzipData(t, data)
+
+	metadata, err := GetInfoAt(bytes.NewReader(compressed), int64(len(compressed)))
+	if err != nil {
+		t.Fatalf("GetInfoAt() error = %v", err)
+	}
+
+	if metadata.Duration() != 2606*time.Millisecond || metadata.Confidence() != ConfidenceEstimated {
+		t.Errorf("GetInfoAt() = %v (%v), want %v (%v)", metadata.Duration(), metadata.Confidence(), 2606*time.Millisecond, ConfidenceEstimated)
+	}
+}
+
+func TestGetInfoFromURL_Gzip(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	fixtures := map[string][]byte{"/test.mp3": gzipData(t, data)}
+
+	tests := []struct {
+		name           string
+		opts           []mp3lentest.Option
+		wantConfidence Confidence
+	}{
+		// from the size in the trailer
+		{"honours Range", nil, ConfidenceEstimated},
+		// by counting frames
+		{"ignores Range", []mp3lentest.Option{mp3lentest.WithoutRanges()}, ConfidenceExact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := mp3lentest.Handler(fixtures, tt.opts...)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "gzip")
+				handler.ServeHTTP(w, r)
+			}))
+			t.Cleanup(server.Close)
+
+			metadata, err := GetInfoFromURL(server.URL + "/test.mp3")
+			if err != nil {
+				t.Fatalf("GetInfoFromURL() error = %v", err)
+			}
+
+			if metadata.Confidence() != tt.wantConfidence {
+				t.Errorf("GetInfoFromURL() Confidence() = %v, want %v", metadata.Confidence(), tt.wantConfidence)
+			}
+
+			if d := metadata.Duration() - 2606*time.Millisecond; d < -10*time.Millisecond || d > 10*time.Millisecond {
+				t.Errorf("GetInfoFromURL() Duration() = %v, want about %v", metadata.Duration(), 2606*time.Millisecond)
+			}
+		})
+	}
+}
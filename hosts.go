@@ -0,0 +1,40 @@
+package mp3len
+
+import (
+	"context"
+	"sync"
+)
+
+// hostLimiter bounds the number of inputs measured at the same time from each
+// host, shared by the calls given the same WithMaxConcurrentHosts option.
+type hostLimiter struct {
+	n int
+
+	mu    sync.Mutex
+	slots map[string]chan struct{} // by hostname, holding a token per input
+}
+
+func newHostLimiter(n int) *hostLimiter {
+	return &hostLimiter{n: n, slots: make(map[string]chan struct{})}
+}
+
+// acquire waits for a slot of host, and returns the function releasing it. It
+// gives up with the error of ctx as soon as ctx is done.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (release func(), err error) {
+	l.mu.Lock()
+	slots, ok := l.slots[host]
+
+	if !ok {
+		slots = make(chan struct{}, l.n)
+		l.slots[host] = slots
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,161 @@
+package mp3len
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"mp3len/mp3lentest"
+)
+
+func TestWithMaxConcurrentHosts(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	fixtures := mp3lentest.Handler(map[string][]byte{"/test.mp3": data})
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := map[string]int{}, map[string]int{}
+
+	arrived := make(chan struct{}, 100)
+	gate := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.Host)
+
+		mu.Lock()
+		inFlight[host]++
+		if inFlight[host] > maxInFlight[host] {
+			maxInFlight[host] = inFlight[host]
+		}
+		mu.Unlock()
+
+		arrived <- struct{}{}
+		<-gate
+
+		fixtures.ServeHTTP(w, r)
+
+		mu.Lock()
+		inFlight[host]--
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	// two hostnames of the same server, three inputs each
+	urls := []string{
+		server.URL + "/test.mp3",
+		strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/test.mp3",
+	}
+
+	limit := WithMaxConcurrentHosts(2)
+	errs := make(chan error)
+
+	for i := 0; i < 6; i++ {
+		go func(u string) {
+			_, err := GetInfoFromURL(u, limit)
+			errs <- err
+		}(urls[i%2])
+	}
+
+	// as many as allowed for both hosts, held until then
+	for i := 0; i < 4; i++ {
+		<-arrived
+	}
+
+	close(gate)
+
+	for i := 0; i < 6; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("GetInfoFromURL() error = %v", err)
+		}
+	}
+
+	want := map[string]int{"127.0.0.1": 2, "localhost": 2}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for host, n := range want {
+		if maxInFlight[host] != n {
+			t.Errorf("requests in flight to %s = %v at most, want %v", host, maxInFlight[host], n)
+		}
+	}
+}
+
+func TestWithMaxConcurrentHosts_Canceled(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	fixtures := mp3lentest.Handler(map[string][]byte{"/test.mp3": data})
+
+	arrived := make(chan struct{}, 10)
+	gate := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-gate
+		fixtures.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	limit := WithMaxConcurrentHosts(1)
+	first := make(chan error)
+
+	go func() {
+		_, err := GetInfoFromURL(server.URL+"/test.mp3", limit)
+		first <- err
+	}()
+
+	<-arrived
+
+	// waiting for the slot held by the first
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GetInfoFromURL(server.URL+"/test.mp3", limit, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetInfoFromURL() error = %v, want %v", err, context.Canceled)
+	}
+
+	close(gate)
+
+	if err := <-first; err != nil {
+		t.Errorf("GetInfoFromURL() error = %v", err)
+	}
+}
+
+func TestHostLimiter(t *testing.T) {
+	l := newHostLimiter(1)
+	ctx := context.Background()
+
+	release, err := l.acquire(ctx, "a.example")
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	// another host has slots of its own
+	releaseB, err := l.acquire(ctx, "b.example")
+	if err != nil {
+		t.Fatalf("acquire() of another host error = %v", err)
+	}
+
+	releaseB()
+
+	acquired := make(chan struct{})
+
+	go func() {
+		if release, err := l.acquire(ctx, "a.example"); err == nil {
+			release()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquire() succeeded while the slot is held")
+	default:
+	}
+
+	release()
+	<-acquired
+}
@@ -0,0 +1,100 @@
+// Package buffers manages the scratch buffers used while skipping and
+// decoding, so that callers can share them between reads.
+package buffers
+
+import (
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// Pool hands out scratch buffers. Implementations must be safe for concurrent
+// use.
+type Pool interface {
+	// Get returns a buffer of length n. Its content is undefined.
+	Get(n int) []byte
+	// Put gives back a buffer returned by Get, which must not be used anymore.
+	Put(b []byte)
+}
+
+// Size is the capacity of the buffers kept by Default. Larger requests are
+// allocated and dropped after use.
+const Size = 8 << 10
+
+// Default is the Pool used when none is given, backed by a sync.Pool.
+var Default Pool = new(syncPool)
+
+type syncPool struct {
+	pool sync.Pool
+}
+
+func (p *syncPool) Get(n int) []byte {
+	if n > Size {
+		return make([]byte, n)
+	}
+
+	if b, ok := p.pool.Get().(*[]byte); ok {
+		return (*b)[:n]
+	}
+
+	return make([]byte, n, Size)
+}
+
+func (p *syncPool) Put(b []byte) {
+	if cap(b) != Size {
+		return
+	}
+
+	b = b[:Size]
+	p.pool.Put(&b)
+}
+
+// Discard reads and drops n bytes from r through a buffer of pool, or Default
+// when pool is nil. Like io.CopyN, it returns io.EOF if r ends before n bytes.
+func Discard(pool Pool, r io.Reader, n int64) (int64, error) {
+	return Copy(pool, ioutil.Discard, r, n)
+}
+
+// Copy is like Discard, but writes the bytes to w. It returns the bytes read
+// from r, which were all written unless w failed.
+func Copy(pool Pool, w io.Writer, r io.Reader, n int64) (int64, error) {
+	if pool == nil {
+		pool = Default
+	}
+
+	size := int64(Size)
+	if n < size {
+		size = n
+	}
+
+	buf := pool.Get(int(size))
+	defer pool.Put(buf)
+
+	var copied int64
+
+	for copied < n {
+		chunk := buf
+		if remaining := n - copied; remaining < int64(len(chunk)) {
+			chunk = chunk[:remaining]
+		}
+
+		m, err := r.Read(chunk)
+		copied += int64(m)
+
+		if m > 0 {
+			if _, werr := w.Write(chunk[:m]); werr != nil {
+				return copied, werr
+			}
+		}
+
+		if err == io.EOF && copied < n {
+			return copied, io.EOF
+		}
+
+		if err != nil && err != io.EOF {
+			return copied, err
+		}
+	}
+
+	return copied, nil
+}
@@ -0,0 +1,125 @@
+package buffers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		wantCap int
+	}{
+		{"small", 10, Size},
+		{"exact", Size, Size},
+		{"large", Size + 1, Size + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Default.Get(tt.n)
+
+			if len(b) != tt.n || cap(b) != tt.wantCap {
+				t.Errorf("Default.Get(%d) len = %d, cap = %d, want %d, %d", tt.n, len(b), cap(b), tt.n, tt.wantCap)
+			}
+
+			Default.Put(b)
+		})
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	data := make([]byte, 3*Size+5)
+
+	tests := []struct {
+		name    string
+		n       int64
+		want    int64
+		wantErr error
+	}{
+		{"none", 0, 0, nil},
+		{"some", 100, 100, nil},
+		{"several buffers", 2*Size + 1, 2*Size + 1, nil},
+		{"all", int64(len(data)), int64(len(data)), nil},
+		{"past the end", int64(len(data)) + 1, int64(len(data)), io.EOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(data)
+			got, err := Discard(nil, r, tt.n)
+
+			if err != tt.wantErr {
+				t.Fatalf("Discard() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Discard() = %v, want %v", got, tt.want)
+			}
+
+			if rest := int64(r.Len()); rest != int64(len(data))-tt.want {
+				t.Errorf("Discard() left %d bytes, want %d", rest, int64(len(data))-tt.want)
+			}
+		})
+	}
+}
+
+func TestDefault_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				b := Default.Get(100)
+				for k := range b {
+					b[k] = byte(i)
+				}
+				for k := range b {
+					if b[k] != byte(i) {
+						t.Errorf("buffer shared between goroutines")
+						return
+					}
+				}
+				Default.Put(b)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func TestCopy(t *testing.T) {
+	data := make([]byte, 2*Size+5)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	var buf bytes.Buffer
+	got, err := Copy(nil, &buf, bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+
+	if got != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("Copy() = %v, wrote %d bytes, want %v", got, buf.Len(), len(data))
+	}
+
+	if _, err := Copy(nil, failingWriter{}, bytes.NewReader(data), 10); err != errWrite {
+		t.Errorf("Copy() error = %v, want %v", err, errWrite)
+	}
+}
+
+var errWrite = errors.New("write failed")
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
@@ -0,0 +1,138 @@
+package id3
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Commercial holds the content of a COMR frame.
+type Commercial struct {
+	// Price is one or more prices, each a currency code followed by the
+	// amount, separated by "/", e.g. "USD9.99/EUR8.50".
+	Price string
+
+	// ValidUntil is the date the prices are valid until, as YYYYMMDD.
+	ValidUntil string
+
+	ContactURL string
+
+	// ReceivedAs tells how the audio is delivered, e.g. $01 for a standard
+	// CD album, $02 for a compressed audio file or $03 for a file over the
+	// Internet.
+	ReceivedAs byte
+
+	Seller      string
+	Description string
+
+	// LogoMIME is the MIME type of Logo, e.g. "image/png". Both are empty
+	// without logo.
+	LogoMIME string
+	Logo     []byte
+}
+
+var errTruncatedCommercial = errors.New("COMR frame is truncated")
+
+// Commercial parses the frame data as commercial information, i.e.
+//
+//	Text encoding      $xx
+//	Price string       <text string> $00
+//	Valid until        <text string>
+//	Contact URL        <text string> $00
+//	Received as        $xx
+//	Name of seller     <text string according to encoding> $00 (00)
+//	Description        <text string according to encoding> $00 (00)
+//	Picture MIME type  <string> $00
+//	Seller logo        <binary data>
+//
+// where valid until is 8 characters long. The logo and its MIME type may be
+// left out. Returns error when the frame is not COMR, or is malformed.
+func (frame *Frame) Commercial() (*Commercial, error) {
+	if frame.ID != "COMR" {
+		return nil, fmt.Errorf("Commercial(): Frame %q is not a commercial frame", frame.ID)
+	}
+
+	if len(frame.Data) == 0 {
+		return nil, errors.New("COMR frame has no text encoding")
+	}
+
+	encoding := frame.Data[0]
+	width, ok := textEncodingWidth(encoding)
+
+	if !ok {
+		return nil, fmt.Errorf("COMR frame has an invalid text encoding flag %X", encoding)
+	}
+
+	data := frame.Data[1:]
+	c := new(Commercial)
+
+	price, data, ok := cutTerminated(data, 1)
+	if !ok {
+		return nil, errors.New("COMR price is not terminated")
+	}
+	c.Price = decodeLatin1Text(price)
+
+	if len(data) < 8 {
+		return nil, errTruncatedCommercial
+	}
+	c.ValidUntil = decodeLatin1Text(data[:8])
+	data = data[8:]
+
+	contact, data, ok := cutTerminated(data, 1)
+	if !ok {
+		return nil, errors.New("COMR contact URL is not terminated")
+	}
+	c.ContactURL = decodeLatin1Text(contact)
+
+	if len(data) == 0 {
+		return nil, errTruncatedCommercial
+	}
+	c.ReceivedAs = data[0]
+	data = data[1:]
+
+	seller, data, ok := cutTerminated(data, width)
+	if !ok {
+		return nil, errors.New("COMR seller name is not terminated")
+	}
+
+	var err error
+
+	if c.Seller, err = decodeTextValue(seller, encoding, frame.strictBOM); err != nil {
+		return nil, fmt.Errorf("COMR seller name: %w", err)
+	}
+
+	// the description is the last field when there is no logo, and may then
+	// be left unterminated
+	description, data, ok := cutTerminated(data, width)
+	if !ok {
+		description, data = data, nil
+	}
+
+	if c.Description, err = decodeTextValue(description, encoding, frame.strictBOM); err != nil {
+		return nil, fmt.Errorf("COMR description: %w", err)
+	}
+
+	if len(data) == 0 {
+		return c, nil
+	}
+
+	mime, data, ok := cutTerminated(data, 1)
+	if !ok {
+		return nil, errors.New("COMR picture MIME type is not terminated")
+	}
+	c.LogoMIME = decodeLatin1Text(mime)
+	c.Logo = data
+
+	return c, nil
+}
+
+// cutTerminated splits data after the text at its start, made of units of
+// width bytes, and its terminator. Returns false when there's no terminator.
+func cutTerminated(data []byte, width int) (text, rest []byte, ok bool) {
+	end := descriptionEnd(data, width)
+
+	if end < 0 {
+		return nil, data, false
+	}
+
+	return data[:end], data[end+width:], true
+}
@@ -0,0 +1,93 @@
+package id3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrame_Commercial(t *testing.T) {
+	const head = "USD9.99/EUR8.50\x0020261231https://example.com/buy\x00\x02"
+
+	tests := []struct {
+		name    string
+		frame   Frame
+		want    *Commercial
+		wantErr bool
+	}{
+		{
+			name:  "with logo",
+			frame: Frame{ID: "COMR", Data: []byte("\x00" + head + "Seller\x00Album\x00image/png\x00\x89PNG")},
+			want: &Commercial{
+				Price:       "USD9.99/EUR8.50",
+				ValidUntil:  "20261231",
+				ContactURL:  "https://example.com/buy",
+				ReceivedAs:  0x02,
+				Seller:      "Seller",
+				Description: "Album",
+				LogoMIME:    "image/png",
+				Logo:        []byte("\x89PNG"),
+			},
+		},
+		{
+			name:  "UTF-16 without logo",
+			frame: Frame{ID: "COMR", Data: []byte("\x01" + head + "\xFF\xFES\x00e\x00\x00\x00\xFF\xFEA\x00\x00\x00")},
+			want: &Commercial{
+				Price:       "USD9.99/EUR8.50",
+				ValidUntil:  "20261231",
+				ContactURL:  "https://example.com/buy",
+				ReceivedAs:  0x02,
+				Seller:      "Se",
+				Description: "A",
+			},
+		},
+		{
+			name:  "unterminated description",
+			frame: Frame{ID: "COMR", Data: []byte("\x00" + head + "Seller\x00Album")},
+			want: &Commercial{
+				Price:       "USD9.99/EUR8.50",
+				ValidUntil:  "20261231",
+				ContactURL:  "https://example.com/buy",
+				ReceivedAs:  0x02,
+				Seller:      "Seller",
+				Description: "Album",
+			},
+		},
+		{
+			name:    "truncated date",
+			frame:   Frame{ID: "COMR", Data: []byte("\x00USD1\x002026")},
+			wantErr: true,
+		},
+		{
+			name:    "unterminated seller",
+			frame:   Frame{ID: "COMR", Data: []byte("\x00" + head + "Seller")},
+			wantErr: true,
+		},
+		{
+			name:    "invalid encoding",
+			frame:   Frame{ID: "COMR", Data: []byte("\x07" + head + "Seller\x00\x00")},
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			frame:   Frame{ID: "COMR"},
+			wantErr: true,
+		},
+		{
+			name:    "not COMR",
+			frame:   Frame{ID: "TIT2", Data: []byte{0x00, 'a'}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.frame.Commercial()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Commercial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Commercial() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
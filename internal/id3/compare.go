@@ -0,0 +1,144 @@
+package id3
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+// CompareOptions tunes Frame.Equal and Tag.Diff. The zero value compares
+// frames byte by byte, including flags.
+type CompareOptions struct {
+	IgnoreFlags bool // don't compare frame flags
+
+	// SemanticText compares text frames by their decoded text, so the same
+	// string in Latin-1 and UTF-16 is equal.
+	SemanticText bool
+
+	// IgnoreTerminators ignores trailing 0x00 bytes of text frames.
+	IgnoreTerminators bool
+}
+
+// ChangeType tells how a frame differs between two tags.
+type ChangeType int
+
+const (
+	ChangeAdded ChangeType = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+func (c ChangeType) String() string {
+	switch c {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	default:
+		return fmt.Sprintf("ChangeType(%d)", int(c))
+	}
+}
+
+// Change is a frame that differs between two tags. Before and After summarize
+// the frame content (text, or size and hash of binary data), and are empty
+// for added and removed frames respectively.
+type Change struct {
+	Type   ChangeType
+	ID     string
+	Before string
+	After  string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s: %q -> %q", c.Type, c.ID, c.Before, c.After)
+}
+
+// Equal reports whether frame and other have the same ID and content. nil and
+// empty Data are equal.
+func (frame *Frame) Equal(other Frame, opts CompareOptions) bool {
+	if frame.ID != other.ID {
+		return false
+	}
+
+	if !opts.IgnoreFlags && frame.Flags != other.Flags {
+		return false
+	}
+
+	if frame.hasText() && opts.SemanticText {
+		a, errA := frame.Text()
+		b, errB := other.Text()
+
+		if errA == nil && errB == nil {
+			return a == b
+		}
+	}
+
+	a, b := frame.Data, other.Data
+
+	if frame.hasText() && opts.IgnoreTerminators {
+		a, b = bytes.TrimRight(a, "\x00"), bytes.TrimRight(b, "\x00")
+	}
+
+	return bytes.Equal(a, b)
+}
+
+// summary describes the frame content for a Change.
+func (frame *Frame) summary() string {
+	if text, err := frame.Text(); err == nil {
+		return text
+	}
+
+	return fmt.Sprintf("%d bytes, sha256:%x", len(frame.Data), sha256.Sum256(frame.Data))
+}
+
+// Diff lists the frames added, removed or modified in other compared to t.
+// Frames are paired by ID and order of appearance, so the second COMM of t is
+// compared to the second COMM of other.
+func (t *Tag) Diff(other *Tag, opts CompareOptions) []Change {
+	changes := make([]Change, 0)
+	theirs := indexFrames(other.Frames)
+	seen := make(map[string]int)
+
+	for i := range t.Frames {
+		frame := &t.Frames[i]
+		nth := seen[frame.ID]
+		seen[frame.ID]++
+
+		if nth >= len(theirs[frame.ID]) {
+			changes = append(changes, Change{Type: ChangeRemoved, ID: frame.ID, Before: frame.summary()})
+			continue
+		}
+
+		otherFrame := theirs[frame.ID][nth]
+
+		if !frame.Equal(*otherFrame, opts) {
+			changes = append(changes, Change{Type: ChangeModified, ID: frame.ID, Before: frame.summary(), After: otherFrame.summary()})
+		}
+	}
+
+	added := make(map[string]int)
+
+	for i := range other.Frames {
+		frame := &other.Frames[i]
+		nth := added[frame.ID]
+		added[frame.ID]++
+
+		if nth >= seen[frame.ID] {
+			changes = append(changes, Change{Type: ChangeAdded, ID: frame.ID, After: frame.summary()})
+		}
+	}
+
+	return changes
+}
+
+func indexFrames(frames []Frame) map[string][]*Frame {
+	index := make(map[string][]*Frame)
+
+	for i := range frames {
+		index[frames[i].ID] = append(index[frames[i].ID], &frames[i])
+	}
+
+	return index
+}
@@ -0,0 +1,111 @@
+package id3
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFrame_Equal(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     Frame
+		b     Frame
+		opts  CompareOptions
+		equal bool
+	}{
+		{"same", textFrame("TIT2", "Title"), textFrame("TIT2", "Title"), CompareOptions{}, true},
+		{"different ID", textFrame("TIT2", "Title"), textFrame("TALB", "Title"), CompareOptions{}, false},
+		{"nil and empty data", Frame{ID: "TENC"}, Frame{ID: "TENC", Data: []byte{}}, CompareOptions{}, true},
+		{"different flags", Frame{ID: "PRIV", Flags: 1}, Frame{ID: "PRIV"}, CompareOptions{}, false},
+		{"ignore flags", Frame{ID: "PRIV", Flags: 1}, Frame{ID: "PRIV"}, CompareOptions{IgnoreFlags: true}, true},
+		{
+			name:  "terminator",
+			a:     Frame{ID: "TIT2", Data: []byte("\x00Title\x00")},
+			b:     Frame{ID: "TIT2", Data: []byte("\x00Title")},
+			equal: false,
+		},
+		{
+			name:  "ignore terminator",
+			a:     Frame{ID: "TIT2", Data: []byte("\x00Title\x00")},
+			b:     Frame{ID: "TIT2", Data: []byte("\x00Title")},
+			opts:  CompareOptions{IgnoreTerminators: true},
+			equal: true,
+		},
+		{
+			name:  "terminators of binary frames matter",
+			a:     Frame{ID: "PRIV", Data: []byte("\x00Title\x00")},
+			b:     Frame{ID: "PRIV", Data: []byte("\x00Title")},
+			opts:  CompareOptions{IgnoreTerminators: true},
+			equal: false,
+		},
+		{
+			name:  "semantic text across encodings",
+			a:     Frame{ID: "TIT2", Data: []byte("\x00AB\x00")},
+			b:     Frame{ID: "TIT2", Data: []byte("\x01\xFF\xFEA\x00B\x00")},
+			opts:  CompareOptions{SemanticText: true},
+			equal: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b, tt.opts); got != tt.equal {
+				t.Errorf("Equal() = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestTag_Diff(t *testing.T) {
+	picture := Frame{ID: "APIC", Data: []byte("\x00image/jpeg\x00\x03\x00\xFF\xD8\xFF")}
+	otherPicture := Frame{ID: "APIC", Data: []byte("\x00image/jpeg\x00\x03\x00\xFF\xD8\x00")}
+
+	before := &Tag{Frames: []Frame{
+		{ID: "TIT2", Data: []byte("\x00Title\x00")},
+		textFrame("TALB", "Album"),
+		{ID: "COMM", Data: []byte("\x00eng\x00ignored")},
+		picture,
+		textFrame("TPE1", "Artist"),
+	}}
+	after := &Tag{Frames: []Frame{
+		{ID: "TIT2", Data: []byte("\x00Title")},
+		textFrame("TALB", "Album (Remastered)"),
+		otherPicture,
+		textFrame("TPE1", "Artist"),
+		textFrame("TPE1", "Featured Artist"),
+	}}
+
+	changes := after.Diff(after, CompareOptions{})
+	if len(changes) != 0 {
+		t.Errorf("Diff() with itself = %v, want none", changes)
+	}
+
+	changes = before.Diff(after, CompareOptions{IgnoreTerminators: true})
+
+	wantTypes := []ChangeType{ChangeModified, ChangeRemoved, ChangeModified, ChangeAdded}
+	wantIDs := []string{"TALB", "COMM", "APIC", "TPE1"}
+
+	var gotTypes []ChangeType
+	var gotIDs []string
+	for _, c := range changes {
+		gotTypes = append(gotTypes, c.Type)
+		gotIDs = append(gotIDs, c.ID)
+	}
+
+	if !reflect.DeepEqual(gotTypes, wantTypes) || !reflect.DeepEqual(gotIDs, wantIDs) {
+		t.Fatalf("Diff() = %v, want %v of %v", changes, wantTypes, wantIDs)
+	}
+
+	if changes[0].Before != "Album" || changes[0].After != "Album (Remastered)" {
+		t.Errorf("Diff() TALB change = %v", changes[0])
+	}
+
+	apic := changes[2]
+	if !strings.HasPrefix(apic.Before, "17 bytes, sha256:") || apic.Before == apic.After {
+		t.Errorf("Diff() APIC change = %v, want summaries by hash", apic)
+	}
+
+	if changes[3].After != "Featured Artist" {
+		t.Errorf("Diff() added TPE1 = %v", changes[3])
+	}
+}
@@ -1,22 +1,42 @@
 package id3
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
-	"io/ioutil"
+
+	"mp3len/internal/buffers"
+	"mp3len/internal/mp3header"
+	"mp3len/internal/readers"
 )
 
-var id3v2Flag = []byte("ID3") // first 3 bytes of an MP3 file with ID3v2 tag
-const lenOfHeader = 10        // fixed length defined by ID3v2 spec
+var id3v2Flag = []byte("ID3")          // first 3 bytes of an MP3 file with ID3v2 tag
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF} // left by text tools in some broken files
+const lenOfHeader = 10                 // fixed length defined by ID3v2 spec
+
+// ErrTooManyFrames is returned when a tag has more frames than Decoder.MaxFrames.
+var ErrTooManyFrames = errors.New("too many frames in ID3 tag")
+
+// ErrCRCMismatch is returned in strict mode when the frames don't match the
+// CRC-32 of the extended header.
+var ErrCRCMismatch = errors.New("ID3 tag CRC mismatch")
+
+const (
+	flagExtendedHeader = 0x40   // in the tag header
+	flagCRC            = 0x8000 // in the ID3v2.3 extended header
+)
 
 type tagHeader struct {
 	version  uint8
 	revision uint8
 	flags    uint8
 	size     int // total size of the tag payload, excluding header
+	junk     int // bytes skipped before the header
 }
 
 // Tag is the whole ID3 Tag block, including a Header, many Frame elements,
@@ -27,16 +47,83 @@ type Tag struct {
 	Revision uint8
 	Flags    uint8
 
+	// Offset is where the tag header starts in the input of the Decoder,
+	// after any junk before it, and Size the size it declares, excluding the
+	// header. Both are 0 for tags built in code. See ByteRange.
+	Offset int
+	Size   int
+
 	Frames      []Frame
 	PaddingSize int
+
+	// CRC is the CRC-32 of the frames declared in the extended header, and
+	// CRCValid whether it matches them. CRCValid is nil when there's no CRC.
+	CRC      uint32
+	CRCValid *bool
+
+	// Raw holds the tag bytes exactly as read (header, frames and padding).
+	// Only set when Decoder.CaptureRaw is enabled.
+	Raw []byte
+
+	// LAME is the LAME tag of the first audio frame, which isn't part of the
+	// ID3 tag. Never set by Decoder, callers reading the audio may set it for
+	// ReplayGain to fall back on.
+	LAME *mp3header.LAMETag
 }
 
 // Decoder holds ID3 decoding state internally.
 type Decoder struct {
+	// CaptureRaw keeps a copy of every byte consumed by Decode in Tag.Raw.
+	// Disabled by default to avoid holding the whole tag in memory twice.
+	CaptureRaw bool
+
+	// Strict rejects inputs that deviate from the spec in ways otherwise
+	// tolerated, such as a UTF-8 BOM before the tag. The text of the frames
+	// decoded then is an ErrMissingBOM when UTF-16 without BOM, rather than
+	// decoded in a guessed byte order.
+	Strict bool
+
+	// MaxFrames bounds the number of frames decoded, guarding against tags
+	// stuffed with tiny frames. 0 means DefaultMaxFrames, and a negative value
+	// unlimited.
+	MaxFrames int
+
+	// MaxFrameBytes bounds the total payload of the frames kept in memory,
+	// checked before reading each frame. 0 means DefaultMaxFrameBytes, and a
+	// negative value unlimited.
+	MaxFrameBytes int
+
+	// BufferPool provides the buffer used to skip padding. Defaults to
+	// buffers.Default. Frame data is always allocated, as Tag keeps it, except
+	// for the images copied to PictureWriter.
+	BufferPool buffers.Pool
+
+	// SkipInternalPadding keeps looking for frames after a run of zeros,
+	// until the end of the tag, for tags written by taggers that leave gaps
+	// between frames. The zeros are counted in PaddingSize. By default, the
+	// first zero frame header starts the padding, as per the spec.
+	SkipInternalPadding bool
+
+	// FrameFilter, when set, is called with the ID and declared size of every
+	// frame before its payload is read, to skip or stop at frames the caller
+	// doesn't need, e.g. large pictures. See FrameAction.
+	FrameFilter func(id string, size int) FrameAction
+
+	// PictureWriter, when set, is called for every APIC frame kept by
+	// FrameFilter, once its MIME type, picture type and description are read.
+	// The image is then copied to the returned writer, or dropped if it's nil,
+	// instead of being held in memory: the frame is kept in Tag.Frames with
+	// nil Data, like with FrameSkip. Frames unsynchronised on their own, as
+	// flagged in ID3v2.4, are read whole, as usual.
+	PictureWriter func(meta *PictureMeta) io.Writer
+
 	r io.Reader
 	n int // n bytes that has already been read
 
-	tag *Tag
+	tag    *Tag
+	limits frameLimits
+	feed   feedState
+	crc    hash.Hash32 // of the frames read, when the tag has a CRC
 }
 
 // NewDecoder returns an ID3 decoder for reader r.
@@ -44,7 +131,9 @@ func NewDecoder(r io.Reader) *Decoder {
 	return &Decoder{r: r}
 }
 
-func readTagHeader(r io.Reader, h *tagHeader) (int, error) {
+// readTagHeader reads the 10-byte tag header. Unless strict, a leading UTF-8
+// BOM is skipped and counted in h.junk.
+func readTagHeader(r io.Reader, h *tagHeader, strict bool) (int, error) {
 	header := make([]byte, 10)
 	n, err := io.ReadFull(r, header)
 
@@ -52,6 +141,18 @@ func readTagHeader(r io.Reader, h *tagHeader) (int, error) {
 		return n, err
 	}
 
+	if !strict && bytes.Equal(header[0:3], utf8BOM) {
+		h.junk = len(utf8BOM)
+		copy(header, header[h.junk:])
+
+		nMore, err := io.ReadFull(r, header[len(header)-h.junk:])
+		n += nMore
+
+		if err != nil {
+			return n, err
+		}
+	}
+
 	if !bytes.Equal(header[0:3], id3v2Flag) {
 		return n, errors.New("invalid ID3 header")
 	}
@@ -64,9 +165,28 @@ func readTagHeader(r io.Reader, h *tagHeader) (int, error) {
 }
 
 // Decode decodes ID3 tag from reader. Returns error when failed.
+//
+// A tag whose frames were all read, but whose padding is cut short by the end
+// of the input, is returned with PaddingSize set to the padding actually read,
+// along with an error wrapping io.ErrUnexpectedEOF: the audio doesn't need the
+// padding. In strict mode, it's a failure like any other truncation.
+//
+// Likewise, a tag exceeding MaxFrames or MaxFrameBytes is returned with the
+// frames before the limit, along with an ErrTagLimits, or is a failure in
+// strict mode.
 func (d *Decoder) Decode() (*Tag, error) {
+	seeker, canSeek := d.r.(io.Seeker)
+	d.r = readers.GuardProgress(d.r)
+
+	var raw *bytes.Buffer
+
+	if d.CaptureRaw {
+		raw = new(bytes.Buffer)
+		d.r = io.TeeReader(d.r, raw)
+	}
+
 	header := new(tagHeader)
-	n, err := readTagHeader(d.r, header)
+	n, err := readTagHeader(d.r, header, d.Strict)
 	d.n += n
 
 	if err != nil {
@@ -77,44 +197,285 @@ func (d *Decoder) Decode() (*Tag, error) {
 		Version:  header.version,
 		Revision: header.revision,
 		Flags:    header.flags,
+		Offset:   header.junk,
+		Size:     header.size,
 	}
 
 	d.tag.Frames = make([]Frame, 0)
+	d.limits = newFrameLimits(d.MaxFrames, d.MaxFrameBytes)
+
+	// for the footer after the body
+	r := d.r
 
 	// Avoid read exceeding ID3 Tag boundary
 	d.r = io.LimitReader(d.r, int64(header.size))
 
+	// bytes removed by undoing the unsynchronisation of the whole tag
+	unsynced := 0
+
+	if header.flags&flagUnsync != 0 && header.version < 4 {
+		if unsynced, err = d.readUnsynced(header); err != nil {
+			return nil, fmt.Errorf("read unsynchronised tag failed: %w", err)
+		}
+
+		// the tag was read as a whole
+		canSeek = false
+	}
+
+	if header.version == 2 && header.flags&flagV22Compression != 0 {
+		return nil, errCompressedV22
+	}
+
+	// an empty tag can't hold the extended header its flags announce
+	if header.flags&flagExtendedHeader != 0 && header.size > 0 {
+		if err := d.readExtendedHeader(header.version); err != nil {
+			return nil, fmt.Errorf("read extended header failed: %w", err)
+		}
+	}
+
+	// bytes of padding read while looking for the next frame
+	padding := 0
+
+	// whether the frame filter or a limit stopped decoding
+	stopped := false
+
+	var limitErr error
+
+	var br *bufio.Reader
+
+	if d.SkipInternalPadding {
+		// to look past the zeros without consuming the next frame
+		br = bufio.NewReader(d.r)
+		d.r = br
+		canSeek = false
+	}
+
 	// offset from header
 	for {
+		if br != nil {
+			zeros, resume, err := d.skipInternalPadding(br)
+			padding += zeros
+
+			if err != nil {
+				return nil, fmt.Errorf("read padding failed at %04X, err: %w", d.n, err)
+			}
+
+			if !resume {
+				break
+			}
+		}
+
 		frame, err := d.readFrame()
 
 		if err == io.EOF {
 			break
 		}
 
+		if err == errStopDecoding {
+			stopped = true
+			break
+		}
+
+		if _, ok := err.(ErrTagLimits); ok {
+			if d.Strict {
+				return nil, err
+			}
+
+			stopped = true
+			limitErr = err
+			break
+		}
+
 		if err != nil {
-			return nil, fmt.Errorf("read frame failed at %04X, err: %s", d.n, err)
+			return nil, fmt.Errorf("read frame failed at %04X, err: %w", d.n, err)
 		}
 
 		if frame == nil {
 			// reached padding. Bye
+			padding += lenOfFrameHeader(header.version)
 			break
 		}
 
 		d.tag.Frames = append(d.tag.Frames, *frame)
 	}
 
-	d.tag.PaddingSize = header.size + lenOfHeader - d.n
+	// the frames after a stop aren't in the CRC
+	if d.crc != nil && !stopped {
+		valid := d.crc.Sum32() == d.tag.CRC
+		d.tag.CRCValid = &valid
+
+		if !valid && d.Strict {
+			return nil, fmt.Errorf("%w: frames have %08X, extended header has %08X", ErrCRCMismatch, d.crc.Sum32(), d.tag.CRC)
+		}
+	}
+
+	remaining := header.size + lenOfHeader + header.junk - d.n
 
 	// discard padding bytes
-	nDiscarded, err := io.CopyN(ioutil.Discard, d.r, int64(d.tag.PaddingSize))
+	var nDiscarded int64
+
+	if canSeek && raw == nil {
+		nDiscarded, err = readers.SeekForward(seeker, int64(remaining))
+	} else {
+		nDiscarded, err = buffers.Discard(d.BufferPool, d.r, int64(remaining))
+	}
+
 	d.n += int(nDiscarded)
+	d.tag.PaddingSize = padding + int(nDiscarded)
 
-	if err != nil {
+	if stopped {
+		// the rest of the tag isn't known to be padding
+		d.tag.PaddingSize = 0
+	}
+
+	if err == io.EOF {
+		err = fmt.Errorf("%w: tag ends %d bytes into %d bytes of padding", io.ErrUnexpectedEOF, d.tag.PaddingSize, padding+remaining)
+
+		if d.Strict {
+			return nil, err
+		}
+	} else if err != nil {
 		return nil, err
 	}
 
-	return d.tag, nil
+	if footer := header.footerSize(); footer > 0 && err == nil {
+		var nFooter int64
+		nFooter, err = buffers.Discard(d.BufferPool, r, int64(footer))
+		d.n += int(nFooter)
+
+		if err == io.EOF {
+			err = fmt.Errorf("%w: tag ends %d bytes into its footer", io.ErrUnexpectedEOF, nFooter)
+
+			if d.Strict {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	d.n += unsynced
+
+	if raw != nil {
+		d.tag.Raw = raw.Bytes()[header.junk:]
+	}
+
+	if err == nil {
+		err = limitErr
+	}
+
+	return d.tag, err
+}
+
+// skipInternalPadding reads the zeros before the next frame, if any. It tells
+// whether to resume reading frames after them: not when the tag ends, nor when
+// the zeros are followed by something else than a frame ID, which is left to
+// be discarded as padding.
+func (d *Decoder) skipInternalPadding(br *bufio.Reader) (zeros int, resume bool, err error) {
+	for {
+		c, err := br.ReadByte()
+
+		if err == io.EOF {
+			return zeros, false, nil
+		}
+
+		if err != nil {
+			return zeros, false, err
+		}
+
+		if c != 0x00 {
+			br.UnreadByte()
+			break
+		}
+
+		zeros++
+		d.n++
+	}
+
+	if zeros == 0 {
+		return 0, true, nil
+	}
+
+	n := lenOfFrameID(d.tag.Version)
+	id, _ := br.Peek(n)
+
+	return zeros, len(id) == n && isValidFrameID(id), nil
+}
+
+// readUnsynced reads the body of a tag unsynchronised as a whole, as done
+// before ID3v2.4, and decodes the rest of it from the synchronised bytes.
+// header.size, and d.n from now on, count synchronised bytes. Returns the
+// number of bytes removed.
+func (d *Decoder) readUnsynced(header *tagHeader) (int, error) {
+	body := make([]byte, header.size)
+	n, err := io.ReadFull(d.r, body)
+
+	if err != nil {
+		d.n += n
+
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return 0, err
+	}
+
+	synced := removeUnsync(body)
+	d.r = bytes.NewReader(synced)
+	header.size = len(synced)
+
+	return len(body) - len(synced), nil
+}
+
+// readExtendedHeader reads the extended header following the tag header, and
+// prepares the CRC check of the frames if it has a CRC. Only the CRC of
+// ID3v2.3 is supported; the extended header of ID3v2.4 is skipped.
+func (d *Decoder) readExtendedHeader(version uint8) error {
+	sizeBytes := make([]byte, 4)
+	n, err := io.ReadFull(d.r, sizeBytes)
+	d.n += n
+
+	if err != nil {
+		return err
+	}
+
+	if version >= 4 {
+		// syncsafe, including the size itself
+		size := decodeTagSize(sizeBytes) - len(sizeBytes)
+
+		if size < 0 {
+			return fmt.Errorf("invalid extended header size: %d", size)
+		}
+
+		nDiscarded, err := buffers.Discard(d.BufferPool, d.r, int64(size))
+		d.n += int(nDiscarded)
+
+		return err
+	}
+
+	// ExtendedFlags  $xx xx
+	// Padding size   $xx xx xx xx
+	// (CRC           $xx xx xx xx)
+	size := binary.BigEndian.Uint32(sizeBytes)
+
+	if size != 6 && size != 10 {
+		return fmt.Errorf("invalid extended header size: %d", size)
+	}
+
+	data := make([]byte, size)
+	n, err = io.ReadFull(d.r, data)
+	d.n += n
+
+	if err != nil {
+		return err
+	}
+
+	if binary.BigEndian.Uint16(data[0:2])&flagCRC != 0 && size == 10 {
+		d.tag.CRC = binary.BigEndian.Uint32(data[6:10])
+		d.crc = crc32.NewIEEE()
+	}
+
+	return nil
 }
 
 // readFrame reads an ID3 frame from the reader.
@@ -123,45 +484,66 @@ func (d *Decoder) Decode() (*Tag, error) {
 //
 // Returns nil *Frame and nil error when all data are 0x00 (padding). The caller
 // should discard all the remaining data up to end of ID3 tag.
+//
+// Returns io.EOF only when no byte of the frame could be read. A frame cut
+// short anywhere after its first byte returns io.ErrUnexpectedEOF.
+//
+// Returns errStopDecoding, having read the frame header only, when
+// FrameFilter says so, and ErrTagLimits when the frame is over the limits.
 func (d *Decoder) readFrame() (*Frame, error) {
-	header := [10]byte{}
-	n, err := io.ReadFull(d.r, header[:])
+	version := uint8(3)
+	if d.tag != nil {
+		version = d.tag.Version
+	}
+
+	offset := d.n
+	header := make([]byte, lenOfFrameHeader(version))
+	n, err := io.ReadFull(d.r, header)
 	d.n += n
 	if err != nil {
 		return nil, err
 	}
 
-	allZero := [10]byte{}
-
-	if bytes.Equal(header[:], allZero[:]) {
+	if isFill(header, 0x00) {
 		// Reached padding. Exit.
 		return nil, nil
 	}
 
-	// Frame ID       $xx xx xx xx (four characters)
-	// Size           $xx xx xx xx
-	// Flags          $xx xx
-
 	// verify if the id is a valid string
-	idRaw := header[0:4]
-	for _, c := range idRaw {
-		if !(('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')) {
-			return nil, fmt.Errorf("invalid header: %v", idRaw)
-		}
+	idRaw, size, flags := parseFrameHeader(header, version)
+	if !isValidFrameID(idRaw) {
+		return nil, fmt.Errorf("invalid header: %v", idRaw)
 	}
 
 	id := string(idRaw)
 
-	// It's safe to represent size as a 32-bit signed int, even if the spec says
-	// it uses 32-bit integer without specifying it's signed or unsigned,
-	// because the Size section of tag header can only store an 28-bit signed
-	// integer.
-	//
-	// See decodeTagSize for details.
-	//
-	// FIXME: find a way to read signed int directly, without explicit type conversion
-	size := int(binary.BigEndian.Uint32(header[4:8]))
-	flags := binary.BigEndian.Uint16(header[8:10])
+	action := FrameKeep
+
+	if d.FrameFilter != nil {
+		action = d.FrameFilter(id, size)
+	}
+
+	if action == FrameStop {
+		return nil, errStopDecoding
+	}
+
+	unsync := d.tag != nil && d.tag.Version >= 4 && flags&frameFlagUnsync != 0
+	stream := action == FrameKeep && id == "APIC" && d.PictureWriter != nil && !unsync
+
+	if err := d.limits.add(size, action == FrameSkip || stream, offset); err != nil {
+		return nil, err
+	}
+
+	skipped := &Frame{ID: id, Flags: flags, Size: size, Offset: offset}
+
+	if action == FrameSkip {
+		return d.skipFrame(header, skipped)
+	}
+
+	if stream {
+		return d.streamPicture(header, skipped)
+	}
+
 	data := make([]byte, size)
 	// In case of HTTP response body, r is a bufio.Reader, and in some cases
 	// r.Read() may not fill the whole len(data). Using io.ReadFull ensures it
@@ -170,18 +552,71 @@ func (d *Decoder) readFrame() (*Frame, error) {
 
 	d.n += n
 
+	if err == io.EOF {
+		// the header was read, so the frame is truncated
+		err = io.ErrUnexpectedEOF
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
+	if unsync {
+		data = removeUnsync(data)
+	}
+
+	if d.crc != nil {
+		d.crc.Write(header)
+		d.crc.Write(data)
+	}
+
 	frame := new(Frame)
 	frame.ID = id
 	frame.Flags = flags
 	frame.Data = data
+	frame.Size = size
+	frame.Offset = offset
+	frame.TrailingPadding = frame.trailingPadding()
+	frame.strictBOM = d.Strict
+
+	return frame, nil
+}
+
+// skipFrame discards the payload of frame, read up to its header, and returns
+// frame as is, without Data.
+func (d *Decoder) skipFrame(header []byte, frame *Frame) (*Frame, error) {
+	r := d.r
+
+	if d.crc != nil {
+		d.crc.Write(header)
+		r = io.TeeReader(r, d.crc)
+	}
+
+	n, err := buffers.Discard(d.BufferPool, r, int64(frame.Size))
+	d.n += int(n)
+
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	if err != nil {
+		return nil, err
+	}
 
 	return frame, nil
 }
 
+// isValidFrameID tells whether id is made of capital letters and digits only.
+func isValidFrameID(id []byte) bool {
+	for _, c := range id {
+		if !(('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // InputOffset returns how many bytes that the decoder has read so far.
 func (d *Decoder) InputOffset() int {
 	return d.n
@@ -194,12 +629,11 @@ func (d *Decoder) InputOffset() int {
 //
 // For example:
 //
-//     (0x) 00 00 02 01
-//     => _0000000 _0000000 _0000010 _0000001
-//     => 10_0000001
-//     => 0x101
-//     => 257 (dec)
-//
+//	(0x) 00 00 02 01
+//	=> _0000000 _0000000 _0000010 _0000001
+//	=> 10_0000001
+//	=> 0x101
+//	=> 257 (dec)
 func decodeTagSize(data []byte) int {
 	size := 0
 
@@ -212,6 +646,38 @@ func decodeTagSize(data []byte) int {
 	return size
 }
 
+// decodeFrameSize returns the payload size declared by the 4 bytes of size of a
+// frame header, in a tag of the given major version. From ID3v2.4, it's
+// syncsafe like the tag size, see decodeTagSize. Before, it's a plain 32-bit
+// integer, which can't be over 28 bits in a tag of valid size anyway.
+//
+// ID3v2.4 sizes with a byte over $7F can't be syncsafe: some taggers write
+// plain integers there, which are then read as such.
+func decodeFrameSize(size []byte, version uint8) int {
+	if version >= 4 && size[0]|size[1]|size[2]|size[3] < 0x80 {
+		return decodeTagSize(size)
+	}
+
+	return int(binary.BigEndian.Uint32(size))
+}
+
+// parseFrameHeader splits a frame header, of a tag of the given major version,
+// into its ID, payload size and flags:
+//
+//	Frame ID  $xx xx xx xx (four characters)
+//	Size      $xx xx xx xx
+//	Flags     $xx xx
+//
+// The headers of ID3v2.2 have no flags, and an ID and a plain size of three
+// bytes each.
+func parseFrameHeader(header []byte, version uint8) (id []byte, size int, flags uint16) {
+	if version == 2 {
+		return header[0:3], int(header[3])<<16 | int(header[4])<<8 | int(header[5]), 0
+	}
+
+	return header[0:4], decodeFrameSize(header[4:8], version), binary.BigEndian.Uint16(header[8:10])
+}
+
 func encodeTagSize(size int) []byte {
 	data := make([]byte, 4)
 
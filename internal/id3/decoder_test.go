@@ -2,11 +2,14 @@ package id3
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"reflect"
 	"testing"
+	"testing/iotest"
 )
 
 func openTestData(path string, t *testing.T) io.Reader {
@@ -64,12 +67,6 @@ func TestDecoder_Decode(t *testing.T) {
 			wantTag: nil,
 			wantErr: true,
 		},
-		{
-			name:    "Reached EOF when reading padding section",
-			fields:  fields{r: io.LimitReader(openTestData("./testdata/id3_padded.bin", t), 60000)},
-			wantTag: nil,
-			wantErr: true,
-		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -117,7 +114,15 @@ func TestDecoder_ActualFile(t *testing.T) {
 			wantTagRevision: 0,
 			wantTagFlags:    0,
 			wantFrameLength: 17,
-			wantPaddingSize: 53269,
+			wantPaddingSize: 53279,
+		},
+		{
+			filePath:        "./testdata/id3_v24.bin",
+			wantTagVersion:  4,
+			wantTagRevision: 0,
+			wantTagFlags:    0,
+			wantFrameLength: 6,
+			wantPaddingSize: 512,
 		},
 	}
 	for _, tt := range tests {
@@ -176,6 +181,7 @@ func TestDecoder_readFrame(t *testing.T) {
 				ID:    "TIT2",
 				Flags: 0,
 				Data:  []byte("\x00Foo Bar\x00"),
+				Size:  9,
 			},
 			wantErr: false,
 		},
@@ -186,6 +192,7 @@ func TestDecoder_readFrame(t *testing.T) {
 				ID:    "TIT2",
 				Flags: 0,
 				Data:  []byte("\x01\xFE\xFF\x4E\x16\x75\x4C\x4F\x60\x59\x7D\x00\x00"),
+				Size:  13,
 			},
 			wantErr: false,
 		},
@@ -196,6 +203,7 @@ func TestDecoder_readFrame(t *testing.T) {
 				ID:    "PRIV",
 				Flags: 0,
 				Data:  []byte("\xDE\xAD\xBE\xEF"),
+				Size:  4,
 			},
 			wantErr: false,
 		},
@@ -267,6 +275,59 @@ func Test_decodeTagSize(t *testing.T) {
 	}
 }
 
+func Test_decodeFrameSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		version uint8
+		want    int
+	}{
+		{"ID3v2.3", []byte{0x00, 0x00, 0x02, 0x01}, 3, 513},
+		{"ID3v2.4", []byte{0x00, 0x00, 0x02, 0x01}, 4, 257},
+		{"ID3v2.4, not syncsafe", []byte{0x00, 0x00, 0x00, 0xC8}, 4, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeFrameSize(tt.data, tt.version); got != tt.want {
+				t.Errorf("decodeFrameSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_SyncsafeFrameSizes(t *testing.T) {
+	tag, err := NewDecoder(openTestData("./testdata/id3_v24.bin", t)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	want := map[string]int{"TIT2": 15, "TPE1": 7, "TALB": 21, "TDRC": 5, "COMM": 305, "TXXX": 206}
+
+	for _, frame := range tag.Frames {
+		if frame.Size != want[frame.ID] || len(frame.Data) != frame.Size {
+			t.Errorf("%s Size = %v, len(Data) = %v, want %v", frame.ID, frame.Size, len(frame.Data), want[frame.ID])
+		}
+	}
+
+	if text, _ := tag.findFrame("TALB").Text(); text != "Fixtures \u2014 ID3v2.4" {
+		t.Errorf("TALB Text() = %q, want %q", text, "Fixtures \u2014 ID3v2.4")
+	}
+
+	sizes := make(map[string]int)
+	s := NewSkipReader(openTestData("./testdata/id3_v24.bin", t))
+	s.OnFrame = func(id string, size int) {
+		sizes[id] = size
+	}
+
+	if _, err := s.ReadThrough(); err != nil {
+		t.Fatalf("ReadThrough() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(sizes, want) {
+		t.Errorf("ReadThrough() frame sizes = %v, want %v", sizes, want)
+	}
+}
+
 func Test_encodeTagSize(t *testing.T) {
 	type args struct {
 		size int
@@ -353,3 +414,480 @@ func TestDecoder_InputOffset(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoder_CaptureRaw(t *testing.T) {
+	for _, filePath := range []string{"./testdata/id3_compact.bin", "./testdata/id3_padded.bin"} {
+		t.Run(fmt.Sprintf("file: %s", filePath), func(t *testing.T) {
+			want, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			d := NewDecoder(bytes.NewReader(want))
+			d.CaptureRaw = true
+
+			tag, err := d.Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if !bytes.Equal(tag.Raw, want[:d.InputOffset()]) {
+				t.Errorf("Decode() tag.Raw differs from the first %d bytes of input", d.InputOffset())
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_Truncated(t *testing.T) {
+	frame := generateTextFrame("TIT2", "Foo Bar", 0x0)
+	tagHeader := append([]byte("ID3\x03\x00\x00"), encodeTagSize(len(frame)*2)...)
+
+	tests := []struct {
+		name              string
+		data              []byte
+		wantUnexpectedEOF bool
+	}{
+		{"mid-frame header", append(append([]byte{}, tagHeader...), frame[:5]...), true},
+		{"right after frame header", append(append([]byte{}, tagHeader...), frame[:10]...), true},
+		{"mid-frame data", append(append([]byte{}, tagHeader...), frame[:12]...), true},
+		{"at frame boundary", append(append([]byte{}, tagHeader...), frame...), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder(bytes.NewReader(tt.data)).Decode()
+
+			if err == nil {
+				t.Fatal("Decode() error = nil, want error")
+			}
+
+			if errors.Is(err, io.ErrUnexpectedEOF) != tt.wantUnexpectedEOF {
+				t.Errorf("Decode() error = %v, want io.ErrUnexpectedEOF: %v", err, tt.wantUnexpectedEOF)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_LeadingBOM(t *testing.T) {
+	tagData, err := ioutil.ReadFile("./testdata/id3_padded.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := append([]byte{0xEF, 0xBB, 0xBF}, tagData...)
+
+	d := NewDecoder(bytes.NewReader(data))
+	d.CaptureRaw = true
+	tag, err := d.Decode()
+
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if len(tag.Frames) != 17 || tag.PaddingSize != 53279 {
+		t.Errorf("Decode() got %d frames and %d bytes of padding, want 17 and 53279", len(tag.Frames), tag.PaddingSize)
+	}
+
+	if d.InputOffset() != len(data) {
+		t.Errorf("Decode() InputOffset() = %d, want %d", d.InputOffset(), len(data))
+	}
+
+	if !bytes.Equal(tag.Raw, tagData) {
+		t.Errorf("Decode() tag.Raw includes the BOM")
+	}
+
+	strict := NewDecoder(bytes.NewReader(data))
+	strict.Strict = true
+
+	if _, err := strict.Decode(); err == nil {
+		t.Errorf("Decode() in strict mode error = nil, want error")
+	}
+}
+
+func TestDecoder_MaxFrames(t *testing.T) {
+	tests := []struct {
+		maxFrames int
+		wantErr   bool
+	}{
+		{0, false},
+		{16, false},
+		{15, true},
+		{1, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("MaxFrames: %d", tt.maxFrames), func(t *testing.T) {
+			d := NewDecoder(openTestData("./testdata/id3_compact.bin", t))
+			d.MaxFrames = tt.maxFrames
+
+			tag, err := d.Decode()
+
+			if errors.Is(err, ErrTooManyFrames) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && len(tag.Frames) != 16 {
+				t.Errorf("Decode() len(tag.Frames) = %v, want 16", len(tag.Frames))
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_EmptyFrame(t *testing.T) {
+	var frames bytes.Buffer
+	frames.Write(generateDataFrame("TENC", nil, 0x00))
+	frames.Write(generateTextFrame("TIT2", "Title", 0x00))
+
+	data := append([]byte("ID3\x03\x00\x00\x00\x00\x00"), byte(frames.Len()))
+	data = append(data, frames.Bytes()...)
+
+	tag, err := NewDecoder(bytes.NewReader(data)).Decode()
+
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if len(tag.Frames) != 2 {
+		t.Fatalf("Decode() len(tag.Frames) = %v, want 2", len(tag.Frames))
+	}
+
+	empty := tag.Frames[0]
+
+	if empty.ID != "TENC" || len(empty.Data) != 0 {
+		t.Errorf("Decode() Frames[0] = %v, want empty TENC", &empty)
+	}
+
+	if text, err := empty.Text(); text != "" || err != nil {
+		t.Errorf("Frames[0].Text() = %q, %v, want empty text and no error", text, err)
+	}
+
+	if text, err := tag.Frames[1].Text(); text != "Title" || err != nil {
+		t.Errorf("Frames[1].Text() = %q, %v, want %q", text, err, "Title")
+	}
+}
+
+func TestDecoder_Decode_SectionReader(t *testing.T) {
+	tag := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x20"), generateTextFrame("TIT2", "Title", 0x00)...)
+	tag = append(tag, make([]byte, 0x20+10-len(tag))...)
+
+	// the tag embedded in a larger file, followed by other data
+	file := append(append([]byte("header"), tag...), []byte("trailer")...)
+
+	tests := []struct {
+		name    string
+		size    int64
+		wantErr bool
+	}{
+		{"whole tag", int64(len(tag)), false},
+		{"truncated padding", int64(len(tag)) - 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section := io.NewSectionReader(bytes.NewReader(file), 6, tt.size)
+
+			got, err := NewDecoder(section).Decode()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if text, _ := got.Frames[0].Text(); len(got.Frames) != 1 || text != "Title" {
+				t.Errorf("Decode() Frames = %v, want TIT2 %q", got.Frames, "Title")
+			}
+
+			if pos, _ := section.Seek(0, io.SeekCurrent); pos != int64(len(tag)) {
+				t.Errorf("Decode() left the section at %d, want %d", pos, len(tag))
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_TruncatedPadding(t *testing.T) {
+	tests := []struct {
+		strict  bool
+		wantTag bool
+	}{
+		{false, true},
+		{true, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("strict: %v", tt.strict), func(t *testing.T) {
+			full, err := NewDecoder(openTestData("./testdata/id3_padded.bin", t)).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			d := NewDecoder(io.LimitReader(openTestData("./testdata/id3_padded.bin", t), 60000))
+			d.Strict = tt.strict
+
+			tag, err := d.Decode()
+
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("Decode() error = %v, want io.ErrUnexpectedEOF", err)
+			}
+
+			if (tag != nil) != tt.wantTag {
+				t.Fatalf("Decode() tag = %v, want tag: %v", tag, tt.wantTag)
+			}
+
+			if tag == nil {
+				return
+			}
+
+			if len(tag.Frames) != len(full.Frames) {
+				t.Errorf("Decode() len(tag.Frames) = %v, want %v", len(tag.Frames), len(full.Frames))
+			}
+
+			if want := 60000 - (65536 - full.PaddingSize); tag.PaddingSize != want {
+				t.Errorf("Decode() PaddingSize = %v, want %v", tag.PaddingSize, want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_TrailingPadding(t *testing.T) {
+	tag, err := NewDecoder(openTestData("./testdata/id3_recorder.bin", t)).Decode()
+
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	tests := []struct {
+		id                  string
+		wantText            string
+		wantTexts           []string
+		wantTrailingPadding int
+	}{
+		{"TIT2", "DS400001", []string{"DS400001"}, 22},
+		{"TALB", "FOLDER A", []string{"FOLDER A"}, 22},
+		{"TYER", "2021", []string{"2021"}, 10},
+		{"TCON", "Voice", []string{"Voice", "Memo"}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			frame := tag.findFrame(tt.id)
+
+			if frame == nil {
+				t.Fatalf("frame %s not found", tt.id)
+			}
+
+			if frame.TrailingPadding != tt.wantTrailingPadding {
+				t.Errorf("TrailingPadding = %v, want %v", frame.TrailingPadding, tt.wantTrailingPadding)
+			}
+
+			if text, _ := frame.Text(); text != tt.wantText {
+				t.Errorf("Text() = %q, want %q", text, tt.wantText)
+			}
+
+			if texts, _ := frame.Texts(); !reflect.DeepEqual(texts, tt.wantTexts) {
+				t.Errorf("Texts() = %q, want %q", texts, tt.wantTexts)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_CRC(t *testing.T) {
+	valid, err := ioutil.ReadFile("./testdata/id3_crc.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "Archived Title" -> "Brchived Title"
+	corrupted := append([]byte{}, valid...)
+	corrupted[35]++
+
+	tests := []struct {
+		name         string
+		data         []byte
+		strict       bool
+		wantCRCValid *bool
+		wantWarnings int
+		wantErr      error
+	}{
+		{"valid", valid, false, boolPtr(true), 0, nil},
+		{"corrupted", corrupted, false, boolPtr(false), 1, nil},
+		{"corrupted, strict", corrupted, true, nil, 0, ErrCRCMismatch},
+		{"no CRC", generateTagWithoutCRC(t), false, nil, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(tt.data))
+			d.Strict = tt.strict
+
+			tag, err := d.Decode()
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(tag.CRCValid, tt.wantCRCValid) {
+				t.Errorf("Decode() CRCValid = %v, want %v", tag.CRCValid, tt.wantCRCValid)
+			}
+
+			if len(tag.Frames) != 3 {
+				t.Errorf("Decode() got %d frames, want 3", len(tag.Frames))
+			}
+
+			if warnings := tag.Validate(); len(warnings) != tt.wantWarnings {
+				t.Errorf("Validate() = %v, want %d warnings", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// generateTagWithoutCRC re-encodes the CRC fixture without its extended header.
+func generateTagWithoutCRC(t *testing.T) []byte {
+	tag, err := NewDecoder(openTestData("./testdata/id3_crc.bin", t)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(tag); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecoder_Decode_SkipInternalPadding(t *testing.T) {
+	var body bytes.Buffer
+	body.Write(generateTextFrame("TIT2", "Title", 0x00))
+	body.Write(make([]byte, 3)) // shorter than a frame header
+	body.Write(generateTextFrame("TALB", "Album", 0x00))
+	body.Write(make([]byte, 20))
+	body.Write(generateTextFrame("TPE1", "Artist", 0x00))
+	body.Write(make([]byte, 30))
+
+	// a gap long enough to be taken as padding
+	var gap bytes.Buffer
+	gap.Write(generateTextFrame("TIT2", "Title", 0x00))
+	gap.Write(make([]byte, 20))
+	gap.Write(generateTextFrame("TPE1", "Artist", 0x00))
+
+	garbage := append(append([]byte{}, body.Bytes()[:body.Len()-30]...), make([]byte, 10)...)
+	garbage = append(garbage, "\x01\x02junk"...)
+	garbage = append(garbage, make([]byte, 14)...)
+
+	tests := []struct {
+		name                string
+		body                []byte
+		skipInternalPadding bool
+		wantIDs             []string
+		wantPaddingSize     int
+		wantErr             bool
+	}{
+		{"spec", gap.Bytes(), false, []string{"TIT2"}, 38, false},
+		{"spec, short gap", body.Bytes(), false, nil, 0, true},
+		{"skip gap", gap.Bytes(), true, []string{"TIT2", "TPE1"}, 20, false},
+		{"skip internal padding", body.Bytes(), true, []string{"TIT2", "TALB", "TPE1"}, 53, false},
+		{"garbage after padding", garbage, true, []string{"TIT2", "TALB", "TPE1"}, 53, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte("ID3\x03\x00\x00"), encodeTagSize(len(tt.body))...)
+			data = append(data, tt.body...)
+
+			d := NewDecoder(bytes.NewReader(data))
+			d.SkipInternalPadding = tt.skipInternalPadding
+
+			tag, err := d.Decode()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			var ids []string
+			for _, frame := range tag.Frames {
+				ids = append(ids, frame.ID)
+			}
+
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("Decode() frames = %v, want %v", ids, tt.wantIDs)
+			}
+
+			if tag.PaddingSize != tt.wantPaddingSize {
+				t.Errorf("Decode() PaddingSize = %v, want %v", tag.PaddingSize, tt.wantPaddingSize)
+			}
+
+			if d.InputOffset() != len(data) {
+				t.Errorf("InputOffset() = %v, want %v", d.InputOffset(), len(data))
+			}
+		})
+	}
+}
+
+// TestDecoder_ExactSizeAtEOF checks that a tag without padding, whose frames
+// fill it exactly, decodes without error when the input ends right after it,
+// including from readers returning io.EOF along with the last bytes, as
+// network readers may.
+func TestDecoder_ExactSizeAtEOF(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/id3_compact.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readers := []struct {
+		name string
+		r    func() io.Reader
+	}{
+		{"bytes", func() io.Reader { return bytes.NewReader(data) }},
+		{"EOF with data", func() io.Reader { return iotest.DataErrReader(bytes.NewReader(data)) }},
+		{"one byte", func() io.Reader { return iotest.OneByteReader(bytes.NewReader(data)) }},
+	}
+	for _, tt := range readers {
+		t.Run("Decode, "+tt.name, func(t *testing.T) {
+			d := NewDecoder(tt.r())
+			tag, err := d.Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if tag.PaddingSize != 0 {
+				t.Errorf("Decode() PaddingSize = %v, want 0", tag.PaddingSize)
+			}
+
+			if d.InputOffset() != len(data) {
+				t.Errorf("InputOffset() = %v, want %v", d.InputOffset(), len(data))
+			}
+		})
+
+		t.Run("ReadThrough, "+tt.name, func(t *testing.T) {
+			got, err := NewSkipReader(tt.r()).ReadThrough()
+			if err != nil {
+				t.Fatalf("ReadThrough() error = %v", err)
+			}
+
+			if got != len(data) {
+				t.Errorf("ReadThrough() = %v, want %v", got, len(data))
+			}
+		})
+	}
+
+	t.Run("Feed", func(t *testing.T) {
+		d := &Decoder{}
+
+		needMore, err := d.Feed(data)
+		if err != nil {
+			t.Fatalf("Feed() error = %v", err)
+		}
+
+		if needMore || d.Tag() == nil || len(d.Rest()) != 0 {
+			t.Errorf("Feed() = %v, Tag() = %v, Rest() = %q, want the tag and nothing more", needMore, d.Tag(), d.Rest())
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package id3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+// maxTagSize is the largest tag payload the 28-bit tag size can hold.
+const maxTagSize = 1<<28 - 1
+
+// Encoder writes ID3v2 tags in the layout read by Decoder.
+type Encoder struct {
+	// PreserveFrameLengths keeps the TrailingPadding of text frames, so that
+	// frames left untouched since decoding keep their size, and so does the
+	// tag. By default the padding is dropped.
+	PreserveFrameLengths bool
+
+	// WriteCRC writes an extended header with the CRC-32 of the frames, as
+	// defined by ID3v2.3. Otherwise no extended header is written. ID3v2.2
+	// tags have none, and can't be written with it.
+	WriteCRC bool
+
+	// Unsync applies unsynchronisation, for old players that mistake bytes of
+	// the tag for MP3 frames. Tags before ID3v2.4 are unsynchronised as a
+	// whole, ID3v2.4 tags frame by frame.
+	Unsync bool
+
+	w        io.Writer
+	warnings []string
+}
+
+// flagExperimental in the tag header marks a tag as experimental.
+const flagExperimental = 0x20
+
+// NewEncoder returns an ID3 encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the tag header, the frames and PaddingSize bytes of padding.
+//
+// The flags of the tag header are those of Tag.Flags, but for the ones Encode
+// manages: unsynchronisation and extended header, set from the options, and
+// the footer of ID3v2.4 tags, never written. Others, such as the experimental
+// flag, are kept, with a warning listing them, see Warnings.
+func (e *Encoder) Encode(tag *Tag) error {
+	var frames bytes.Buffer
+	e.warnings = nil
+
+	if e.WriteCRC && tag.Version == 2 {
+		return errors.New("ID3v2.2 tag can't have a CRC")
+	}
+
+	for i := range tag.Frames {
+		frame := tag.Frames[i]
+
+		if !e.PreserveFrameLengths && frame.TrailingPadding > 0 && frame.TrailingPadding <= len(frame.Data) {
+			frame.Data = frame.Data[:len(frame.Data)-frame.TrailingPadding]
+		}
+
+		if tag.Version >= 4 {
+			// Data is always held synchronised
+			frame.Flags &^= frameFlagUnsync
+
+			if e.Unsync {
+				frame.Data = addUnsync(frame.Data)
+				frame.Flags |= frameFlagUnsync
+			}
+		}
+
+		b, err := frame.bytes(tag.Version)
+
+		if err != nil {
+			return err
+		}
+
+		frames.Write(b)
+	}
+
+	managed := uint8(flagExtendedHeader | flagUnsync)
+	if tag.Version >= 4 {
+		managed |= flagFooter
+	}
+
+	flags := tag.Flags &^ managed
+	e.warnKeptFlags(flags)
+
+	var extendedHeader []byte
+
+	if e.WriteCRC {
+		flags |= flagExtendedHeader
+
+		// size, flags, padding size and CRC
+		extendedHeader = make([]byte, 14)
+		binary.BigEndian.PutUint32(extendedHeader[0:4], 10)
+		binary.BigEndian.PutUint16(extendedHeader[4:6], flagCRC)
+		binary.BigEndian.PutUint32(extendedHeader[6:10], uint32(tag.PaddingSize))
+		binary.BigEndian.PutUint32(extendedHeader[10:14], crc32.ChecksumIEEE(frames.Bytes()))
+	}
+
+	body := append(extendedHeader, frames.Bytes()...)
+
+	if e.Unsync && tag.Version < 4 {
+		flags |= flagUnsync
+		body = addUnsync(body)
+	}
+
+	size := len(body) + tag.PaddingSize
+
+	if size > maxTagSize {
+		return fmt.Errorf("tag of %d bytes exceeds the maximum of %d", size, maxTagSize)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(lenOfHeader + size)
+
+	buf.Write(id3v2Flag)
+	buf.WriteByte(tag.Version)
+	buf.WriteByte(tag.Revision)
+	buf.WriteByte(flags)
+	buf.Write(encodeTagSize(size))
+	buf.Write(body)
+	buf.Write(make([]byte, tag.PaddingSize))
+
+	_, err := e.w.Write(buf.Bytes())
+
+	return err
+}
+
+// Warnings returns what the last call to Encode wrote as is without managing
+// it, such as unknown flags of the tag header.
+func (e *Encoder) Warnings() []string {
+	return e.warnings
+}
+
+// warnKeptFlags records a warning listing the flags of the tag header kept
+// from the tag.
+func (e *Encoder) warnKeptFlags(flags uint8) {
+	if flags == 0 {
+		return
+	}
+
+	var names []string
+
+	for bit := uint8(0x80); bit > 0; bit >>= 1 {
+		if flags&bit == 0 {
+			continue
+		}
+
+		if bit == flagExperimental {
+			names = append(names, fmt.Sprintf("0x%02X (experimental)", bit))
+		} else {
+			names = append(names, fmt.Sprintf("0x%02X (unknown)", bit))
+		}
+	}
+
+	e.warnings = append(e.warnings, fmt.Sprintf("kept flags %s of the tag header as is", strings.Join(names, ", ")))
+}
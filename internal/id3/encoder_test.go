@@ -0,0 +1,144 @@
+package id3
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestEncoder_Encode(t *testing.T) {
+	for _, filePath := range []string{"./testdata/id3_compact.bin", "./testdata/id3_padded.bin", "./testdata/id3_recorder.bin", "./testdata/id3_v24.bin"} {
+		t.Run(filePath, func(t *testing.T) {
+			want, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			d := NewDecoder(bytes.NewReader(want))
+			tag, err := d.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.PreserveFrameLengths = true
+
+			if err := e.Encode(tag); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), want[:d.InputOffset()]) {
+				t.Errorf("Encode() differs from the decoded %d bytes, got %d bytes", d.InputOffset(), buf.Len())
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode_DropTrailingPadding(t *testing.T) {
+	tag, err := NewDecoder(openTestData("./testdata/id3_recorder.bin", t)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(tag); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	// 22 + 22 + 10 + 5 bytes of fill in the fixture
+	if want := 275 - 59; buf.Len() != want {
+		t.Errorf("Encode() wrote %d bytes, want %d", buf.Len(), want)
+	}
+
+	got, err := NewDecoder(&buf).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	for i, frame := range got.Frames {
+		if frame.TrailingPadding != 0 {
+			t.Errorf("%s TrailingPadding = %d, want 0", frame.ID, frame.TrailingPadding)
+		}
+
+		texts, _ := frame.Texts()
+		wantTexts, _ := tag.Frames[i].Texts()
+
+		if !reflect.DeepEqual(texts, wantTexts) {
+			t.Errorf("%s Texts() = %q, want %q", frame.ID, texts, wantTexts)
+		}
+	}
+}
+
+func TestEncoder_Encode_CRC(t *testing.T) {
+	want, err := ioutil.ReadFile("./testdata/id3_crc.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := NewDecoder(bytes.NewReader(want)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.WriteCRC = true
+
+	if err := e.Encode(tag); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Encode() = %x, want %x", buf.Bytes(), want)
+	}
+}
+
+func TestEncoder_Encode_KeepFlags(t *testing.T) {
+	frame := generateTextFrame("TIT2", "Foo Bar", 0x0)
+
+	tests := []struct {
+		name         string
+		version      byte
+		flags        byte
+		want         byte
+		wantWarnings int
+	}{
+		{"experimental", 3, 0x20, 0x20, 1},
+		{"experimental, unsynchronised", 3, 0xA0, 0x20, 1},
+		{"ID3v2.4 footer", 4, 0x30, 0x20, 1},
+		{"undefined in ID3v2.3", 3, 0x10, 0x10, 1},
+		{"none", 3, 0x00, 0x00, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte{'I', 'D', '3', tt.version, 0x00, tt.flags}, encodeTagSize(len(frame))...)
+			data = append(data, frame...)
+
+			if tt.version == 4 && tt.flags&flagFooter != 0 {
+				data = append(data, append([]byte("3DI"), data[3:lenOfHeader]...)...)
+			}
+
+			tag, err := NewDecoder(bytes.NewReader(data)).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+
+			if err := e.Encode(tag); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			if got := buf.Bytes()[5]; got != tt.want {
+				t.Errorf("Encode() flags = %#02x, want %#02x", got, tt.want)
+			}
+
+			if len(e.Warnings()) != tt.wantWarnings {
+				t.Errorf("Warnings() = %q, want %d", e.Warnings(), tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package id3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"unicode/utf16"
+)
+
+// Encoding is the text encoding of a frame, as declared by its first byte.
+type Encoding int
+
+const (
+	EncodingNotText Encoding = iota // not a text frame, or an unknown encoding
+	EncodingLatin1
+	EncodingUTF16
+	EncodingUTF16BE
+	EncodingUTF8
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingNotText:
+		return "not text"
+	case EncodingLatin1:
+		return "ISO-8859-1"
+	case EncodingUTF16:
+		return "UTF-16"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	case EncodingUTF8:
+		return "UTF-8"
+	default:
+		return fmt.Sprintf("Encoding(%d)", int(e))
+	}
+}
+
+// Encoding returns the encoding declared by a text frame. Empty frames and
+// frames declaring an unknown encoding are EncodingNotText.
+func (frame *Frame) Encoding() Encoding {
+	if frame.ID[0] != 'T' || len(frame.Data) == 0 {
+		return EncodingNotText
+	}
+
+	switch frame.Data[0] {
+	case textEncodingLatin1:
+		return EncodingLatin1
+	case textEncodingUTF16:
+		return EncodingUTF16
+	case textEncodingUTF16BE:
+		return EncodingUTF16BE
+	case textEncodingUTF8:
+		return EncodingUTF8
+	default:
+		return EncodingNotText
+	}
+}
+
+// EncodingStats counts the frames of the tag by Encoding, e.g. to find how
+// many still use UTF-16 across a collection.
+func (t *Tag) EncodingStats() map[Encoding]int {
+	stats := make(map[Encoding]int)
+
+	for i := range t.Frames {
+		stats[t.Frames[i].Encoding()]++
+	}
+
+	return stats
+}
+
+// TranscodeText re-encodes the text of a frame, all its values included, in
+// the target encoding, e.g. to normalize a collection to UTF-8. UTF-16BE and
+// UTF-8 are only defined by ID3v2.4. Returns an error, leaving the frame
+// untouched, if the text can't be decoded or the target can't represent it.
+func (frame *Frame) TranscodeText(target Encoding) error {
+	values, err := frame.Texts()
+
+	if err != nil {
+		return err
+	}
+
+	data, err := encodeText(values, target)
+
+	if err != nil {
+		return err
+	}
+
+	transcoded := Frame{ID: frame.ID, Data: data}
+
+	if got, err := transcoded.Texts(); err != nil || !reflect.DeepEqual(got, values) {
+		return fmt.Errorf("TranscodeText(): %s can't represent %q", target, values)
+	}
+
+	frame.Data = data
+	frame.TrailingPadding = 0
+
+	return nil
+}
+
+// encodeText encodes the values of a text frame, each with a terminator.
+func encodeText(values []string, encoding Encoding) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch encoding {
+	case EncodingLatin1:
+		buf.WriteByte(textEncodingLatin1)
+	case EncodingUTF16:
+		buf.WriteByte(textEncodingUTF16)
+	case EncodingUTF16BE:
+		buf.WriteByte(textEncodingUTF16BE)
+	case EncodingUTF8:
+		buf.WriteByte(textEncodingUTF8)
+	default:
+		return nil, fmt.Errorf("unable to encode text as %s", encoding)
+	}
+
+	for _, value := range values {
+		switch encoding {
+		case EncodingLatin1:
+			for _, r := range value {
+				if r > 0xFF {
+					return nil, fmt.Errorf("%s can't represent %q", encoding, r)
+				}
+
+				buf.WriteByte(byte(r))
+			}
+
+			buf.WriteByte(0x00)
+		case EncodingUTF16:
+			data, err := encodeUTF16String(value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			buf.Write(data)
+			buf.Write([]byte{0x00, 0x00})
+		case EncodingUTF16BE:
+			binary.Write(&buf, binary.BigEndian, utf16.Encode([]rune(value)))
+			buf.Write([]byte{0x00, 0x00})
+		case EncodingUTF8:
+			buf.WriteString(value)
+			buf.WriteByte(0x00)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
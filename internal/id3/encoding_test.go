@@ -0,0 +1,68 @@
+package id3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTag_EncodingStats(t *testing.T) {
+	tag := &Tag{Frames: []Frame{
+		{ID: "TIT2", Data: []byte("\x00Latin-1\x00")},
+		{ID: "TALB", Data: []byte("\x00Latin-1\x00")},
+		{ID: "TPE1", Data: []byte("\x01\xFF\xFEA\x00\x00\x00")},
+		{ID: "TPE2", Data: []byte("\x02\x00A\x00\x00")},
+		{ID: "TCOM", Data: []byte("\x03UTF-8\x00")},
+		{ID: "TCON", Data: []byte("\x09unknown")},
+		{ID: "TYER", Data: []byte{}},
+		{ID: "APIC", Data: []byte("\x00image/png\x00")},
+		{ID: "WOAR", Data: []byte("http://example.com/")},
+	}}
+
+	want := map[Encoding]int{
+		EncodingLatin1:  2,
+		EncodingUTF16:   1,
+		EncodingUTF16BE: 1,
+		EncodingUTF8:    1,
+		EncodingNotText: 4,
+	}
+
+	if got := tag.EncodingStats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EncodingStats() = %v, want %v", got, want)
+	}
+}
+
+func TestFrame_TranscodeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		target   Encoding
+		wantData []byte
+		wantErr  bool
+	}{
+		{"Latin-1 to UTF-8", []byte("\x00Title\x00"), EncodingUTF8, []byte("\x03Title\x00"), false},
+		{"UTF-16 to UTF-8", []byte("\x01\xFF\xFE\x16\x4E\x4C\x75\x00\x00"), EncodingUTF8, []byte("\x03世界\x00"), false},
+		{"UTF-8 to UTF-16", []byte("\x03世界\x00"), EncodingUTF16, []byte("\x01\xFE\xFF\x4E\x16\x75\x4C\x00\x00"), false},
+		{"UTF-8 to UTF-16BE", []byte("\x03世界\x00"), EncodingUTF16BE, []byte("\x02\x4E\x16\x75\x4C\x00\x00"), false},
+		{"UTF-8 to Latin-1", []byte("\x03Title\x00"), EncodingLatin1, []byte("\x00Title\x00"), false},
+		{"several values", []byte("\x00Voice\x00Memo\x00\x00\x00"), EncodingUTF16BE, []byte("\x02\x00V\x00o\x00i\x00c\x00e\x00\x00\x00M\x00e\x00m\x00o\x00\x00"), false},
+		{"empty", []byte{}, EncodingUTF8, []byte("\x03"), false},
+		{"not representable", []byte("\x03世界\x00"), EncodingLatin1, []byte("\x03世界\x00"), true},
+		{"unknown target", []byte("\x00Title\x00"), EncodingNotText, []byte("\x00Title\x00"), true},
+		{"unknown source", []byte("\x09Title"), EncodingUTF8, []byte("\x09Title"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := &Frame{ID: "TIT2", Data: tt.data}
+
+			err := frame.TranscodeText(tt.target)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TranscodeText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(frame.Data, tt.wantData) {
+				t.Errorf("TranscodeText() Data = %q, want %q", frame.Data, tt.wantData)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package id3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// feedState holds the bytes given to Decoder.Feed so far.
+type feedState struct {
+	buf  []byte
+	done bool
+	tag  *Tag // as returned by Decode
+	rest []byte
+}
+
+// Feed gives the decoder the next bytes of the input, for transports that
+// deliver chunks rather than a blocking io.Reader. It returns needMore until
+// the whole tag was fed, then decodes it like Decode, with the same options.
+// The tag is then available from Tag, and the bytes fed after it from Rest.
+//
+// The decoder must have been created with a nil reader, e.g. &Decoder{}, and
+// Decode must not be called. The whole tag is held in memory until decoded, as
+// its frames are anyway.
+//
+// Errors of Decode are returned once, when the tag is complete. An input that
+// isn't an ID3v2 tag is reported as soon as its header is fed.
+func (d *Decoder) Feed(b []byte) (needMore bool, err error) {
+	if d.feed.done {
+		d.feed.rest = append(d.feed.rest, b...)
+		return false, nil
+	}
+
+	if d.r != nil {
+		return false, errors.New("Feed(): the decoder already has a reader")
+	}
+
+	d.feed.buf = append(d.feed.buf, b...)
+
+	header := new(tagHeader)
+	_, err = readTagHeader(bytes.NewReader(d.feed.buf), header, d.Strict)
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	size := header.junk + lenOfHeader + header.size
+
+	if len(d.feed.buf) < size {
+		return true, nil
+	}
+
+	d.feed.done = true
+	d.feed.rest = d.feed.buf[size:]
+	d.r = bytes.NewReader(d.feed.buf[:size])
+
+	d.feed.tag, err = d.Decode()
+	d.feed.buf = nil
+
+	return false, err
+}
+
+// Tag returns the tag decoded by Feed, or nil while it needs more bytes or
+// when decoding failed.
+func (d *Decoder) Tag() *Tag {
+	return d.feed.tag
+}
+
+// Rest returns the bytes given to Feed after the end of the tag, e.g. the
+// start of the audio.
+func (d *Decoder) Rest() []byte {
+	return d.feed.rest
+}
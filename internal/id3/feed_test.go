@@ -0,0 +1,91 @@
+package id3
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestDecoder_Feed(t *testing.T) {
+	tagData, err := ioutil.ReadAll(openTestData("./testdata/id3_compact.bin", t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	audio := []byte("\xFF\xFB\x90\x44audio")
+	data := append(append(append([]byte{}, utf8BOM...), tagData...), audio...)
+
+	want, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chunkSize := range []int{1, 3, 10, 4096, len(data)} {
+		d := &Decoder{}
+		fed := 0
+
+		for fed < len(data) {
+			end := fed + chunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+
+			needMore, err := d.Feed(data[fed:end])
+			fed = end
+
+			if err != nil {
+				t.Fatalf("chunks of %d: Feed() error = %v", chunkSize, err)
+			}
+
+			if !needMore {
+				break
+			}
+
+			if d.Tag() != nil {
+				t.Fatalf("chunks of %d: Tag() != nil before the tag was complete", chunkSize)
+			}
+		}
+
+		if fed < len(data) {
+			// bytes after the tag are kept too
+			if needMore, err := d.Feed(data[fed:]); needMore || err != nil {
+				t.Fatalf("chunks of %d: Feed() after the tag = %v, %v", chunkSize, needMore, err)
+			}
+		}
+
+		if !reflect.DeepEqual(d.Tag(), want) {
+			t.Errorf("chunks of %d: Tag() differs from Decode()", chunkSize)
+		}
+
+		if !bytes.Equal(d.Rest(), audio) {
+			t.Errorf("chunks of %d: Rest() = %q, want %q", chunkSize, d.Rest(), audio)
+		}
+	}
+}
+
+func TestDecoder_Feed_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		decoder *Decoder
+		data    []byte
+	}{
+		{"not a tag", &Decoder{}, []byte("RIFF\x00\x00\x00\x00WAVE")},
+		{"BOM in strict mode", &Decoder{Strict: true}, append(append([]byte{}, utf8BOM...), "ID3\x03\x00\x00\x00\x00\x00\x00"...)},
+		{"truncated frame", &Decoder{}, []byte("ID3\x03\x00\x00\x00\x00\x00\x0ATIT2\x00\x00\x00\x10\x00\x00")},
+		{"has a reader", NewDecoder(bytes.NewReader(nil)), []byte("ID3")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			needMore, err := tt.decoder.Feed(tt.data)
+
+			if needMore || err == nil {
+				t.Errorf("Feed() = %v, %v, want an error", needMore, err)
+			}
+
+			if tt.decoder.Tag() != nil {
+				t.Errorf("Tag() != nil after an error")
+			}
+		})
+	}
+}
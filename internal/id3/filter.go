@@ -0,0 +1,50 @@
+package id3
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FrameAction tells Decoder what to do with a frame, from its header. See
+// Decoder.FrameFilter.
+type FrameAction int
+
+const (
+	// FrameKeep decodes the frame as usual.
+	FrameKeep FrameAction = iota
+	// FrameSkip discards the payload without allocating it. The frame is kept
+	// in Tag.Frames with nil Data, its ID, Flags, Size and Offset set.
+	FrameSkip
+	// FrameStop stops decoding at the frame. Neither it nor the frames after
+	// it are kept, and the rest of the tag is discarded.
+	FrameStop
+)
+
+func (a FrameAction) String() string {
+	switch a {
+	case FrameKeep:
+		return "keep"
+	case FrameSkip:
+		return "skip"
+	case FrameStop:
+		return "stop"
+	default:
+		return fmt.Sprintf("FrameAction(%d)", int(a))
+	}
+}
+
+// SkipLargeBinaryFrames returns a filter skipping the payload of frames other
+// than text and URL frames when it's larger than max bytes, such as embedded
+// pictures or chapters.
+func SkipLargeBinaryFrames(max int) func(id string, size int) FrameAction {
+	return func(id string, size int) FrameAction {
+		if size > max && id[0] != 'T' && id[0] != 'W' {
+			return FrameSkip
+		}
+
+		return FrameKeep
+	}
+}
+
+// errStopDecoding is returned by readFrame when the filter returned FrameStop.
+var errStopDecoding = errors.New("stopped by frame filter")
@@ -0,0 +1,122 @@
+package id3
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestDecoder_FrameFilter(t *testing.T) {
+	const chapterSize = 2 << 20
+
+	var body bytes.Buffer
+	body.Write(generateTextFrame("TIT2", "Title", 0x00))
+
+	for i := 0; i < 10; i++ {
+		body.Write(generateDataFrame("APIC", make([]byte, chapterSize), 0x00))
+	}
+
+	body.Write(generateTextFrame("TALB", "Album", 0x00))
+
+	data := append([]byte("ID3\x03\x00\x00"), encodeTagSize(body.Len())...)
+	data = append(data, body.Bytes()...)
+
+	tests := []struct {
+		name         string
+		filter       func(id string, size int) FrameAction
+		wantIDs      []string
+		wantDataSize int
+		maxAlloc     uint64
+	}{
+		{"keep", nil, []string{"TIT2", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "TALB"}, 10*chapterSize + 14, 0},
+		{"skip", SkipLargeBinaryFrames(1 << 10), []string{"TIT2", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "APIC", "TALB"}, 14, chapterSize / 8},
+		{"stop", func(id string, size int) FrameAction {
+			if id == "APIC" {
+				return FrameStop
+			}
+			return FrameKeep
+		}, []string{"TIT2"}, 7, chapterSize / 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(data))
+			d.FrameFilter = tt.filter
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+
+			tag, err := d.Decode()
+
+			runtime.ReadMemStats(&after)
+
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if tt.maxAlloc > 0 {
+				if allocated := after.TotalAlloc - before.TotalAlloc; allocated > tt.maxAlloc {
+					t.Errorf("Decode() allocated %d bytes, want less than %d", allocated, tt.maxAlloc)
+				}
+			}
+
+			var ids []string
+			dataSize := 0
+			offset := lenOfHeader
+
+			for _, frame := range tag.Frames {
+				ids = append(ids, frame.ID)
+				dataSize += len(frame.Data)
+
+				if frame.Offset != offset {
+					t.Errorf("%s Offset = %v, want %v", frame.ID, frame.Offset, offset)
+				}
+
+				offset += lenOfHeader + frame.DeclaredSize()
+			}
+
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("Decode() frames = %v, want %v", ids, tt.wantIDs)
+			}
+
+			if dataSize != tt.wantDataSize {
+				t.Errorf("Decode() size of frame data = %v, want %v", dataSize, tt.wantDataSize)
+			}
+
+			if d.InputOffset() != len(data) {
+				t.Errorf("InputOffset() = %v, want %v", d.InputOffset(), len(data))
+			}
+
+			if tag.PaddingSize != 0 {
+				t.Errorf("Decode() PaddingSize = %v, want 0", tag.PaddingSize)
+			}
+		})
+	}
+}
+
+func TestDecoder_FrameFilter_CRC(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/id3_crc.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDecoder(bytes.NewReader(data))
+	d.FrameFilter = func(id string, size int) FrameAction { return FrameSkip }
+
+	tag, err := d.Decode()
+
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if tag.CRCValid == nil || !*tag.CRCValid {
+		t.Errorf("Decode() CRCValid = %v, want true", tag.CRCValid)
+	}
+
+	for _, frame := range tag.Frames {
+		if frame.Data != nil || frame.Size == 0 {
+			t.Errorf("%s Data = %v, Size = %v, want skipped with its size", frame.ID, frame.Data, frame.Size)
+		}
+	}
+}
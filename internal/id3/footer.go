@@ -0,0 +1,59 @@
+package id3
+
+import (
+	"bytes"
+	"errors"
+)
+
+var id3v2FooterFlag = []byte("3DI") // first 3 bytes of an ID3v2.4 footer
+
+// flagFooter in the tag header tells that an ID3v2.4 tag ends with a footer.
+const flagFooter = 0x10
+
+// LenOfFooter is the fixed length of an ID3v2.4 footer.
+const LenOfFooter = 10
+
+// footerSize returns the length of the footer after the tag of h, 0 unless an
+// ID3v2.4 tag flags one.
+func (h *tagHeader) footerSize() int {
+	if h.version >= 4 && h.flags&flagFooter != 0 {
+		return LenOfFooter
+	}
+
+	return 0
+}
+
+// HasLeadingTag tells whether data, the first bytes of an input, starts with an
+// ID3v2 tag. Unless strict, a leading UTF-8 BOM is tolerated, like Decoder and
+// SkipReader do. Needs at least 6 bytes to see past a BOM.
+func HasLeadingTag(data []byte, strict bool) bool {
+	if !strict && bytes.HasPrefix(data, utf8BOM) {
+		data = data[len(utf8BOM):]
+	}
+
+	return bytes.HasPrefix(data, id3v2Flag)
+}
+
+// ParseFooter parses an ID3v2.4 footer, as found in the last 10 bytes of a file
+// with an appended tag. Returns the total size of the tag, including header
+// and footer, so the tag starts at that many bytes before the end.
+func ParseFooter(footer []byte) (int, error) {
+	if len(footer) < LenOfFooter || !bytes.Equal(footer[0:3], id3v2FooterFlag) {
+		return 0, errors.New("invalid ID3 footer")
+	}
+
+	return lenOfHeader + decodeTagSize(footer[6:10]) + LenOfFooter, nil
+}
+
+// ParseHeader parses the 10-byte header of an ID3v2 tag. Returns the total
+// size of the tag including the header and the footer, if any, and the major
+// version of the tag.
+func ParseHeader(header []byte) (tagSize int, version uint8, err error) {
+	if len(header) < lenOfHeader || !bytes.Equal(header[0:3], id3v2Flag) {
+		return 0, 0, errors.New("invalid ID3 header")
+	}
+
+	h := tagHeader{version: header[3], flags: header[5]}
+
+	return lenOfHeader + decodeTagSize(header[6:10]) + h.footerSize(), h.version, nil
+}
@@ -0,0 +1,121 @@
+package id3
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestHasLeadingTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		strict bool
+		want   bool
+	}{
+		{"tag", []byte("ID3\x04\x00\x00"), false, true},
+		{"audio", []byte{0xFF, 0xFB, 0x90, 0x44, 0x00, 0x00}, false, false},
+		{"BOM", []byte("\xEF\xBB\xBFID3"), false, true},
+		{"BOM in strict mode", []byte("\xEF\xBB\xBFID3"), true, false},
+		{"too short", []byte("ID"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasLeadingTag(tt.data, tt.strict); got != tt.want {
+				t.Errorf("HasLeadingTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFooter(t *testing.T) {
+	tests := []struct {
+		name    string
+		footer  []byte
+		want    int
+		wantErr bool
+	}{
+		{"OK", []byte("3DI\x04\x00\x10\x00\x00\x02\x01"), 277, false},
+		{"Error: header instead of footer", []byte("ID3\x04\x00\x10\x00\x00\x02\x01"), 0, true},
+		{"Error: too short", []byte("3DI\x04"), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFooter(tt.footer)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFooter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFooter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	size, version, err := ParseHeader([]byte("ID3\x03\x00\x00\x00\x00\x02\x01"))
+
+	if err != nil || size != 267 || version != 3 {
+		t.Errorf("ParseHeader() = (%v, %v, %v), want (267, 3, nil)", size, version, err)
+	}
+
+	if size, _, _ := ParseHeader([]byte("ID3\x04\x00\x10\x00\x00\x02\x01")); size != 277 {
+		t.Errorf("ParseHeader() of a tag with a footer = %v, want 277", size)
+	}
+
+	if _, _, err := ParseHeader([]byte("3DI\x04\x00\x10\x00\x00\x02\x01")); err == nil {
+		t.Errorf("ParseHeader() of a footer error = nil, want error")
+	}
+}
+
+// footerTag returns an ID3v2.4 tag of a TIT2 frame and 16 bytes of padding,
+// ending with a footer.
+func footerTag() []byte {
+	frame := generateTextFrame("TIT2", "Foo Bar", 0x0)
+	header := append([]byte("ID3\x04\x00\x10"), encodeTagSize(len(frame)+16)...)
+
+	tag := append(append([]byte{}, header...), frame...)
+	tag = append(tag, make([]byte, 16)...)
+
+	return append(tag, append([]byte("3DI"), header[3:]...)...)
+}
+
+func TestDecoder_Decode_Footer(t *testing.T) {
+	tag := footerTag()
+	r := bytes.NewReader(append(append([]byte{}, tag...), 0xFF, 0xFB))
+
+	d := NewDecoder(r)
+	decoded, err := d.Decode()
+
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if len(decoded.Frames) != 1 || decoded.PaddingSize != 16 {
+		t.Errorf("Decode() = %d frames, %d bytes of padding, want 1, 16", len(decoded.Frames), decoded.PaddingSize)
+	}
+
+	if d.InputOffset() != len(tag) {
+		t.Errorf("InputOffset() = %v, want %v", d.InputOffset(), len(tag))
+	}
+
+	if rest, _ := ioutil.ReadAll(r); !bytes.Equal(rest, []byte{0xFF, 0xFB}) {
+		t.Errorf("Decode() left % X, want FF FB", rest)
+	}
+}
+
+func TestSkipReader_Footer(t *testing.T) {
+	tag := footerTag()
+	r := bytes.NewReader(append(append([]byte{}, tag...), 0xFF, 0xFB))
+
+	s := NewSkipReader(r)
+	s.OnFrame = func(id string, size int) {}
+
+	if n, err := s.ReadThrough(); n != len(tag) || err != nil {
+		t.Fatalf("ReadThrough() = %v, %v, want %v, nil", n, err, len(tag))
+	}
+
+	if rest, _ := ioutil.ReadAll(r); !bytes.Equal(rest, []byte{0xFF, 0xFB}) {
+		t.Errorf("ReadThrough() left % X, want FF FB", rest)
+	}
+}
@@ -5,23 +5,50 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"unicode"
 	"unicode/utf16"
+	"unicode/utf8"
 )
 
 const textEncodingLatin1 = 0x00
 const textEncodingUTF16 = 0x01
+const textEncodingUTF16BE = 0x02 // ID3v2.4, no BOM
+const textEncodingUTF8 = 0x03    // ID3v2.4
 
 // Frame holds data structure for an ID3v2 frame.
 type Frame struct {
-	ID    string // 4-char
+	ID    string // 4-char, 3-char in ID3v2.2
 	Flags uint16
 	Data  []byte
+
+	// Size is the payload size declared in the frame header when decoded, 0
+	// for frames built in code. See DeclaredSize.
+	Size int
+
+	// Offset is where the frame header starts in the input of the Decoder,
+	// as by InputOffset, 0 for frames built in code.
+	Offset int
+
+	// TrailingPadding is the number of fill bytes (0x00 or 0xFF) after the
+	// terminator of a decoded text frame. Some hardware recorders pad text to
+	// a fixed width this way. Cleared by SetText.
+	TrailingPadding int
+
+	// strictBOM rejects UTF-16 text without BOM with ErrMissingBOM rather
+	// than guessing its byte order. Set by a strict Decoder.
+	strictBOM bool
 }
 
+// ErrMissingBOM is returned for UTF-16 text without byte order mark by the
+// frames of a strict Decoder, see Decoder.Strict.
+var ErrMissingBOM = errors.New("invalid UTF-16 payload (missing BOM)")
+
 // Text returns a string (UTF-8) decoded from frame data, if the data is
 // a text frame or URL frame. For other kinds of frames, an empty string will
 // be returned, and the secondary return value will be bool(false).
 //
+// An empty frame, i.e. of size 0, has an empty text and no error.
+//
 // FIXME: support TXXX and WXXX which has 3 sections, encoding flag, description
 //        and text, separated by 0x00{1,2}
 func (frame *Frame) Text() (string, error) {
@@ -29,18 +56,87 @@ func (frame *Frame) Text() (string, error) {
 		return "", fmt.Errorf("GetText(): Frame %q does not accept text content", frame.ID)
 	}
 
+	// Empty frames are legal, some taggers write them for unset fields.
+	if len(frame.Data) == 0 {
+		return "", nil
+	}
+
 	// First byte is encoding flag
 	switch frame.Data[0] {
 	case textEncodingLatin1:
 		return decodeLatin1Text(frame.Data[1:]), nil
 	case textEncodingUTF16:
-		return decodeUTF16String(frame.Data[1:])
+		return decodeUTF16String(frame.Data[1:], frame.strictBOM)
+	case textEncodingUTF16BE:
+		return decodeUTF16BEString(frame.Data[1:])
+	case textEncodingUTF8:
+		return decodeUTF8Text(frame.Data[1:])
 	default:
 		// Undefined text encoding
 		return "", fmt.Errorf("unable to decode string")
 	}
 }
 
+// TextWithConfidence is like Text, but also returns how plausible the text is,
+// from 0 to 1, as the share of its characters that are likely in a title. Text
+// decoded with the wrong byte order, e.g. a big-endian BOM before little-endian
+// data, is mostly made of unlikely characters. Empty text has a confidence of
+// 1.
+func (frame *Frame) TextWithConfidence() (string, float64, error) {
+	text, err := frame.Text()
+
+	if err != nil {
+		return "", 0, err
+	}
+
+	total, plausible, swapped := 0, 0, 0
+
+	for _, r := range text {
+		total++
+
+		if !isPlausibleRune(r) {
+			continue
+		}
+
+		if isSwappedASCII(r) {
+			swapped++
+		}
+
+		plausible++
+	}
+
+	if total == 0 {
+		return text, 1, nil
+	}
+
+	// a few of them are legit, e.g. U+4E00 一
+	if mostlySwapped(swapped, total) {
+		plausible -= swapped
+	}
+
+	return text, float64(plausible) / float64(total), nil
+}
+
+// isPlausibleRune tells whether r is likely to appear in text, i.e. it's none
+// of control, unassigned and private use characters.
+func isPlausibleRune(r rune) bool {
+	return r != utf8.RuneError && unicode.IsPrint(r) && !unicode.Is(unicode.Co, r)
+}
+
+// isSwappedASCII tells whether r is how a printable ASCII character looks like
+// when its byte order is swapped, i.e. U+xx00 where xx is that character.
+// Some CJK ideographs and other characters look like that too, so only text
+// mostly made of them is taken as swapped, see mostlySwapped.
+func isSwappedASCII(r rune) bool {
+	return r&0xFF == 0 && r>>8 >= 0x20 && r>>8 <= 0x7E
+}
+
+// mostlySwapped tells whether text of total characters, swapped of which are
+// as by isSwappedASCII, was decoded in the wrong byte order.
+func mostlySwapped(swapped, total int) bool {
+	return swapped*2 > total
+}
+
 // SetText sets the frame Data as the str. The existing Data will be overriden.
 //
 // str will be encoded in UTF16 if any rune is not Latin1. Returns error when
@@ -72,16 +168,119 @@ func (frame *Frame) SetText(str string) error {
 	}
 
 	frame.Data = buf.Bytes()
+	frame.TrailingPadding = 0
 
 	return nil
 }
 
-// Bytes returns the encoded bytes of the frame.
+// Texts returns the values of a text frame, which are separated by a
+// terminator. The terminator of the last value and any fill after it, as
+// counted by TrailingPadding, are not taken as extra empty values.
+func (frame *Frame) Texts() ([]string, error) {
+	if !frame.hasText() {
+		return nil, fmt.Errorf("Texts(): Frame %q does not accept text content", frame.ID)
+	}
+
+	if len(frame.Data) == 0 {
+		return nil, nil
+	}
+
+	width, ok := textEncodingWidth(frame.Data[0])
+
+	if !ok {
+		return nil, fmt.Errorf("unable to decode string")
+	}
+
+	text := frame.Data[1:]
+	text = text[:len(text)-textFill(text, width)]
+
+	if len(text) == 0 {
+		return nil, nil
+	}
+
+	var values []string
+	start := 0
+
+	for i := 0; i+width <= len(text); i += width {
+		if isFill(text[i:i+width], 0x00) {
+			value, err := decodeTextValue(text[start:i], frame.Data[0], frame.strictBOM)
+
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, value)
+			start = i + width
+		}
+	}
+
+	value, err := decodeTextValue(text[start:], frame.Data[0], frame.strictBOM)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(values, value), nil
+}
+
+// decodeTextValue decodes a single, unterminated value of a text frame.
+func decodeTextValue(value []byte, encoding byte, strictBOM bool) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+
+	switch encoding {
+	case textEncodingUTF16:
+		return decodeUTF16String(value, strictBOM)
+	case textEncodingUTF16BE:
+		return decodeUTF16BEString(value)
+	case textEncodingUTF8:
+		return decodeUTF8Text(value)
+	default:
+		return string(value), nil
+	}
+}
+
+// Bytes returns the encoded bytes of the frame, as in an ID3v2.3 tag.
 func (frame *Frame) Bytes() ([]byte, error) {
+	return frame.bytes(3)
+}
+
+// bytes returns the encoded bytes of the frame in a tag of the given major
+// version, whose frame sizes are syncsafe from ID3v2.4. In ID3v2.2, the frame
+// header is that of parseFrameHeader, and the ID must be of three characters.
+func (frame *Frame) bytes(version uint8) ([]byte, error) {
 	var buf bytes.Buffer
 	buf.WriteString(frame.ID)
 
-	err := binary.Write(&buf, binary.BigEndian, int32(len(frame.Data))) // size
+	if version == 2 {
+		if len(frame.ID) != 3 {
+			return nil, fmt.Errorf("frame %q has no ID3v2.2 ID", frame.ID)
+		}
+
+		size := len(frame.Data)
+
+		if size > 0xFFFFFF {
+			return nil, fmt.Errorf("frame %q of %d bytes exceeds the maximum of %d", frame.ID, size, 0xFFFFFF)
+		}
+
+		buf.Write([]byte{byte(size >> 16), byte(size >> 8), byte(size)})
+		buf.Write(frame.Data)
+
+		return buf.Bytes(), nil
+	}
+
+	var err error
+
+	if version >= 4 {
+		if len(frame.Data) > maxTagSize {
+			return nil, fmt.Errorf("frame %q of %d bytes exceeds the maximum of %d", frame.ID, len(frame.Data), maxTagSize)
+		}
+
+		buf.Write(encodeTagSize(len(frame.Data)))
+	} else {
+		err = binary.Write(&buf, binary.BigEndian, int32(len(frame.Data))) // size
+	}
 
 	if err != nil {
 		return nil, err
@@ -97,10 +296,45 @@ func (frame *Frame) Bytes() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// ByteSize calculates the bytes required to write the payload. It should be
-// len(Data) + 10 bytes of header
+// ByteSize calculates the bytes required to write the frame, as by Bytes. It
+// should be len(Data) + 10 bytes of header
 func (frame *Frame) ByteSize() int {
-	return len(frame.Data) + 10
+	return frame.PayloadSize() + 10
+}
+
+// PayloadSize returns the size of the frame data held, i.e. len(Data).
+func (frame *Frame) PayloadSize() int {
+	return len(frame.Data)
+}
+
+// DeclaredSize returns the payload size declared in the frame header, which
+// may differ from PayloadSize when the frame was truncated or Data changed
+// after decoding. For frames built in code, it's PayloadSize.
+func (frame *Frame) DeclaredSize() int {
+	if frame.Size == 0 {
+		return frame.PayloadSize()
+	}
+
+	return frame.Size
+}
+
+// ByteRange returns where the frame starts and ends in a file whose bytes from
+// tagStart were the input of the Decoder, i.e. tagStart is where the tag, or
+// junk before it, begins. The range covers the frame header and the declared
+// payload. Frames of three-character IDs have the header of ID3v2.2.
+//
+// Offsets in a tag unsynchronised as a whole count the bytes once the
+// unsynchronisation is undone: the range is then not that of the file, and
+// such frames can't be patched, see PatchTextFrameAt.
+func (frame *Frame) ByteRange(tagStart int64) (start, end int64) {
+	start = tagStart + int64(frame.Offset)
+	header := int64(lenOfHeader)
+
+	if len(frame.ID) == 3 {
+		header = lenOfV22FrameHeader
+	}
+
+	return start, start + header + int64(frame.Size)
 }
 
 func (frame *Frame) String() string {
@@ -113,6 +347,74 @@ func (frame *Frame) String() string {
 	return fmt.Sprintf("%s %-5d %016b %s", frame.ID, len(frame.Data), frame.Flags, content)
 }
 
+// trailingPadding returns the fill after the terminator of a text frame. See
+// TrailingPadding.
+func (frame *Frame) trailingPadding() int {
+	if len(frame.Data) == 0 || frame.ID[0] != 'T' {
+		return 0
+	}
+
+	width, ok := textEncodingWidth(frame.Data[0])
+
+	if !ok {
+		return 0
+	}
+
+	if fill := textFill(frame.Data[1:], width); fill > width {
+		return fill - width
+	}
+
+	return 0
+}
+
+// textEncodingWidth returns the size of a character unit, hence of the
+// terminator, in the given text encoding.
+func textEncodingWidth(encoding byte) (int, bool) {
+	switch encoding {
+	case textEncodingLatin1:
+		return 1, true
+	case textEncodingUTF16, textEncodingUTF16BE:
+		return 2, true
+	case textEncodingUTF8:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
+// textFill returns the length of the terminator and the fill after it at the
+// end of text, i.e. the trailing run of 0x00 and 0xFF units starting from its
+// first 0x00 unit. It's 0 if text isn't terminated.
+func textFill(text []byte, width int) int {
+	fill := -1
+
+	for i := len(text) - len(text)%width - width; i >= 0; i -= width {
+		unit := text[i : i+width]
+
+		if isFill(unit, 0x00) {
+			fill = i
+		} else if !isFill(unit, 0xFF) {
+			break
+		}
+	}
+
+	if fill < 0 {
+		return 0
+	}
+
+	return len(text) - fill
+}
+
+func isFill(unit []byte, c byte) bool {
+	for _, b := range unit {
+		if b != c {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (frame *Frame) hasText() bool {
 	return frame.ID[0] == 'T' || frame.ID[0] == 'W'
 }
@@ -130,7 +432,10 @@ func decodeLatin1Text(data []byte) string {
 	return string(data[:terminus])
 }
 
-func decodeUTF16String(buf []byte) (string, error) {
+// decodeUTF16String decodes UTF-16 text up to its terminator, if any, in the
+// byte order of its BOM. Without BOM, it's an ErrMissingBOM when strictBOM,
+// otherwise the byte order is guessed, see guessUTF16Order.
+func decodeUTF16String(buf []byte, strictBOM bool) (string, error) {
 	if len(buf) < 2 {
 		return "", errors.New("invalid UTF-16 payload")
 	}
@@ -139,12 +444,18 @@ func decodeUTF16String(buf []byte) (string, error) {
 	buf16Bit := make([]uint16, len(buf)/2)
 	var err error
 
+	// the BOM, decoded as U+FEFF, is dropped
+	skip := 1
+
 	if buf[0] == 0xFE && buf[1] == 0xFF {
 		err = binary.Read(reader, binary.BigEndian, buf16Bit)
 	} else if buf[0] == 0xFF && buf[1] == 0xFE {
 		err = binary.Read(reader, binary.LittleEndian, buf16Bit)
+	} else if strictBOM {
+		err = ErrMissingBOM
 	} else {
-		err = errors.New("invalid UTF-16 payload (missing BOM)")
+		err = binary.Read(reader, guessUTF16Order(buf), buf16Bit)
+		skip = 0
 	}
 
 	if err != nil {
@@ -161,8 +472,71 @@ func decodeUTF16String(buf []byte) (string, error) {
 
 	utf8 := utf16.Decode(buf16Bit[:terminus])
 
-	// ignore leading BOM
-	return string(utf8[1:]), nil
+	if len(utf8) < skip {
+		return "", nil
+	}
+
+	return string(utf8[skip:]), nil
+}
+
+// guessUTF16Order returns the byte order of buf, UTF-16 text without BOM. It's
+// little-endian when the text read as big-endian is mostly made of swapped
+// ASCII, see isSwappedASCII, and less so read as little-endian. Otherwise it's
+// big-endian, the default of UTF-16, which keeps e.g. CJK text whose units end
+// in a zero byte.
+func guessUTF16Order(buf []byte) binary.ByteOrder {
+	total, swappedBE, swappedLE := 0, 0, 0
+
+	for i := 0; i+1 < len(buf); i += 2 {
+		if buf[i] == 0 && buf[i+1] == 0 {
+			// the terminator
+			break
+		}
+
+		total++
+
+		if isSwappedASCII(rune(binary.BigEndian.Uint16(buf[i:]))) {
+			swappedBE++
+		}
+
+		if isSwappedASCII(rune(binary.LittleEndian.Uint16(buf[i:]))) {
+			swappedLE++
+		}
+	}
+
+	if mostlySwapped(swappedBE, total) && swappedBE > swappedLE {
+		return binary.LittleEndian
+	}
+
+	return binary.BigEndian
+}
+
+// decodeUTF8Text decodes UTF-8 text up to its terminator, if any.
+func decodeUTF8Text(data []byte) (string, error) {
+	text := decodeLatin1Text(data)
+
+	if !utf8.ValidString(text) {
+		return "", errors.New("invalid UTF-8 payload")
+	}
+
+	return text, nil
+}
+
+// decodeUTF16BEString decodes big-endian UTF-16 without BOM, as of ID3v2.4, up
+// to its terminator, if any.
+func decodeUTF16BEString(buf []byte) (string, error) {
+	units := make([]uint16, len(buf)/2)
+
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(buf[2*i:])
+
+		if units[i] == 0x0000 {
+			units = units[:i]
+			break
+		}
+	}
+
+	return string(utf16.Decode(units)), nil
 }
 
 func encodeUTF16String(str string) ([]byte, error) {
@@ -1,6 +1,8 @@
 package id3
 
 import (
+	"bytes"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -117,15 +119,75 @@ func TestFrame_Text(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "Error: Invalid UTF-16 payload (Missing BOM)",
+			name: "UTF-16BE Text",
 			fields: fields{
 				ID:    "TALB",
 				Flags: 0,
-				Data:  []byte("\x01\x4E\x16\x75\x4C\x4F\x60\x59\x7D\x00\x00"),
+				Data:  []byte("\x02\x4E\x16\x75\x4C\x00\x00"),
+			},
+			want:    "世界",
+			wantErr: false,
+		},
+		{
+			name: "UTF-8 Text",
+			fields: fields{
+				ID:    "TALB",
+				Flags: 0,
+				Data:  []byte("\x03世界\x00"),
+			},
+			want:    "世界",
+			wantErr: false,
+		},
+		{
+			name: "Error: Invalid UTF-8 payload",
+			fields: fields{
+				ID:    "TALB",
+				Flags: 0,
+				Data:  []byte("\x03\xFF\xFE\x00"),
 			},
 			want:    "",
 			wantErr: true,
 		},
+		{
+			name: "UTF-16 Text without BOM, guessed Big Endian",
+			fields: fields{
+				ID:    "TALB",
+				Flags: 0,
+				Data:  []byte("\x01\x4E\x16\x75\x4C\x4F\x60\x59\x7D\x00\x00"),
+			},
+			want:    "世界你好",
+			wantErr: false,
+		},
+		{
+			name: "UTF-16 Text without BOM, CJK ending in zero bytes",
+			fields: fields{
+				ID:    "TALB",
+				Flags: 0,
+				Data:  []byte("\x01\x4E\x00\x4E\x8C\x4E\x09\x00\x00"),
+			},
+			want:    "一二三",
+			wantErr: false,
+		},
+		{
+			name: "UTF-16 Text without BOM, Hangul ending in zero bytes",
+			fields: fields{
+				ID:    "TALB",
+				Flags: 0,
+				Data:  []byte("\x01\xAC\x00\xC6\x94\x00\x00"),
+			},
+			want:    "가요",
+			wantErr: false,
+		},
+		{
+			name: "UTF-16 Text without BOM, guessed Little Endian",
+			fields: fields{
+				ID:    "TALB",
+				Flags: 0,
+				Data:  []byte("\x01H\x00i\x00\x00\x00"),
+			},
+			want:    "Hi",
+			wantErr: false,
+		},
 		{
 			name: "Error: Invalid UTF-16 payload (empty data)",
 			fields: fields{
@@ -141,7 +203,7 @@ func TestFrame_Text(t *testing.T) {
 			fields: fields{
 				ID:    "TALB",
 				Flags: 0,
-				Data:  []byte("\x02"),
+				Data:  []byte("\x09"),
 			},
 			want:    "",
 			wantErr: true,
@@ -176,6 +238,50 @@ func TestFrame_Text(t *testing.T) {
 	}
 }
 
+func TestFrame_Text_StrictBOM(t *testing.T) {
+	frame := &Frame{ID: "TALB", Data: []byte("\x01H\x00i\x00\x00\x00"), strictBOM: true}
+
+	if _, err := frame.Text(); !errors.Is(err, ErrMissingBOM) {
+		t.Errorf("Text() error = %v, want %v", err, ErrMissingBOM)
+	}
+
+	if _, err := frame.Texts(); !errors.Is(err, ErrMissingBOM) {
+		t.Errorf("Texts() error = %v, want %v", err, ErrMissingBOM)
+	}
+
+	frame.Data = []byte("\x01\xFF\xFEH\x00i\x00\x00\x00")
+
+	if got, err := frame.Text(); got != "Hi" || err != nil {
+		t.Errorf("Text() = %q, %v, want %q, nil", got, err, "Hi")
+	}
+}
+
+func TestDecoder_Decode_StrictBOM(t *testing.T) {
+	frame := generateDataFrame("TIT2", []byte("\x01H\x00i\x00\x00\x00"), 0)
+	tag := append([]byte("ID3\x03\x00\x00"), encodeTagSize(len(frame))...)
+	tag = append(tag, frame...)
+
+	for _, strict := range []bool{false, true} {
+		d := NewDecoder(bytes.NewReader(tag))
+		d.Strict = strict
+
+		decoded, err := d.Decode()
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+
+		got, err := decoded.Frames[0].Text()
+
+		if strict && !errors.Is(err, ErrMissingBOM) {
+			t.Errorf("Text() of strict Decoder error = %v, want %v", err, ErrMissingBOM)
+		}
+
+		if !strict && (got != "Hi" || err != nil) {
+			t.Errorf("Text() = %q, %v, want %q, nil", got, err, "Hi")
+		}
+	}
+}
+
 func TestFrame_SetText(t *testing.T) {
 	type fields struct {
 		ID    string
@@ -328,3 +434,125 @@ func TestFrame_ByteSize(t *testing.T) {
 		})
 	}
 }
+
+func TestFrame_Sizes(t *testing.T) {
+	decoded, err := (&Decoder{r: bytes.NewReader(generateDataFrame("PRIV", []byte{0xDE, 0xAD, 0xBE, 0xEF}, 0))}).readFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shortened := *decoded
+	shortened.Data = shortened.Data[:2]
+
+	tests := []struct {
+		name             string
+		frame            *Frame
+		wantByteSize     int
+		wantPayloadSize  int
+		wantDeclaredSize int
+	}{
+		{"built in code", &Frame{ID: "PRIV", Data: []byte{0xDE, 0xAD}}, 12, 2, 2},
+		{"decoded", decoded, 14, 4, 4},
+		{"data shorter than declared", &shortened, 12, 2, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.frame.ByteSize(); got != tt.wantByteSize {
+				t.Errorf("ByteSize() = %v, want %v", got, tt.wantByteSize)
+			}
+			if got := tt.frame.PayloadSize(); got != tt.wantPayloadSize {
+				t.Errorf("PayloadSize() = %v, want %v", got, tt.wantPayloadSize)
+			}
+			if got := tt.frame.DeclaredSize(); got != tt.wantDeclaredSize {
+				t.Errorf("DeclaredSize() = %v, want %v", got, tt.wantDeclaredSize)
+			}
+		})
+	}
+}
+
+func TestFrame_Texts(t *testing.T) {
+	tests := []struct {
+		name                string
+		data                []byte
+		want                []string
+		wantTrailingPadding int
+		wantErr             bool
+	}{
+		{"single", []byte("\x00Foo\x00"), []string{"Foo"}, 0, false},
+		{"not terminated", []byte("\x00Foo"), []string{"Foo"}, 0, false},
+		{"multiple", []byte("\x00Foo\x00Bar\x00"), []string{"Foo", "Bar"}, 0, false},
+		{"empty value in between", []byte("\x00Foo\x00\x00Bar"), []string{"Foo", "", "Bar"}, 0, false},
+		{"NUL fill", []byte("\x00Foo\x00Bar\x00\x00\x00\x00"), []string{"Foo", "Bar"}, 3, false},
+		{"0xFF fill", []byte("\x00Foo\x00\xFF\xFF\xFF"), []string{"Foo"}, 3, false},
+		{"0xFF ending the text", []byte("\x00Fo\xFF\x00"), []string{"Fo\xFF"}, 0, false},
+		{"only fill", []byte("\x00\x00\x00\x00"), nil, 2, false},
+		{"empty", []byte{}, nil, 0, false},
+		{
+			"UTF-16",
+			[]byte("\x01\xFF\xFE\x41\x00\x00\x00\xFE\xFF\x00\x42\x00\x00\x00\x00\xFF\xFF"),
+			[]string{"A", "B"},
+			4,
+			false,
+		},
+		{"unknown encoding", []byte("\x09Foo"), nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := &Frame{ID: "TIT2", Data: tt.data}
+
+			got, err := frame.Texts()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Texts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Texts() = %q, want %q", got, tt.want)
+			}
+
+			if got := frame.trailingPadding(); got != tt.wantTrailingPadding {
+				t.Errorf("trailingPadding() = %v, want %v", got, tt.wantTrailingPadding)
+			}
+		})
+	}
+}
+
+func TestFrame_TextWithConfidence(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		data           []byte
+		want           string
+		wantConfidence float64
+		wantErr        bool
+	}{
+		{"Latin-1", "TIT2", []byte("\x00Hello\x00"), "Hello", 1, false},
+		{"UTF-16", "TIT2", []byte("\x01\xFE\xFF\x4E\x16\x75\x4C\x00\x00"), "世界", 1, false},
+		{"big-endian BOM, little-endian data", "TIT2", []byte("\x01\xFE\xFFH\x00i\x00\x00\x00"), "䠀椀", 0, false},
+		{"control characters", "TIT2", []byte("\x00Caf\x85\x00"), "Caf\x85", 0.75, false},
+		{"CJK ending in zero bytes", "TIT2", []byte("\x01\xFE\xFF\x4E\x00\x4E\x8C\x4E\x09\x00\x00"), "一二三", 1, false},
+		{"CJK, half ending in zero bytes", "TIT2", []byte("\x01\xFE\xFF\x67\x00\x9A\xD8\x00\x00"), "最高", 1, false},
+		{"Hangul ending in zero bytes", "TIT2", []byte("\x01\xFE\xFF\xAC\x00\xC6\x94\x00\x00"), "가요", 1, false},
+		{"empty", "TIT2", []byte{}, "", 1, false},
+		{"not a text frame", "PRIV", []byte("data"), "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := &Frame{ID: tt.id, Data: tt.data}
+
+			got, confidence, err := frame.TextWithConfidence()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TextWithConfidence() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("TextWithConfidence() text = %q, want %q", got, tt.want)
+			}
+
+			if confidence != tt.wantConfidence {
+				t.Errorf("TextWithConfidence() confidence = %v, want %v", confidence, tt.wantConfidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package id3
+
+import (
+	"fmt"
+)
+
+// Limits applied when Decoder.MaxFrames or MaxFrameBytes, or their SkipReader
+// counterparts, are 0. Real tags are far below them.
+const (
+	DefaultMaxFrames     = 10000
+	DefaultMaxFrameBytes = 64 << 20
+)
+
+// ErrTagLimits is returned when a tag has more frames, or more frame data,
+// than allowed. It wraps ErrTooManyFrames when the frame count tripped.
+type ErrTagLimits struct {
+	Limit  string // "frames" or "bytes of frame data"
+	Max    int
+	Frames int // frames read before the limit tripped
+	Offset int // of the frame that tripped it, from the start of the tag
+}
+
+func (e ErrTagLimits) Error() string {
+	return fmt.Sprintf("ID3 tag has more than %d %s, stopped at %04X after %d frames", e.Max, e.Limit, e.Offset, e.Frames)
+}
+
+func (e ErrTagLimits) Unwrap() error {
+	if e.Limit == limitFrames {
+		return ErrTooManyFrames
+	}
+
+	return nil
+}
+
+const (
+	limitFrames     = "frames"
+	limitFrameBytes = "bytes of frame data"
+)
+
+// frameLimits counts frames and their payload against the limits.
+type frameLimits struct {
+	maxFrames int // 0 for unlimited
+	maxBytes  int // 0 for unlimited
+	frames    int
+	bytes     int
+}
+
+func newFrameLimits(maxFrames, maxBytes int) frameLimits {
+	resolve := func(max, def int) int {
+		if max == 0 {
+			return def
+		}
+
+		if max < 0 {
+			return 0
+		}
+
+		return max
+	}
+
+	return frameLimits{
+		maxFrames: resolve(maxFrames, DefaultMaxFrames),
+		maxBytes:  resolve(maxBytes, DefaultMaxFrameBytes),
+	}
+}
+
+// add counts a frame at offset, whose payload of size bytes is going to be
+// kept in memory unless skipped, before it's read.
+func (l *frameLimits) add(size int, skipped bool, offset int) error {
+	if l.maxFrames > 0 && l.frames >= l.maxFrames {
+		return ErrTagLimits{Limit: limitFrames, Max: l.maxFrames, Frames: l.frames, Offset: offset}
+	}
+
+	if !skipped {
+		if l.maxBytes > 0 && l.bytes+size > l.maxBytes {
+			return ErrTagLimits{Limit: limitFrameBytes, Max: l.maxBytes, Frames: l.frames, Offset: offset}
+		}
+
+		l.bytes += size
+	}
+
+	l.frames++
+
+	return nil
+}
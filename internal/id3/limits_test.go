@@ -0,0 +1,137 @@
+package id3
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// generateTinyFramesTag returns an ID3v2.3 tag of count frames of size bytes
+// each, like the tags found by fuzzing.
+func generateTinyFramesTag(count int, size int) []byte {
+	frame := generateDataFrame("PRIV", make([]byte, size), 0x00)
+	body := bytes.Repeat(frame, count)
+
+	data := append([]byte("ID3\x03\x00\x00"), encodeTagSize(len(body))...)
+	return append(data, body...)
+}
+
+func TestDecoder_Decode_Limits(t *testing.T) {
+	tiny := generateTinyFramesTag(100000, 1)
+	large := generateTinyFramesTag(3, 100)
+
+	tests := []struct {
+		name          string
+		data          []byte
+		strict        bool
+		maxFrames     int
+		maxFrameBytes int
+		filter        func(id string, size int) FrameAction
+		wantFrames    int
+		wantErr       error
+	}{
+		{
+			name:       "default frame limit",
+			data:       tiny,
+			wantFrames: DefaultMaxFrames,
+			wantErr:    ErrTagLimits{Limit: "frames", Max: DefaultMaxFrames, Frames: DefaultMaxFrames, Offset: 10 + DefaultMaxFrames*11},
+		},
+		{
+			name:    "default frame limit, strict",
+			data:    tiny,
+			strict:  true,
+			wantErr: ErrTagLimits{Limit: "frames", Max: DefaultMaxFrames, Frames: DefaultMaxFrames, Offset: 10 + DefaultMaxFrames*11},
+		},
+		{
+			name:       "unlimited",
+			data:       tiny,
+			maxFrames:  -1,
+			wantFrames: 100000,
+		},
+		{
+			name:          "frame bytes",
+			data:          large,
+			maxFrameBytes: 250,
+			wantFrames:    2,
+			wantErr:       ErrTagLimits{Limit: "bytes of frame data", Max: 250, Frames: 2, Offset: 10 + 2*110},
+		},
+		{
+			name:          "skipped frames are not kept",
+			data:          large,
+			maxFrameBytes: 250,
+			filter:        SkipLargeBinaryFrames(50),
+			wantFrames:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(tt.data))
+			d.Strict = tt.strict
+			d.MaxFrames = tt.maxFrames
+			d.MaxFrameBytes = tt.maxFrameBytes
+			d.FrameFilter = tt.filter
+
+			tag, err := d.Decode()
+
+			var limits ErrTagLimits
+			if errors.As(err, &limits) {
+				err = limits
+			}
+
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.strict {
+				if tag != nil {
+					t.Errorf("Decode() in strict mode returned a tag")
+				}
+				return
+			}
+
+			if len(tag.Frames) != tt.wantFrames {
+				t.Errorf("Decode() len(tag.Frames) = %v, want %v", len(tag.Frames), tt.wantFrames)
+			}
+
+			if d.InputOffset() != len(tt.data) {
+				t.Errorf("Decode() InputOffset() = %v, want %v", d.InputOffset(), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestErrTagLimits_Unwrap(t *testing.T) {
+	if !errors.Is(ErrTagLimits{Limit: limitFrames}, ErrTooManyFrames) {
+		t.Errorf("ErrTagLimits for frames is not ErrTooManyFrames")
+	}
+
+	if errors.Is(ErrTagLimits{Limit: limitFrameBytes}, ErrTooManyFrames) {
+		t.Errorf("ErrTagLimits for frame bytes is ErrTooManyFrames")
+	}
+}
+
+func TestSkipReader_Limits(t *testing.T) {
+	data := generateTinyFramesTag(100000, 1)
+
+	visited := 0
+	s := NewSkipReader(bytes.NewReader(data))
+	s.OnFrame = func(id string, size int) {
+		visited++
+	}
+
+	n, err := s.ReadThrough()
+
+	var limits ErrTagLimits
+	if !errors.As(err, &limits) || limits.Limit != limitFrames {
+		t.Fatalf("ReadThrough() error = %v, want ErrTagLimits of frames", err)
+	}
+
+	if visited != DefaultMaxFrames {
+		t.Errorf("ReadThrough() visited %d frames, want %d", visited, DefaultMaxFrames)
+	}
+
+	if n != len(data) {
+		t.Errorf("ReadThrough() = %d, want the whole tag of %d", n, len(data))
+	}
+}
@@ -0,0 +1,100 @@
+package id3
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrPatchTooLarge is returned by PatchTextFrameAt when the new text doesn't
+// fit in the frame, nor in the padding after it.
+var ErrPatchTooLarge = errors.New("patched frame doesn't fit in the tag")
+
+// PatchTextFrameAt overwrites the text of frame, one of the frames of tag as
+// decoded from the file ws from tagStart, see Frame.ByteRange, without
+// rewriting the rest of the file. The text is encoded as by SetText.
+//
+// The frame keeps its size when the new text is shorter, the rest being filled
+// with zeros, as TrailingPadding. It only grows when it's the last frame of
+// the tag and the padding can absorb it, otherwise ErrPatchTooLarge is
+// returned and nothing is written. Tags unsynchronised as a whole or with a
+// CRC, and frames with format flags, e.g. compressed or unsynchronised, can't
+// be patched.
+//
+// On success, the frame and the padding size of tag are updated to match the
+// file.
+func PatchTextFrameAt(ws io.WriteSeeker, tag *Tag, frame *Frame, tagStart int64, newText string) error {
+	if tag.Flags&flagUnsync != 0 && tag.Version < 4 {
+		return errors.New("PatchTextFrameAt(): frames of an unsynchronised tag can't be patched")
+	}
+
+	if tag.CRCValid != nil {
+		return errors.New("PatchTextFrameAt(): frames of a tag with a CRC can't be patched")
+	}
+
+	if frame.Flags&0x00FF != 0 {
+		return fmt.Errorf("PatchTextFrameAt(): Frame %q has format flags %04X", frame.ID, frame.Flags)
+	}
+
+	i := -1
+
+	for j := range tag.Frames {
+		if frame.Offset > 0 && tag.Frames[j].Offset == frame.Offset {
+			i = j
+			break
+		}
+	}
+
+	if i < 0 {
+		return fmt.Errorf("PatchTextFrameAt(): Frame %q wasn't decoded from the tag", frame.ID)
+	}
+
+	patched := Frame{ID: frame.ID, Flags: frame.Flags}
+
+	if err := patched.SetText(newText); err != nil {
+		return err
+	}
+
+	size := frame.Size
+
+	if grow := len(patched.Data) - size; grow > 0 {
+		if i != len(tag.Frames)-1 || grow > tag.PaddingSize {
+			return fmt.Errorf("%w: %q needs %d bytes, has %d and %d of padding", ErrPatchTooLarge, frame.ID, len(patched.Data), size, tag.PaddingSize)
+		}
+
+		size = len(patched.Data)
+	}
+
+	// the fill must be made of whole characters to be taken as such
+	width, _ := textEncodingWidth(patched.Data[0])
+
+	if fill := size - len(patched.Data); fill%width != 0 {
+		return fmt.Errorf("%w: %q can't be filled with %d bytes", ErrPatchTooLarge, frame.ID, fill)
+	}
+
+	patched.Data = append(patched.Data, make([]byte, size-len(patched.Data))...)
+
+	b, err := patched.bytes(tag.Version)
+	if err != nil {
+		return err
+	}
+
+	start, _ := frame.ByteRange(tagStart)
+
+	if _, err := ws.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+
+	if _, err := ws.Write(b); err != nil {
+		return err
+	}
+
+	tag.PaddingSize -= size - tag.Frames[i].Size
+	patched.Size = size
+	patched.Offset = tag.Frames[i].Offset
+	patched.TrailingPadding = patched.trailingPadding()
+	tag.Frames[i] = patched
+	*frame = patched
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package id3
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// copyTestData copies data to a temporary file, opened for reading and
+// writing, after prefix bytes of junk.
+func copyTestData(t *testing.T, prefix int, data []byte) *os.File {
+	path := filepath.Join(t.TempDir(), "tag.bin")
+
+	if err := ioutil.WriteFile(path, append(make([]byte, prefix), data...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		f.Close()
+	})
+
+	return f
+}
+
+// decodeAt decodes the tag of f starting at offset.
+func decodeAt(t *testing.T, f *os.File, offset int64) *Tag {
+	data, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := NewDecoder(bytes.NewReader(data[offset:])).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	return tag
+}
+
+func TestTag_ByteRange(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/id3_padded.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := NewDecoder(bytes.NewReader(append(append([]byte{}, utf8BOM...), data...))).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if start, end := tag.ByteRange(); start != 3 || end != 3+65536 {
+		t.Errorf("ByteRange() = %v, %v, want 3, %v", start, end, 3+65536)
+	}
+
+	// from the start of the input, BOM included
+	frame := tag.Frames[0]
+
+	if start, end := frame.ByteRange(100); start != 113 || end != 113+10+23 {
+		t.Errorf("%s ByteRange() = %v, %v, want 113, %v", frame.ID, start, end, 113+10+23)
+	}
+}
+
+func TestPatchTextFrameAt(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/id3_padded.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const tagStart = 128
+	f := copyTestData(t, tagStart, data)
+	tag := decodeAt(t, f, tagStart)
+	title := &tag.Frames[0]
+
+	if err := PatchTextFrameAt(f, tag, title, tagStart, "Patched"); err != nil {
+		t.Fatalf("PatchTextFrameAt() error = %v", err)
+	}
+
+	got := decodeAt(t, f, tagStart)
+
+	if text, _ := got.Frames[0].Text(); text != "Patched" {
+		t.Errorf("TIT2 Text() = %q, want %q", text, "Patched")
+	}
+
+	if got.Frames[0].Size != 23 || title.Size != 23 {
+		t.Errorf("TIT2 Size = %v, %v in tag, want 23", got.Frames[0].Size, title.Size)
+	}
+
+	for i := 1; i < len(got.Frames); i++ {
+		if !bytes.Equal(got.Frames[i].Data, tag.Frames[i].Data) {
+			t.Errorf("%s changed", got.Frames[i].ID)
+		}
+	}
+
+	before, _ := ioutil.ReadFile(f.Name())
+
+	err = PatchTextFrameAt(f, tag, title, tagStart, "A title longer than the twenty-three bytes of the frame")
+	if !errors.Is(err, ErrPatchTooLarge) {
+		t.Fatalf("PatchTextFrameAt() error = %v, want %v", err, ErrPatchTooLarge)
+	}
+
+	if after, _ := ioutil.ReadFile(f.Name()); !bytes.Equal(after, before) {
+		t.Error("PatchTextFrameAt() wrote to the file after refusing")
+	}
+}
+
+func TestPatchTextFrameAt_IntoPadding(t *testing.T) {
+	frames := append(generateTextFrame("TALB", "Album", 0x0), generateTextFrame("TIT2", "Title", 0x0)...)
+
+	for _, version := range []byte{3, 4} {
+		data := append([]byte{'I', 'D', '3', version, 0x00, 0x00}, encodeTagSize(len(frames)+200)...)
+		data = append(append(data, frames...), make([]byte, 200)...)
+
+		f := copyTestData(t, 0, data)
+		tag := decodeAt(t, f, 0)
+		long := string(bytes.Repeat([]byte("a"), 150))
+
+		if err := PatchTextFrameAt(f, tag, &tag.Frames[1], 0, long); err != nil {
+			t.Fatalf("ID3v2.%d: PatchTextFrameAt() error = %v", version, err)
+		}
+
+		got := decodeAt(t, f, 0)
+
+		if text, _ := got.Frames[1].Text(); text != long {
+			t.Errorf("ID3v2.%d: TIT2 Text() = %q, want %q", version, text, long)
+		}
+
+		if got.PaddingSize != tag.PaddingSize || got.PaddingSize != 200-(152-7) {
+			t.Errorf("ID3v2.%d: PaddingSize = %v, %v in tag, want %v", version, got.PaddingSize, tag.PaddingSize, 200-(152-7))
+		}
+
+		// past the padding
+		if err := PatchTextFrameAt(f, tag, &tag.Frames[1], 0, long+long); !errors.Is(err, ErrPatchTooLarge) {
+			t.Errorf("ID3v2.%d: PatchTextFrameAt() error = %v, want %v", version, err, ErrPatchTooLarge)
+		}
+
+		// not the last frame
+		if err := PatchTextFrameAt(f, tag, &tag.Frames[0], 0, long); !errors.Is(err, ErrPatchTooLarge) {
+			t.Errorf("ID3v2.%d: PatchTextFrameAt() error = %v, want %v", version, err, ErrPatchTooLarge)
+		}
+	}
+}
+
+func TestPatchTextFrameAt_Unsupported(t *testing.T) {
+	frame := generateTextFrame("TIT2", "Title", 0x0)
+
+	tests := []struct {
+		name  string
+		flags byte
+		frame *Frame
+	}{
+		{"unsynchronised tag", flagUnsync, nil},
+		{"frame built in code", 0x00, &Frame{ID: "TIT2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte{'I', 'D', '3', 3, 0x00, tt.flags}, encodeTagSize(len(frame))...)
+			f := copyTestData(t, 0, append(data, frame...))
+			tag := decodeAt(t, f, 0)
+
+			target := tt.frame
+			if target == nil {
+				target = &tag.Frames[0]
+			}
+
+			if err := PatchTextFrameAt(f, tag, target, 0, "T"); err == nil {
+				t.Error("PatchTextFrameAt() error = nil, want error")
+			}
+		})
+	}
+}
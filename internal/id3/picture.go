@@ -0,0 +1,157 @@
+package id3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"mp3len/internal/buffers"
+)
+
+// PictureMeta describes the picture of an APIC frame, without the image.
+type PictureMeta struct {
+	MIME        string // e.g. "image/jpeg"
+	Type        byte   // e.g. $03 for the front cover
+	Description string
+	Size        int // of the image, in bytes
+}
+
+var errTruncatedPicture = errors.New("APIC frame is truncated")
+
+// Picture parses the frame data as an attached picture, i.e.
+//
+//	Text encoding   $xx
+//	MIME type       <text string> $00
+//	Picture type    $xx
+//	Description     <text string according to encoding> $00 (00)
+//	Picture data    <binary data>
+//
+// and returns its description and image. Returns error when the frame is not
+// APIC, or is malformed.
+func (frame *Frame) Picture() (*PictureMeta, []byte, error) {
+	if frame.ID != "APIC" {
+		return nil, nil, fmt.Errorf("Picture(): Frame %q is not an attached picture frame", frame.ID)
+	}
+
+	meta, n, err := readPictureMeta(bytes.NewReader(frame.Data), len(frame.Data), frame.strictBOM)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return meta, frame.Data[n:], nil
+}
+
+// readPictureMeta reads the fields of an APIC frame of size bytes before the
+// image from r, and returns them with the bytes read. See Frame.strictBOM.
+func readPictureMeta(r io.Reader, size int, strictBOM bool) (*PictureMeta, int, error) {
+	n := 0
+
+	read := func(p []byte) error {
+		if n+len(p) > size {
+			return errTruncatedPicture
+		}
+
+		m, err := io.ReadFull(r, p)
+		n += m
+
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+
+	readText := func(width int) ([]byte, error) {
+		var text []byte
+		unit := make([]byte, width)
+
+		for {
+			if err := read(unit); err != nil {
+				return nil, err
+			}
+
+			if isFill(unit, 0x00) {
+				return text, nil
+			}
+
+			text = append(text, unit...)
+		}
+	}
+
+	b := make([]byte, 1)
+
+	if err := read(b); err != nil {
+		return nil, n, err
+	}
+
+	encoding := b[0]
+	width, ok := textEncodingWidth(encoding)
+
+	if !ok {
+		return nil, n, fmt.Errorf("APIC frame has an invalid text encoding flag %X", encoding)
+	}
+
+	mime, err := readText(1)
+	if err != nil {
+		return nil, n, err
+	}
+
+	if err := read(b); err != nil {
+		return nil, n, err
+	}
+
+	description, err := readText(width)
+	if err != nil {
+		return nil, n, err
+	}
+
+	meta := &PictureMeta{MIME: decodeLatin1Text(mime), Type: b[0]}
+
+	if meta.Description, err = decodeTextValue(description, encoding, strictBOM); err != nil {
+		return nil, n, fmt.Errorf("APIC description: %w", err)
+	}
+
+	meta.Size = size - n
+
+	return meta, n, nil
+}
+
+// streamPicture reads the payload of the APIC frame, read up to its header,
+// copying the image to the writer of PictureWriter, and returns frame as is,
+// without Data.
+func (d *Decoder) streamPicture(header []byte, frame *Frame) (*Frame, error) {
+	r := d.r
+
+	if d.crc != nil {
+		d.crc.Write(header)
+		r = io.TeeReader(r, d.crc)
+	}
+
+	meta, n, err := readPictureMeta(r, frame.Size, d.Strict)
+	d.n += n
+
+	if err != nil {
+		return nil, err
+	}
+
+	w := d.PictureWriter(meta)
+	if w == nil {
+		w = ioutil.Discard
+	}
+
+	copied, err := buffers.Copy(d.BufferPool, w, r, int64(meta.Size))
+	d.n += int(copied)
+
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return frame, nil
+}
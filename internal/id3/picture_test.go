@@ -0,0 +1,147 @@
+package id3
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestFrame_Picture(t *testing.T) {
+	tests := []struct {
+		name      string
+		frame     Frame
+		want      *PictureMeta
+		wantImage []byte
+		wantErr   bool
+	}{
+		{
+			name:      "Latin-1",
+			frame:     Frame{ID: "APIC", Data: []byte("\x00image/png\x00\x03Cover\x00\x89PNG")},
+			want:      &PictureMeta{MIME: "image/png", Type: 0x03, Description: "Cover", Size: 4},
+			wantImage: []byte("\x89PNG"),
+		},
+		{
+			name:      "UTF-16",
+			frame:     Frame{ID: "APIC", Data: []byte("\x01image/jpeg\x00\x04\xFF\xFEB\x00\x00\x00\xFF\xD8")},
+			want:      &PictureMeta{MIME: "image/jpeg", Type: 0x04, Description: "B", Size: 2},
+			wantImage: []byte("\xFF\xD8"),
+		},
+		{
+			name:      "no image",
+			frame:     Frame{ID: "APIC", Data: []byte("\x00\x00\x00\x00")},
+			want:      &PictureMeta{},
+			wantImage: []byte{},
+		},
+		{
+			name:    "unterminated description",
+			frame:   Frame{ID: "APIC", Data: []byte("\x00image/png\x00\x03Cover")},
+			wantErr: true,
+		},
+		{
+			name:    "invalid encoding",
+			frame:   Frame{ID: "APIC", Data: []byte("\x07image/png\x00\x03\x00")},
+			wantErr: true,
+		},
+		{
+			name:    "not APIC",
+			frame:   Frame{ID: "TIT2", Data: []byte{0x00, 'a'}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, image, err := tt.frame.Picture()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Picture() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Picture() = %+v, want %+v", got, tt.want)
+			}
+
+			if !bytes.Equal(image, tt.wantImage) {
+				t.Errorf("Picture() image = %q, want %q", image, tt.wantImage)
+			}
+		})
+	}
+}
+
+func TestDecoder_PictureWriter(t *testing.T) {
+	const imageSize = 5 << 20
+
+	image := bytes.Repeat([]byte{0xAB}, imageSize)
+	picture := append([]byte("\x00image/jpeg\x00\x03Front\x00"), image...)
+
+	var body bytes.Buffer
+	body.Write(generateTextFrame("TIT2", "Title", 0x00))
+	body.Write(generateDataFrame("APIC", picture, 0x00))
+	body.Write(generateTextFrame("TALB", "Album", 0x00))
+
+	data := append([]byte("ID3\x03\x00\x00"), encodeTagSize(body.Len())...)
+	data = append(data, body.Bytes()...)
+
+	var metas []PictureMeta
+	var written countingWriter
+
+	d := NewDecoder(bytes.NewReader(data))
+	d.PictureWriter = func(meta *PictureMeta) io.Writer {
+		metas = append(metas, *meta)
+		return &written
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	tag, err := d.Decode()
+
+	runtime.ReadMemStats(&after)
+
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	wantMetas := []PictureMeta{{MIME: "image/jpeg", Type: 0x03, Description: "Front", Size: imageSize}}
+
+	if !reflect.DeepEqual(metas, wantMetas) {
+		t.Errorf("PictureWriter() called with %+v, want %+v", metas, wantMetas)
+	}
+
+	if written.n != imageSize || !written.same {
+		t.Errorf("PictureWriter() wrote %d bytes, want %d bytes of the image", written.n, imageSize)
+	}
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > imageSize/8 {
+		t.Errorf("Decode() allocated %d bytes, want less than %d", allocated, imageSize/8)
+	}
+
+	if len(tag.Frames) != 3 || tag.Frames[1].ID != "APIC" || tag.Frames[1].Data != nil || tag.Frames[1].Size != len(picture) {
+		t.Fatalf("Decode() frames = %v, want the APIC frame without data", tag.Frames)
+	}
+
+	if album, _ := tag.Frames[2].Text(); album != "Album" {
+		t.Errorf("Decode() TALB = %q, want %q", album, "Album")
+	}
+
+	if d.InputOffset() != len(data) {
+		t.Errorf("InputOffset() = %v, want %v", d.InputOffset(), len(data))
+	}
+}
+
+// countingWriter counts the bytes written, and whether they were all 0xAB.
+type countingWriter struct {
+	n    int
+	same bool
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		w.same = true
+	}
+
+	w.same = w.same && bytes.Count(p, []byte{0xAB}) == len(p)
+	w.n += len(p)
+
+	return len(p), nil
+}
@@ -0,0 +1,115 @@
+package id3
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Sources of a ReplayGain
+const (
+	ReplayGainFromTXXX = "TXXX"
+	ReplayGainFromLAME = "LAME"
+)
+
+// ReplayGain holds the loudness normalization values of a track, as written
+// by ReplayGain scanners.
+type ReplayGain struct {
+	// TrackGain and AlbumGain are in dB, when HasTrackGain and HasAlbumGain.
+	TrackGain    float64
+	AlbumGain    float64
+	HasTrackGain bool
+	HasAlbumGain bool
+
+	// TrackPeak and AlbumPeak are the peak amplitudes, 1 being full scale,
+	// or 0 if unknown.
+	TrackPeak float64
+	AlbumPeak float64
+
+	// Source is where the values come from, ReplayGainFromTXXX or
+	// ReplayGainFromLAME.
+	Source string
+}
+
+// ReplayGain returns the replay gain of the tag, from its TXXX frames like
+// "replaygain_track_gain", or else from LAME, the LAME tag of the audio when
+// the caller set it. The two are never mixed. ok is false when neither has a
+// gain.
+func (t *Tag) ReplayGain() (*ReplayGain, bool) {
+	if gain, ok := t.txxxReplayGain(); ok {
+		return gain, true
+	}
+
+	if t.LAME == nil || !t.LAME.HasTrackGain && !t.LAME.HasAlbumGain {
+		return nil, false
+	}
+
+	// LAME only measures the peak of the track
+	return &ReplayGain{
+		TrackGain:    t.LAME.TrackGain,
+		AlbumGain:    t.LAME.AlbumGain,
+		HasTrackGain: t.LAME.HasTrackGain,
+		HasAlbumGain: t.LAME.HasAlbumGain,
+		TrackPeak:    t.LAME.Peak,
+		Source:       ReplayGainFromLAME,
+	}, true
+}
+
+// txxxReplayGain reads the replay gain from TXXX frames. Descriptions are
+// matched regardless of case, as taggers differ. Values that can't be parsed
+// are ignored.
+func (t *Tag) txxxReplayGain() (*ReplayGain, bool) {
+	gain := &ReplayGain{Source: ReplayGainFromTXXX}
+
+	for i := range t.Frames {
+		if frameID(t.Frames[i].ID) != "TXXX" {
+			continue
+		}
+
+		values, err := t.Frames[i].Texts()
+
+		if err != nil || len(values) < 2 {
+			continue
+		}
+
+		value := strings.TrimSpace(values[1])
+
+		switch strings.ToLower(values[0]) {
+		case "replaygain_track_gain":
+			gain.TrackGain, gain.HasTrackGain = parseGain(value)
+		case "replaygain_album_gain":
+			gain.AlbumGain, gain.HasAlbumGain = parseGain(value)
+		case "replaygain_track_peak":
+			gain.TrackPeak, _ = parsePeak(value)
+		case "replaygain_album_peak":
+			gain.AlbumPeak, _ = parsePeak(value)
+		}
+	}
+
+	if !gain.HasTrackGain && !gain.HasAlbumGain {
+		return nil, false
+	}
+
+	return gain, true
+}
+
+// parseGain parses a gain like "-6.48 dB" or "+1.2dB".
+func parseGain(value string) (float64, bool) {
+	if len(value) >= 2 && strings.EqualFold(value[len(value)-2:], "dB") {
+		value = strings.TrimSpace(value[:len(value)-2])
+	}
+
+	gain, err := strconv.ParseFloat(value, 64)
+
+	return gain, err == nil
+}
+
+// parsePeak parses a peak amplitude like "0.988312".
+func parsePeak(value string) (float64, bool) {
+	peak, err := strconv.ParseFloat(value, 64)
+
+	if err != nil || peak < 0 {
+		return 0, false
+	}
+
+	return peak, true
+}
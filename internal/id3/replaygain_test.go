@@ -0,0 +1,106 @@
+package id3
+
+import (
+	"reflect"
+	"testing"
+
+	"mp3len/internal/mp3header"
+)
+
+func txxxFrame(description, value string) Frame {
+	return Frame{ID: "TXXX", Data: []byte("\x00" + description + "\x00" + value)}
+}
+
+func TestTag_ReplayGain(t *testing.T) {
+	lame := &mp3header.LAMETag{Peak: 0.95, TrackGain: -3.1, HasTrackGain: true}
+
+	tests := []struct {
+		name   string
+		tag    *Tag
+		want   *ReplayGain
+		wantOK bool
+	}{
+		{
+			name: "TXXX",
+			tag: &Tag{Frames: []Frame{
+				txxxFrame("replaygain_track_gain", "-6.48 dB"),
+				txxxFrame("replaygain_track_peak", "0.988312"),
+				txxxFrame("REPLAYGAIN_ALBUM_GAIN", "+1.20dB"),
+				txxxFrame("replaygain_album_peak", "1.000000"),
+			}, LAME: lame},
+			want: &ReplayGain{
+				TrackGain:    -6.48,
+				AlbumGain:    1.2,
+				HasTrackGain: true,
+				HasAlbumGain: true,
+				TrackPeak:    0.988312,
+				AlbumPeak:    1,
+				Source:       ReplayGainFromTXXX,
+			},
+			wantOK: true,
+		},
+		{
+			name: "TXXX album only",
+			tag: &Tag{Frames: []Frame{
+				txxxFrame("replaygain_album_gain", "-2 dB"),
+				txxxFrame("replaygain_track_gain", "loud"),
+			}},
+			want:   &ReplayGain{AlbumGain: -2, HasAlbumGain: true, Source: ReplayGainFromTXXX},
+			wantOK: true,
+		},
+		{
+			name: "LAME",
+			tag:  &Tag{Frames: []Frame{txxxFrame("replaygain_track_peak", "0.5")}, LAME: lame},
+			want: &ReplayGain{
+				TrackGain:    -3.1,
+				HasTrackGain: true,
+				TrackPeak:    0.95,
+				Source:       ReplayGainFromLAME,
+			},
+			wantOK: true,
+		},
+		{
+			name: "LAME without gain",
+			tag:  &Tag{LAME: &mp3header.LAMETag{Peak: 0.95}},
+		},
+		{
+			name: "none",
+			tag:  &Tag{Frames: []Frame{txxxFrame("CATALOG", "XY-1")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.tag.ReplayGain()
+
+			if ok != tt.wantOK {
+				t.Fatalf("ReplayGain() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReplayGain() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseGain(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   float64
+		wantOK bool
+	}{
+		{"-6.48 dB", -6.48, true},
+		{"+1.20dB", 1.2, true},
+		{"3 DB", 3, true},
+		{"0.5", 0.5, true},
+		{"dB", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseGain(tt.value)
+
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseGain(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -1,15 +1,48 @@
 package id3
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
 	"io"
-	"io/ioutil"
+
+	"mp3len/internal/buffers"
+	"mp3len/internal/readers"
 )
 
 // SkipReader reads through the whole ID3v2 tag block, but does not store
 // anything in the memory. Useful for ignoring ID3v2 tag section.
+//
+// When the reader is an io.Seeker, the tag body is skipped by seeking, unless
+// CaptureRaw is set. Seeking stops at the end of the reader, so that a tag
+// truncated by e.g. an io.SectionReader is still reported.
 type SkipReader struct {
-	r io.Reader
-	n int // n bytes that has been read
+	// CaptureRaw keeps a copy of the skipped tag bytes, available from Raw().
+	CaptureRaw bool
+
+	// Strict rejects inputs that deviate from the spec in ways otherwise
+	// tolerated, such as a UTF-8 BOM before the tag.
+	Strict bool
+
+	// BufferPool provides the buffer used to skip the tag. Defaults to
+	// buffers.Default.
+	BufferPool buffers.Pool
+
+	// OnFrame, when set, is called with the ID and declared size of every
+	// frame, e.g. to tell whether the tag has artwork. Frame data is still
+	// skipped, not read into memory.
+	OnFrame func(id string, size int)
+
+	// MaxFrames and MaxFrameBytes bound the frames visited by OnFrame, like
+	// those of Decoder. Past them, OnFrame isn't called anymore, the tag is
+	// skipped through and ReadThrough returns an ErrTagLimits.
+	MaxFrames     int
+	MaxFrameBytes int
+
+	r      io.Reader
+	n      int // n bytes that has been read
+	raw    []byte
+	header tagHeader
 }
 
 func NewSkipReader(r io.Reader) *SkipReader {
@@ -17,23 +50,178 @@ func NewSkipReader(r io.Reader) *SkipReader {
 }
 
 func (s *SkipReader) ReadThrough() (int, error) {
-	header := new(tagHeader)
-	n, err := readTagHeader(s.r, header)
+	seeker, canSeek := s.r.(io.Seeker)
+	s.r = readers.GuardProgress(s.r)
+
+	var raw *bytes.Buffer
+
+	if s.CaptureRaw {
+		raw = new(bytes.Buffer)
+		s.r = io.TeeReader(s.r, raw)
+	}
+
+	header := &s.header
+	n, err := readTagHeader(s.r, header, s.Strict)
 	s.n += n
 
 	if err != nil {
 		return s.n, err
 	}
 
-	// Avoid read exceeding ID3 Tag boundary
-	s.r = io.LimitReader(s.r, int64(header.size))
+	if raw != nil {
+		raw.Grow(header.size)
+	}
+
+	skip := func(n int64) (int64, error) {
+		if canSeek && raw == nil {
+			return readers.SeekForward(seeker, n)
+		}
+
+		return buffers.Discard(s.BufferPool, s.r, n)
+	}
+
+	var nRead int64
 
-	nDiscarded, err := io.CopyN(ioutil.Discard, s.r, int64(header.size))
-	s.n += int(nDiscarded)
+	// frame sizes of a tag unsynchronised as a whole don't count the bytes
+	// added by unsynchronisation, so its frames can't be skipped one by one
+	unsynced := header.flags&flagUnsync != 0 && header.version < 4
+
+	var limitErr error
+
+	if s.OnFrame != nil && !unsynced {
+		nRead, err = s.visitFrames(skip, int64(header.size))
+
+		if _, ok := err.(ErrTagLimits); ok {
+			limitErr, err = err, nil
+		}
+	}
+
+	// the footer, if any, follows the body
+	size := int64(header.size + header.footerSize())
+
+	// Reads exactly up to the ID3 Tag boundary
+	if err == nil {
+		var nDiscarded int64
+		nDiscarded, err = skip(size - nRead)
+		nRead += nDiscarded
+	}
+
+	s.n += int(nRead)
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Unlike Decoder, the end of the frames is unknown, so it's always an
+		// error.
+		return s.n, fmt.Errorf("%w: tag ends %d bytes into %d bytes", io.ErrUnexpectedEOF, nRead, size)
+	}
 
 	if err != nil {
 		return s.n, err
 	}
 
-	return s.n, nil
+	if raw != nil {
+		s.raw = raw.Bytes()[header.junk:]
+	}
+
+	return s.n, limitErr
+}
+
+// visitFrames calls OnFrame for the frames within the size bytes of the tag
+// body, skipping their data. It stops at the padding, at anything that isn't a
+// frame header, or past the limits, leaving the rest of the body to the caller.
+func (s *SkipReader) visitFrames(skip func(n int64) (int64, error), size int64) (int64, error) {
+	var n int64
+	header := make([]byte, lenOfFrameHeader(s.header.version))
+	limits := newFrameLimits(s.MaxFrames, s.MaxFrameBytes)
+
+	if s.header.version == 2 && s.header.flags&flagV22Compression != 0 {
+		// no frame headers to read
+		return n, nil
+	}
+
+	if s.header.flags&flagExtendedHeader != 0 && size >= 4 {
+		m, err := io.ReadFull(s.r, header[:4])
+		n += int64(m)
+
+		if err != nil {
+			return n, err
+		}
+
+		extendedSize := int64(binary.BigEndian.Uint32(header[:4]))
+
+		if s.header.version >= 4 {
+			// syncsafe, including the size itself
+			extendedSize = int64(decodeTagSize(header[:4])) - 4
+		}
+
+		if extendedSize < 0 || extendedSize > size-n {
+			return n, nil
+		}
+
+		skipped, err := skip(extendedSize)
+		n += skipped
+
+		if err != nil {
+			return n, err
+		}
+	}
+
+	for size-n >= int64(len(header)) {
+		m, err := io.ReadFull(s.r, header)
+		n += int64(m)
+
+		if err != nil {
+			return n, err
+		}
+
+		id, declared, _ := parseFrameHeader(header, s.header.version)
+
+		if !isValidFrameID(id) {
+			// padding or garbage
+			return n, nil
+		}
+
+		frameSize := int64(declared)
+
+		// from the start of the tag, as in Decoder: n counts the body of the
+		// tag up to the end of the frame header
+		offset := s.header.junk + lenOfHeader + int(n) - len(header)
+
+		if err := limits.add(int(frameSize), false, offset); err != nil {
+			return n, err
+		}
+
+		s.OnFrame(string(id), int(frameSize))
+
+		if frameSize > size-n {
+			frameSize = size - n
+		}
+
+		skipped, err := skip(frameSize)
+		n += skipped
+
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// BytesRead returns how many bytes ReadThrough consumed from the reader,
+// including a skipped BOM. After a successful ReadThrough, the reader is
+// positioned right after the tag, this many bytes from where it started.
+func (s *SkipReader) BytesRead() int {
+	return s.n
+}
+
+// Raw returns the tag bytes consumed by ReadThrough (header, frames and
+// padding). Returns nil unless CaptureRaw was set before ReadThrough.
+func (s *SkipReader) Raw() []byte {
+	return s.raw
+}
+
+// Version returns the major version and revision of the tag, e.g. (3, 0) for
+// ID3v2.3.0. Only meaningful after ReadThrough.
+func (s *SkipReader) Version() (uint8, uint8) {
+	return s.header.version, s.header.revision
 }
@@ -1,8 +1,11 @@
 package id3
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"reflect"
 	"testing"
 )
@@ -62,6 +65,178 @@ func TestSkipReader_ReadThrough(t *testing.T) {
 			if got != tt.want {
 				t.Errorf("SkipReader.ReadThrough() = %v, want %v", got, tt.want)
 			}
+			if s.BytesRead() != tt.want {
+				t.Errorf("SkipReader.BytesRead() = %v, want %v", s.BytesRead(), tt.want)
+			}
+			if version, revision := s.Version(); version != 3 || revision != 0 {
+				t.Errorf("SkipReader.Version() = (%v, %v), want (3, 0)", version, revision)
+			}
+		})
+	}
+}
+
+func TestSkipReader_CaptureRaw(t *testing.T) {
+	for _, filePath := range []string{"./testdata/id3_compact.bin", "./testdata/id3_padded.bin"} {
+		t.Run(fmt.Sprintf("file: %s", filePath), func(t *testing.T) {
+			want, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := &SkipReader{r: bytes.NewReader(want), CaptureRaw: true}
+			n, err := s.ReadThrough()
+			if err != nil {
+				t.Fatalf("SkipReader.ReadThrough() error = %v", err)
+			}
+
+			if !bytes.Equal(s.Raw(), want[:n]) {
+				t.Errorf("SkipReader.Raw() differs from the first %d bytes of input", n)
+			}
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		s := NewSkipReader(openTestData("./testdata/id3_compact.bin", t))
+		if _, err := s.ReadThrough(); err != nil {
+			t.Fatalf("SkipReader.ReadThrough() error = %v", err)
+		}
+
+		if s.Raw() != nil {
+			t.Errorf("SkipReader.Raw() = %d bytes, want nil", len(s.Raw()))
+		}
+	})
+}
+
+// stuckReader returns (0, nil) forever.
+type stuckReader struct{}
+
+func (stuckReader) Read(_ []byte) (int, error) {
+	return 0, nil
+}
+
+func TestSkipReader_ReadThrough_NoProgress(t *testing.T) {
+	_, err := NewSkipReader(stuckReader{}).ReadThrough()
+
+	if err != io.ErrNoProgress {
+		t.Errorf("SkipReader.ReadThrough() error = %v, want %v", err, io.ErrNoProgress)
+	}
+
+	_, err = NewDecoder(stuckReader{}).Decode()
+
+	if err != io.ErrNoProgress {
+		t.Errorf("Decode() error = %v, want %v", err, io.ErrNoProgress)
+	}
+}
+
+func TestSkipReader_ReadThrough_LeadingBOM(t *testing.T) {
+	data := []byte("\xEF\xBB\xBFID3\x03\x00\x00\x00\x00\x00\x02AB")
+
+	s := NewSkipReader(bytes.NewReader(data))
+	n, err := s.ReadThrough()
+
+	if err != nil {
+		t.Fatalf("SkipReader.ReadThrough() error = %v", err)
+	}
+
+	if n != len(data) {
+		t.Errorf("SkipReader.ReadThrough() = %d, want %d", n, len(data))
+	}
+
+	if s.BytesRead() != len(data) {
+		t.Errorf("SkipReader.BytesRead() = %d, want %d", s.BytesRead(), len(data))
+	}
+
+	s = NewSkipReader(bytes.NewReader(data))
+	s.Strict = true
+
+	if _, err := s.ReadThrough(); err == nil {
+		t.Errorf("SkipReader.ReadThrough() in strict mode error = nil, want error")
+	}
+}
+
+func BenchmarkSkipReader_ReadThrough(b *testing.B) {
+	// 1 MiB of padding
+	data := append([]byte("ID3\x03\x00\x00\x00\x40\x00\x00"), make([]byte, 1<<20)...)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewSkipReader(bytes.NewReader(data)).ReadThrough(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSkipReader_ReadThrough_SectionReader(t *testing.T) {
+	tag := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x20"), make([]byte, 0x20)...)
+	file := append(append([]byte("header"), tag...), []byte("trailer")...)
+
+	tests := []struct {
+		name    string
+		size    int64
+		want    int
+		wantErr bool
+	}{
+		{"whole tag", int64(len(tag)), len(tag), false},
+		{"truncated", int64(len(tag)) - 5, len(tag) - 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section := io.NewSectionReader(bytes.NewReader(file), 6, tt.size)
+
+			got, err := NewSkipReader(section).ReadThrough()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SkipReader.ReadThrough() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("SkipReader.ReadThrough() error = %v, want io.ErrUnexpectedEOF", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("SkipReader.ReadThrough() = %v, want %v", got, tt.want)
+			}
+
+			if pos, _ := section.Seek(0, io.SeekCurrent); pos != int64(tt.want) {
+				t.Errorf("SkipReader.ReadThrough() left the section at %d, want %d", pos, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipReader_OnFrame(t *testing.T) {
+	for _, filePath := range []string{"./testdata/id3_compact.bin", "./testdata/id3_padded.bin", "./testdata/id3_recorder.bin", "./testdata/id3_crc.bin"} {
+		t.Run(fmt.Sprintf("file: %s", filePath), func(t *testing.T) {
+			d := NewDecoder(openTestData(filePath, t))
+			tag, err := d.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []string
+			for _, frame := range tag.Frames {
+				want = append(want, fmt.Sprintf("%s %d", frame.ID, frame.Size))
+			}
+
+			var got []string
+			s := NewSkipReader(openTestData(filePath, t))
+			s.OnFrame = func(id string, size int) {
+				got = append(got, fmt.Sprintf("%s %d", id, size))
+			}
+
+			n, err := s.ReadThrough()
+			if err != nil {
+				t.Fatalf("SkipReader.ReadThrough() error = %v", err)
+			}
+
+			if n != d.InputOffset() {
+				t.Errorf("SkipReader.ReadThrough() = %v, want %v", n, d.InputOffset())
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("OnFrame() called with %v, want %v", got, want)
+			}
 		})
 	}
 }
@@ -0,0 +1,161 @@
+package id3
+
+import (
+	"strconv"
+	"strings"
+)
+
+// findFrame returns the first frame with the given ID, or nil if absent. ID3v2.2
+// frames are found by the ID of their ID3v2.3 counterpart, see frameID.
+func (t *Tag) findFrame(id string) *Frame {
+	for i := range t.Frames {
+		if frameID(t.Frames[i].ID) == id {
+			return &t.Frames[i]
+		}
+	}
+
+	return nil
+}
+
+// DiscNumber returns the disc number and the total number of discs from the
+// TPOS frame, which holds either "disc/total" or a bare "disc". total is 0
+// when absent. ok is false when there is no TPOS frame or it can't be parsed.
+func (t *Tag) DiscNumber() (disc int, total int, ok bool) {
+	return t.numberPair("TPOS")
+}
+
+// TrackNumber is like DiscNumber, but for the TRCK frame.
+func (t *Tag) TrackNumber() (track int, total int, ok bool) {
+	return t.numberPair("TRCK")
+}
+
+func (t *Tag) numberPair(id string) (int, int, bool) {
+	frame := t.findFrame(id)
+
+	if frame == nil {
+		return 0, 0, false
+	}
+
+	text, err := frame.Text()
+
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return parseNumberPair(text)
+}
+
+// parseNumberPair parses "n/total" or "n". total is 0 if it's missing.
+func parseNumberPair(text string) (int, int, bool) {
+	parts := strings.SplitN(strings.TrimSpace(text), "/", 2)
+
+	n, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+
+	if err != nil || n < 0 {
+		return 0, 0, false
+	}
+
+	if len(parts) == 1 || strings.TrimSpace(parts[1]) == "" {
+		return n, 0, true
+	}
+
+	total, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+
+	if err != nil || total < 0 {
+		return 0, 0, false
+	}
+
+	return n, total, true
+}
+
+// AllText decodes every text frame into a map from frame ID to value, e.g.
+// "TOFN" to the original file name. Multiple values of a frame are joined with
+// "/". TXXX frames are keyed by "TXXX:" and their description. ID3v2.2 frames
+// are keyed by the ID of their ID3v2.3 counterpart, e.g. TT2 by "TIT2".
+//
+// When an ID appears more than once, the first frame wins. Frames that can't
+// be decoded are left out.
+func (t *Tag) AllText() map[string]string {
+	texts := make(map[string]string)
+
+	for i := range t.Frames {
+		frame := &t.Frames[i]
+
+		if frame.ID[0] != 'T' {
+			continue
+		}
+
+		values, err := frame.Texts()
+
+		if err != nil {
+			continue
+		}
+
+		key := frameID(frame.ID)
+
+		if key == "TXXX" {
+			if len(values) == 0 {
+				continue
+			}
+
+			key = "TXXX:" + values[0]
+			values = values[1:]
+		}
+
+		if _, ok := texts[key]; !ok {
+			texts[key] = strings.Join(values, "/")
+		}
+	}
+
+	return texts
+}
+
+// ByteRange returns where the tag starts and ends in the input of the Decoder,
+// from its header to its padding, or footer for ID3v2.4 tags declaring one.
+func (t *Tag) ByteRange() (start, end int64) {
+	start = int64(t.Offset)
+	end = start + lenOfHeader + int64(t.Size)
+
+	if t.Version >= 4 && t.Flags&flagFooter != 0 {
+		end += LenOfFooter
+	}
+
+	return start, end
+}
+
+// Clone returns a deep copy of the tag. Frame data of the copy can be modified
+// without affecting t.
+func (t *Tag) Clone() *Tag {
+	clone := *t
+
+	if t.Frames != nil {
+		clone.Frames = make([]Frame, len(t.Frames))
+
+		for i, frame := range t.Frames {
+			clone.Frames[i] = frame
+			clone.Frames[i].Data = cloneBytes(frame.Data)
+		}
+	}
+
+	clone.Raw = cloneBytes(t.Raw)
+
+	if t.CRCValid != nil {
+		valid := *t.CRCValid
+		clone.CRCValid = &valid
+	}
+
+	if t.LAME != nil {
+		lame := *t.LAME
+		clone.LAME = &lame
+	}
+
+	return &clone
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+
+	return append(make([]byte, 0, len(b)), b...)
+}
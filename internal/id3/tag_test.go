@@ -0,0 +1,143 @@
+package id3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func textFrame(id string, str string) Frame {
+	frame := Frame{ID: id}
+
+	if err := frame.SetText(str); err != nil {
+		panic(err)
+	}
+
+	return frame
+}
+
+func Test_parseNumberPair(t *testing.T) {
+	tests := []struct {
+		text      string
+		wantN     int
+		wantTotal int
+		wantOK    bool
+	}{
+		{"1/2", 1, 2, true},
+		{"1", 1, 0, true},
+		{" 3 / 12 ", 3, 12, true},
+		{"3/", 3, 0, true},
+		{"", 0, 0, false},
+		{"/2", 0, 0, false},
+		{"a/2", 0, 0, false},
+		{"1/b", 0, 0, false},
+		{"-1", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			n, total, ok := parseNumberPair(tt.text)
+			if n != tt.wantN || total != tt.wantTotal || ok != tt.wantOK {
+				t.Errorf("parseNumberPair() = (%v, %v, %v), want (%v, %v, %v)", n, total, ok, tt.wantN, tt.wantTotal, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTag_DiscNumber(t *testing.T) {
+	tag := &Tag{Frames: []Frame{textFrame("TPOS", "1/2")}}
+
+	if disc, total, ok := tag.DiscNumber(); disc != 1 || total != 2 || !ok {
+		t.Errorf("DiscNumber() = (%v, %v, %v), want (1, 2, true)", disc, total, ok)
+	}
+
+	if _, _, ok := (&Tag{}).DiscNumber(); ok {
+		t.Errorf("DiscNumber() ok = true on a tag without TPOS")
+	}
+}
+
+func TestTag_TrackNumber(t *testing.T) {
+	f := openTestData("./testdata/id3_padded.bin", t)
+
+	tag, err := NewDecoder(f).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if track, total, ok := tag.TrackNumber(); track != 10 || total != 0 || !ok {
+		t.Errorf("TrackNumber() = (%v, %v, %v), want (10, 0, true)", track, total, ok)
+	}
+}
+
+func TestTag_Clone(t *testing.T) {
+	f := openTestData("./testdata/id3_compact.bin", t)
+
+	d := NewDecoder(f)
+	d.CaptureRaw = true
+	tag, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := true
+	tag.CRCValid = &valid
+
+	clone := tag.Clone()
+
+	if !reflect.DeepEqual(clone, tag) {
+		t.Fatalf("Clone() = %v, want %v", clone, tag)
+	}
+
+	original := tag.Frames[0].Data[1]
+	clone.Frames[0].Data[1]++
+	clone.Frames[1].ID = "XXXX"
+	clone.Raw[0] = 'X'
+	*clone.CRCValid = false
+
+	if tag.Frames[0].Data[1] != original {
+		t.Errorf("Clone() shares frame data with the original")
+	}
+
+	if tag.Frames[1].ID == "XXXX" {
+		t.Errorf("Clone() shares the frame slice with the original")
+	}
+
+	if tag.Raw[0] != 'I' {
+		t.Errorf("Clone() shares raw bytes with the original")
+	}
+
+	if !*tag.CRCValid {
+		t.Errorf("Clone() shares CRCValid with the original")
+	}
+
+	empty := (&Tag{Frames: []Frame{{ID: "TENC"}}}).Clone()
+	if empty.Frames[0].Data != nil {
+		t.Errorf("Clone() Data = %v, want nil", empty.Frames[0].Data)
+	}
+}
+
+func TestTag_AllText(t *testing.T) {
+	tag := &Tag{Frames: []Frame{
+		textFrame("TIT2", "Title"),
+		textFrame("TOFN", "original.wav"),
+		textFrame("TPUB", "Publisher"),
+		{ID: "TPE1", Data: []byte("\x00Artist A\x00Artist B\x00")},
+		{ID: "TXXX", Data: []byte("\x00CATALOG\x00XY-1\x00")},
+		{ID: "TXXX", Data: []byte("\x00MOOD\x00calm")},
+		textFrame("TIT2", "Second title"),
+		{ID: "TCOM", Data: []byte("\x09bad encoding")},
+		{ID: "PRIV", Data: []byte("owner\x00data")},
+		textFrame("WOAR", "http://example.com/"),
+	}}
+
+	want := map[string]string{
+		"TIT2":         "Title",
+		"TOFN":         "original.wav",
+		"TPUB":         "Publisher",
+		"TPE1":         "Artist A/Artist B",
+		"TXXX:CATALOG": "XY-1",
+		"TXXX:MOOD":    "calm",
+	}
+
+	if got := tag.AllText(); !reflect.DeepEqual(got, want) {
+		t.Errorf("AllText() = %v, want %v", got, want)
+	}
+}
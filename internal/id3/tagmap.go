@@ -0,0 +1,244 @@
+package id3
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keys of ToMap, besides the "txxx:" ones
+const (
+	KeyTitle    = "title"
+	KeyArtist   = "artist"
+	KeyAlbum    = "album"
+	KeyTrack    = "track"
+	KeyDisc     = "disc"
+	KeyYear     = "year"
+	KeyGenre    = "genre"
+	KeyComment  = "comment"
+	KeyPictures = "pictures"
+)
+
+// txxxPrefix starts the keys of ToMap for TXXX frames, followed by their
+// description.
+const txxxPrefix = "txxx:"
+
+// textKeys are the keys of ToMap taken from a text frame as is.
+var textKeys = []struct {
+	key string
+	id  string
+}{
+	{KeyTitle, "TIT2"},
+	{KeyArtist, "TPE1"},
+	{KeyAlbum, "TALB"},
+}
+
+// ToMap flattens the tag into a map of stable keys, for templates and search
+// indexes: "title", "artist", "album", "track", "disc", "year", "genre",
+// "comment", "txxx:" and the description of each TXXX frame, and "pictures",
+// the number of APIC frames.
+//
+// Values are normalized: track and disc are "n" or "n/total", year is the
+// year of a TDRC timestamp, and genre is a name, even when a number. Keys
+// without value are left out, and so are binary frames.
+func (t *Tag) ToMap() map[string]string {
+	m := make(map[string]string)
+
+	set := func(key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+
+	for _, k := range textKeys {
+		set(k.key, t.frameText(k.id))
+	}
+
+	set(KeyTrack, formatNumberPair(t.TrackNumber()))
+	set(KeyDisc, formatNumberPair(t.DiscNumber()))
+
+	if year := t.frameText("TYER"); year != "" {
+		set(KeyYear, year)
+	} else if timestamp := t.frameText("TDRC"); len(timestamp) >= 4 {
+		set(KeyYear, timestamp[:4])
+	}
+
+	set(KeyGenre, normalizeGenre(t.frameText("TCON")))
+	set(KeyComment, t.comment())
+
+	pictures := 0
+
+	for i := range t.Frames {
+		switch frameID(t.Frames[i].ID) {
+		case "APIC":
+			pictures++
+		case "TXXX":
+			values, err := t.Frames[i].Texts()
+
+			if err != nil || len(values) < 2 {
+				continue
+			}
+
+			// the first frame wins, like in AllText
+			if key := txxxPrefix + values[0]; m[key] == "" {
+				set(key, strings.Join(values[1:], "/"))
+			}
+		}
+	}
+
+	if pictures > 0 {
+		m[KeyPictures] = strconv.Itoa(pictures)
+	}
+
+	return m
+}
+
+// FromMap builds an ID3v2.3 tag from a map like that of ToMap, with a frame
+// per key. Keys it doesn't recognize, and "pictures", are ignored.
+func FromMap(m map[string]string) (*Tag, error) {
+	tag := &Tag{Version: 3}
+
+	add := func(id, value string) error {
+		frame := Frame{ID: id}
+
+		if err := frame.SetText(value); err != nil {
+			return err
+		}
+
+		tag.Frames = append(tag.Frames, frame)
+
+		return nil
+	}
+
+	for _, k := range textKeys {
+		if value, ok := m[k.key]; ok {
+			if err := add(k.id, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, k := range []struct{ key, id string }{{KeyTrack, "TRCK"}, {KeyDisc, "TPOS"}, {KeyYear, "TYER"}, {KeyGenre, "TCON"}} {
+		if value, ok := m[k.key]; ok {
+			if err := add(k.id, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if comment, ok := m[KeyComment]; ok {
+		data, err := encodeText([]string{"", comment}, mapEncoding(comment))
+
+		if err != nil {
+			return nil, err
+		}
+
+		// the language goes between the encoding and the description
+		data = append(append([]byte{data[0]}, "eng"...), data[1:]...)
+		tag.Frames = append(tag.Frames, Frame{ID: "COMM", Data: data})
+	}
+
+	var descriptions []string
+
+	for key := range m {
+		if strings.HasPrefix(key, txxxPrefix) {
+			descriptions = append(descriptions, strings.TrimPrefix(key, txxxPrefix))
+		}
+	}
+
+	// maps have no order, frames do
+	sort.Strings(descriptions)
+
+	for _, description := range descriptions {
+		value := m[txxxPrefix+description]
+		data, err := encodeText([]string{description, value}, mapEncoding(description+value))
+
+		if err != nil {
+			return nil, fmt.Errorf("FromMap(): TXXX %q: %v", description, err)
+		}
+
+		tag.Frames = append(tag.Frames, Frame{ID: "TXXX", Data: data})
+	}
+
+	return tag, nil
+}
+
+// mapEncoding is the encoding FromMap uses for text, the one of SetText.
+func mapEncoding(text string) Encoding {
+	if isLatin1Compatible(text) {
+		return EncodingLatin1
+	}
+
+	return EncodingUTF16
+}
+
+// formatNumberPair formats the result of TrackNumber or DiscNumber.
+func formatNumberPair(n, total int, ok bool) string {
+	switch {
+	case !ok:
+		return ""
+	case total > 0:
+		return fmt.Sprintf("%d/%d", n, total)
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// normalizeGenre turns the text of a TCON frame into the name of a genre.
+// ID3v2.3 refers to ID3v1 genres by number in parentheses, "(17)", optionally
+// followed by a refinement, "(17)Rock & Roll", which is preferred. "(RX)" and
+// "(CR)" stand for Remix and Cover. Bare numbers, written by some taggers, are
+// taken as ID3v1 genres too.
+func normalizeGenre(text string) string {
+	for strings.HasPrefix(text, "(") && !strings.HasPrefix(text, "((") {
+		end := strings.IndexByte(text, ')')
+
+		if end < 0 {
+			break
+		}
+
+		ref, rest := text[1:end], strings.TrimSpace(text[end+1:])
+
+		if rest != "" && !strings.HasPrefix(rest, "(") {
+			return rest
+		}
+
+		// the first of several genres
+		if name := genreRef(ref); name != "" {
+			return name
+		}
+
+		text = rest
+	}
+
+	// "((" escapes a refinement starting with "("
+	if strings.HasPrefix(text, "((") {
+		return text[1:]
+	}
+
+	if name := genreRef(text); name != "" {
+		return name
+	}
+
+	return text
+}
+
+// genreRef returns the name of a genre referred to by number or as RX or CR,
+// or "" if ref is neither.
+func genreRef(ref string) string {
+	switch ref {
+	case "RX":
+		return "Remix"
+	case "CR":
+		return "Cover"
+	}
+
+	n, err := strconv.Atoi(ref)
+
+	if err != nil || n < 0 || n > 255 {
+		return ""
+	}
+
+	return GenreName(byte(n))
+}
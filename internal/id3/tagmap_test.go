@@ -0,0 +1,105 @@
+package id3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTag_ToMap(t *testing.T) {
+	tag := &Tag{Frames: []Frame{
+		textFrame("TIT2", "Title"),
+		textFrame("TPE1", "Artist"),
+		textFrame("TALB", "Album"),
+		textFrame("TRCK", "3/12"),
+		textFrame("TPOS", "1"),
+		textFrame("TDRC", "2001-05-04"),
+		textFrame("TCON", "(17)"),
+		{ID: "COMM", Data: []byte("\x00engdesc\x00A comment\x00")},
+		txxxFrame("CATALOG", "XY-1"),
+		txxxFrame("CATALOG", "XY-2"),
+		{ID: "APIC", Data: make([]byte, 100)},
+		{ID: "APIC", Data: make([]byte, 100)},
+		{ID: "PRIV", Data: []byte("owner\x00data")},
+	}}
+
+	want := map[string]string{
+		"title":        "Title",
+		"artist":       "Artist",
+		"album":        "Album",
+		"track":        "3/12",
+		"disc":         "1",
+		"year":         "2001",
+		"genre":        "Rock",
+		"comment":      "A comment",
+		"txxx:CATALOG": "XY-1",
+		"pictures":     "2",
+	}
+
+	if got := tag.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMap() = %v, want %v", got, want)
+	}
+
+	if got := (&Tag{}).ToMap(); len(got) != 0 {
+		t.Errorf("ToMap() = %v, want empty", got)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	maps := []map[string]string{
+		{
+			"title":        "Title",
+			"artist":       "Artiste café",
+			"album":        "Album",
+			"track":        "3/12",
+			"disc":         "1/2",
+			"year":         "1999",
+			"genre":        "Jazz",
+			"comment":      "Ünïcode comment",
+			"txxx:CATALOG": "XY-1",
+			"txxx:MOOD":    "calm",
+		},
+		{"title": "Title only"},
+		{},
+	}
+	for _, m := range maps {
+		tag, err := FromMap(m)
+
+		if err != nil {
+			t.Fatalf("FromMap() error = %v", err)
+		}
+
+		if got := tag.ToMap(); !reflect.DeepEqual(got, m) {
+			t.Errorf("FromMap().ToMap() = %v, want %v", got, m)
+		}
+	}
+
+	// derived and unknown keys are ignored
+	tag, err := FromMap(map[string]string{"pictures": "2", "mood": "calm"})
+
+	if err != nil || len(tag.Frames) != 0 {
+		t.Errorf("FromMap() = %v, %v, want no frames", tag, err)
+	}
+}
+
+func Test_normalizeGenre(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Rock", "Rock"},
+		{"(17)", "Rock"},
+		{"17", "Rock"},
+		{"(17)Rock & Roll", "Rock & Roll"},
+		{"(17)(8)", "Rock"},
+		{"(RX)", "Remix"},
+		{"(CR)", "Cover"},
+		{"((Parenthesized)", "(Parenthesized)"},
+		{"(200)", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeGenre(tt.text); got != tt.want {
+			t.Errorf("normalizeGenre(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
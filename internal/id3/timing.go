@@ -0,0 +1,59 @@
+package id3
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Timestamp formats of timed frames such as ETCO
+const (
+	TimestampMPEGFrames   byte = 0x01 // timestamps count MPEG frames
+	TimestampMilliseconds byte = 0x02
+)
+
+// TimingEvent is an event of an ETCO frame, e.g. $01 for the end of the
+// initial silence or $03 for the start of the main part.
+type TimingEvent struct {
+	Type byte
+
+	// TimeMS is the time of the event from the start of the audio, in
+	// milliseconds, or in MPEG frames with TimestampMPEGFrames.
+	TimeMS uint32
+}
+
+var errTruncatedTiming = errors.New("ETCO event is truncated")
+
+// EventTimingCodes parses the frame data as event timing codes, i.e.
+//
+//	Time stamp format  $xx
+//	Type of event      $xx
+//	Time stamp         $xx xx xx xx
+//
+// where the last two repeat for each event. Returns error when the frame is
+// not ETCO, or is malformed.
+func (frame *Frame) EventTimingCodes() (format byte, events []TimingEvent, err error) {
+	if frame.ID != "ETCO" {
+		return 0, nil, fmt.Errorf("EventTimingCodes(): Frame %q is not an event timing codes frame", frame.ID)
+	}
+
+	if len(frame.Data) == 0 {
+		return 0, nil, errors.New("ETCO frame has no time stamp format")
+	}
+
+	format = frame.Data[0]
+	data := frame.Data[1:]
+
+	for len(data) > 0 {
+		if len(data) < 5 {
+			return 0, nil, errTruncatedTiming
+		}
+
+		events = append(events, TimingEvent{
+			Type:   data[0],
+			TimeMS: uint32(data[1])<<24 | uint32(data[2])<<16 | uint32(data[3])<<8 | uint32(data[4]),
+		})
+		data = data[5:]
+	}
+
+	return format, events, nil
+}
@@ -0,0 +1,70 @@
+package id3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrame_EventTimingCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		frame      Frame
+		wantFormat byte
+		want       []TimingEvent
+		wantErr    bool
+	}{
+		{
+			name: "milliseconds",
+			frame: Frame{ID: "ETCO", Data: []byte{
+				TimestampMilliseconds,
+				0x03, 0x00, 0x00, 0x01, 0xF4, // main part start at 500ms
+				0x04, 0x00, 0x01, 0x00, 0x00, // outro start at 65536ms
+			}},
+			wantFormat: TimestampMilliseconds,
+			want: []TimingEvent{
+				{Type: 0x03, TimeMS: 500},
+				{Type: 0x04, TimeMS: 65536},
+			},
+		},
+		{
+			name:       "MPEG frames",
+			frame:      Frame{ID: "ETCO", Data: []byte{TimestampMPEGFrames, 0x01, 0x00, 0x00, 0x00, 0x26}},
+			wantFormat: TimestampMPEGFrames,
+			want:       []TimingEvent{{Type: 0x01, TimeMS: 38}},
+		},
+		{
+			name:       "no events",
+			frame:      Frame{ID: "ETCO", Data: []byte{TimestampMilliseconds}},
+			wantFormat: TimestampMilliseconds,
+		},
+		{
+			name:    "empty",
+			frame:   Frame{ID: "ETCO"},
+			wantErr: true,
+		},
+		{
+			name:    "truncated event",
+			frame:   Frame{ID: "ETCO", Data: []byte{TimestampMilliseconds, 0x03, 0x00, 0x00}},
+			wantErr: true,
+		},
+		{
+			name:    "not ETCO",
+			frame:   Frame{ID: "TIT2", Data: []byte{0x00, 'a'}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, events, err := tt.frame.EventTimingCodes()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EventTimingCodes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if format != tt.wantFormat {
+				t.Errorf("EventTimingCodes() format = %v, want %v", format, tt.wantFormat)
+			}
+			if !reflect.DeepEqual(events, tt.want) {
+				t.Errorf("EventTimingCodes() events = %+v, want %+v", events, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package id3
+
+const (
+	flagUnsync      = 0x80   // in the tag header
+	frameFlagUnsync = 0x0002 // in the frame header, ID3v2.4
+)
+
+// addUnsync applies the unsynchronisation scheme to data: a 0x00 is inserted
+// after every 0xFF followed by 0xE0 or more, by 0x00, or ending data. The
+// result has no false MP3 frame sync.
+func addUnsync(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+
+	for i, b := range data {
+		out = append(out, b)
+
+		if b == 0xFF && (i+1 == len(data) || data[i+1] >= 0xE0 || data[i+1] == 0x00) {
+			out = append(out, 0x00)
+		}
+	}
+
+	return out
+}
+
+// removeUnsync reverts addUnsync, dropping every 0x00 after a 0xFF.
+func removeUnsync(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+
+	for i, b := range data {
+		if b == 0x00 && i > 0 && data[i-1] == 0xFF {
+			continue
+		}
+
+		out = append(out, b)
+	}
+
+	return out
+}
@@ -0,0 +1,94 @@
+package id3
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func Test_addUnsync(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{"no 0xFF", []byte{0x01, 0x02}, []byte{0x01, 0x02}},
+		{"false sync", []byte{0xFF, 0xE0}, []byte{0xFF, 0x00, 0xE0}},
+		{"0xFF 0x00", []byte{0xFF, 0x00}, []byte{0xFF, 0x00, 0x00}},
+		{"0xFF at the end", []byte{0x01, 0xFF}, []byte{0x01, 0xFF, 0x00}},
+		{"harmless 0xFF", []byte{0xFF, 0x10}, []byte{0xFF, 0x10}},
+		{"consecutive 0xFF", []byte{0xFF, 0xFF, 0xFF}, []byte{0xFF, 0x00, 0xFF, 0x00, 0xFF, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := addUnsync(tt.data)
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("addUnsync() = %x, want %x", got, tt.want)
+			}
+
+			if back := removeUnsync(got); !bytes.Equal(back, tt.data) {
+				t.Errorf("removeUnsync() = %x, want %x", back, tt.data)
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode_Unsync(t *testing.T) {
+	frames := []Frame{
+		textFrame("TIT2", "Title"),
+		{ID: "PRIV", Data: []byte("owner\x00\xFF\xFB\x90\x44\xFF\x00\xFF")},
+		{ID: "APIC", Data: append([]byte("\x00image/jpeg\x00\x03\x00"), 0xFF, 0xD8, 0xFF, 0xE0, 0xFF)},
+	}
+
+	for _, version := range []uint8{3, 4} {
+		t.Run(fmt.Sprintf("ID3v2.%d", version), func(t *testing.T) {
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.Unsync = true
+
+			if err := e.Encode(&Tag{Version: version, Frames: frames, PaddingSize: 16}); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			data := buf.Bytes()
+
+			if size := decodeTagSize(data[6:10]); size != len(data)-lenOfHeader {
+				t.Errorf("Encode() declared a size of %d, want %d", size, len(data)-lenOfHeader)
+			}
+
+			body := data[lenOfHeader:]
+
+			for i := 0; i+1 < len(body); i++ {
+				if body[i] == 0xFF && body[i+1] >= 0xE0 {
+					t.Errorf("Encode() wrote a false sync %x at %d", body[i:i+2], lenOfHeader+i)
+				}
+			}
+
+			d := NewDecoder(bytes.NewReader(data))
+			tag, err := d.Decode()
+
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if d.InputOffset() != len(data) {
+				t.Errorf("Decode() read %d bytes, want %d", d.InputOffset(), len(data))
+			}
+
+			if tag.PaddingSize != 16 {
+				t.Errorf("Decode() PaddingSize = %d, want 16", tag.PaddingSize)
+			}
+
+			if len(tag.Frames) != len(frames) {
+				t.Fatalf("Decode() got %d frames, want %d", len(tag.Frames), len(frames))
+			}
+
+			for i := range frames {
+				if !tag.Frames[i].Equal(frames[i], CompareOptions{IgnoreFlags: true}) {
+					t.Errorf("Decode() frame %s = %x, want %x", frames[i].ID, tag.Frames[i].Data, frames[i].Data)
+				}
+			}
+		})
+	}
+}
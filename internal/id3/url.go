@@ -0,0 +1,80 @@
+package id3
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL returns the URL of a W-frame, e.g. WOAF, WOAR or WCOM, whose data is
+// an ISO-8859-1 URL without encoding byte, or of a WXXX frame, where it
+// follows an encoding byte and a description. It's trimmed of surrounding
+// spaces and of a terminator, which some taggers add.
+func (frame *Frame) URL() (string, error) {
+	if frame.ID[0] != 'W' {
+		return "", fmt.Errorf("URL(): Frame %q is not a URL frame", frame.ID)
+	}
+
+	data := frame.Data
+
+	if frameID(frame.ID) == "WXXX" {
+		if len(data) == 0 {
+			return "", errors.New("URL(): WXXX frame is empty")
+		}
+
+		width, ok := textEncodingWidth(data[0])
+
+		if !ok {
+			return "", fmt.Errorf("URL(): WXXX frame has an invalid text encoding flag %X", data[0])
+		}
+
+		end := descriptionEnd(data[1:], width)
+
+		if end < 0 {
+			return "", errors.New("URL(): WXXX description is not terminated")
+		}
+
+		data = data[1+end+width:]
+	}
+
+	return strings.TrimSpace(decodeLatin1Text(data)), nil
+}
+
+// URLParsed is URL, parsed. Returns error when the frame has no URL, or it's
+// not an absolute URL, e.g. "www.example.com" or garbage, as many are.
+func (frame *Frame) URLParsed() (*url.URL, error) {
+	str, err := frame.URL()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if str == "" {
+		return nil, fmt.Errorf("URLParsed(): Frame %q has no URL", frame.ID)
+	}
+
+	u, err := url.Parse(str)
+
+	if err != nil {
+		return nil, fmt.Errorf("URLParsed(): Frame %q: %w", frame.ID, err)
+	}
+
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("URLParsed(): Frame %q has %q, which is not an absolute URL", frame.ID, str)
+	}
+
+	return u, nil
+}
+
+// descriptionEnd returns the position of the terminator of the text at the
+// start of data, made of units of width bytes, or -1.
+func descriptionEnd(data []byte, width int) int {
+	for i := 0; i+width <= len(data); i += width {
+		if isFill(data[i:i+width], 0x00) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,42 @@
+package id3
+
+import (
+	"testing"
+)
+
+func TestFrame_URLParsed(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   Frame
+		wantURL string
+		want    string
+		wantErr bool
+	}{
+		{"WOAR", Frame{ID: "WOAR", Data: []byte("http://example.com/artist")}, "http://example.com/artist", "http://example.com/artist", false},
+		{"terminated and spaced", Frame{ID: "WCOM", Data: []byte(" https://example.com/buy \x00")}, "https://example.com/buy", "https://example.com/buy", false},
+		{"WXXX Latin-1", Frame{ID: "WXXX", Data: []byte("\x00Homepage\x00https://example.com/")}, "https://example.com/", "https://example.com/", false},
+		{"WXXX UTF-16", Frame{ID: "WXXX", Data: []byte("\x01\xFF\xFEH\x00\x00\x00https://example.com/")}, "https://example.com/", "https://example.com/", false},
+		{"not absolute", Frame{ID: "WOAF", Data: []byte("www.example.com")}, "www.example.com", "", true},
+		{"malformed", Frame{ID: "WOAF", Data: []byte("http://[::1")}, "http://[::1", "", true},
+		{"empty", Frame{ID: "WOAF"}, "", "", true},
+		{"WXXX unterminated", Frame{ID: "WXXX", Data: []byte("\x00Homepage")}, "", "", true},
+		{"WXXX bad encoding", Frame{ID: "WXXX", Data: []byte("\x09a\x00http://example.com/")}, "", "", true},
+		{"not a URL frame", Frame{ID: "TIT2", Data: []byte("\x00http://example.com/")}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := tt.frame.URL(); got != tt.wantURL {
+				t.Errorf("URL() = %q, want %q", got, tt.wantURL)
+			}
+
+			got, err := tt.frame.URLParsed()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("URLParsed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && got.String() != tt.want {
+				t.Errorf("URLParsed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package id3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LenOfV1 is the fixed length of an ID3v1 tag, found at the end of a file.
+const LenOfV1 = 128
+
+var id3v1Flag = []byte("TAG") // first 3 bytes of an ID3v1 tag
+
+// lenOfV1Text is the width of the text fields of an ID3v1 tag, which are cut
+// to it.
+const lenOfV1Text = 30
+
+// V1Tag is an ID3v1 tag, or ID3v1.1 when it has a track number. Text fields
+// are Latin-1, with trailing NULs and spaces trimmed.
+type V1Tag struct {
+	Title   string
+	Artist  string
+	Album   string
+	Year    string
+	Comment string
+	Track   int  // 0 when absent, as in ID3v1.0
+	Genre   byte // 255 when unknown
+}
+
+// ParseV1 parses the 128 bytes of an ID3v1 tag.
+func ParseV1(data []byte) (*V1Tag, error) {
+	if len(data) < LenOfV1 || !bytes.HasPrefix(data, id3v1Flag) {
+		return nil, errors.New("invalid ID3v1 tag")
+	}
+
+	text := func(b []byte) string {
+		return strings.TrimRight(decodeLatin1Text(b), " ")
+	}
+
+	tag := &V1Tag{
+		Title:   text(data[3:33]),
+		Artist:  text(data[33:63]),
+		Album:   text(data[63:93]),
+		Year:    text(data[93:97]),
+		Comment: text(data[97:127]),
+		Genre:   data[127],
+	}
+
+	// ID3v1.1 takes the last 2 bytes of the comment for a NUL and the track
+	if data[125] == 0 && data[126] != 0 {
+		tag.Comment = text(data[97:125])
+		tag.Track = int(data[126])
+	}
+
+	return tag, nil
+}
+
+// Conflict is a field on which the ID3v2 and ID3v1 tags of a file disagree.
+type Conflict struct {
+	Field string // e.g. "title"
+	ID    string // of the ID3v2 frame, e.g. "TIT2"
+	V2    string
+	V1    string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: %q in %s, %q in ID3v1", c.Field, c.V2, c.ID, c.V1)
+}
+
+// ReconcileV1 compares the fields of v1 to those of t, and returns the ones
+// that differ, in the order of the ID3v1 tag.
+//
+// Fields missing from either tag are not conflicts, as ID3v1 fields are often
+// left empty. Neither are ID3v2 values longer than an ID3v1 field can hold,
+// when the ID3v1 value is cut from them.
+func (t *Tag) ReconcileV1(v1 *V1Tag) []Conflict {
+	var conflicts []Conflict
+
+	compare := func(field, id, v2Value, v1Value string, width int) {
+		if v2Value == "" || v1Value == "" || v2Value == v1Value {
+			return
+		}
+
+		if len(v1Value) >= width && strings.HasPrefix(v2Value, v1Value) {
+			return
+		}
+
+		conflicts = append(conflicts, Conflict{Field: field, ID: id, V2: v2Value, V1: v1Value})
+	}
+
+	compare("title", "TIT2", t.frameText("TIT2"), v1.Title, lenOfV1Text)
+	compare("artist", "TPE1", t.frameText("TPE1"), v1.Artist, lenOfV1Text)
+	compare("album", "TALB", t.frameText("TALB"), v1.Album, lenOfV1Text)
+
+	yearID, year := "TYER", t.frameText("TYER")
+
+	if year == "" {
+		// ID3v2.4 has a timestamp instead, starting with the year
+		yearID, year = "TDRC", t.frameText("TDRC")
+	}
+
+	compare("year", yearID, year, v1.Year, 4)
+	compare("comment", "COMM", t.comment(), v1.Comment, 28)
+
+	if v1.Track > 0 {
+		if track, _, ok := t.TrackNumber(); ok {
+			compare("track", "TRCK", strconv.Itoa(track), strconv.Itoa(v1.Track), 3)
+		}
+	}
+
+	return conflicts
+}
+
+// frameText returns the trimmed text of the first frame with the given ID, or
+// "" if there's none or it can't be decoded.
+func (t *Tag) frameText(id string) string {
+	frame := t.findFrame(id)
+
+	if frame == nil {
+		return ""
+	}
+
+	text, err := frame.Text()
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(text)
+}
+
+// comment returns the text of the first COMM frame, or "" if there's none or
+// it can't be decoded.
+func (t *Tag) comment() string {
+	frame := t.findFrame("COMM")
+
+	// encoding, language, description
+	if frame == nil || len(frame.Data) < 4 {
+		return ""
+	}
+
+	width, ok := textEncodingWidth(frame.Data[0])
+
+	if !ok {
+		return ""
+	}
+
+	end := descriptionEnd(frame.Data[4:], width)
+
+	if end < 0 {
+		return ""
+	}
+
+	text := frame.Data[4+end+width:]
+	text = text[:len(text)-textFill(text, width)]
+
+	value, err := decodeTextValue(text, frame.Data[0], frame.strictBOM)
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(value)
+}
+
+// ToMap flattens the tag like Tag.ToMap.
+func (v1 *V1Tag) ToMap() map[string]string {
+	m := make(map[string]string)
+
+	set := func(key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+
+	set(KeyTitle, v1.Title)
+	set(KeyArtist, v1.Artist)
+	set(KeyAlbum, v1.Album)
+	set(KeyYear, v1.Year)
+	set(KeyComment, v1.Comment)
+	set(KeyGenre, GenreName(v1.Genre))
+
+	if v1.Track > 0 {
+		m[KeyTrack] = strconv.Itoa(v1.Track)
+	}
+
+	return m
+}
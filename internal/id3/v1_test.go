@@ -0,0 +1,132 @@
+package id3
+
+import (
+	"reflect"
+	"testing"
+)
+
+// generateV1 returns an ID3v1 tag of the given fields, ID3v1.1 when track > 0.
+func generateV1(title, artist, album, year, comment string, track int, genre byte) []byte {
+	data := make([]byte, LenOfV1)
+	copy(data, "TAG")
+	copy(data[3:33], title)
+	copy(data[33:63], artist)
+	copy(data[63:93], album)
+	copy(data[93:97], year)
+	copy(data[97:127], comment)
+
+	if track > 0 {
+		data[125], data[126] = 0, byte(track)
+	}
+
+	data[127] = genre
+
+	return data
+}
+
+func TestParseV1(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    *V1Tag
+		wantErr bool
+	}{
+		{
+			name: "ID3v1.1",
+			data: generateV1("Title", "Artist", "Album", "1999", "Comment", 7, 17),
+			want: &V1Tag{Title: "Title", Artist: "Artist", Album: "Album", Year: "1999", Comment: "Comment", Track: 7, Genre: 17},
+		},
+		{
+			name: "ID3v1.0, padded with spaces",
+			data: generateV1("Title                         ", "Artist", "", "", "A comment of thirty characters", 0, 255),
+			want: &V1Tag{Title: "Title", Artist: "Artist", Comment: "A comment of thirty characters", Genre: 255},
+		},
+		{
+			name:    "no magic",
+			data:    make([]byte, LenOfV1),
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			data:    generateV1("Title", "", "", "", "", 0, 0)[:100],
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseV1(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseV1() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseV1() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTag_ReconcileV1(t *testing.T) {
+	longTitle := "A title longer than thirty characters"
+
+	tag := &Tag{Frames: []Frame{
+		textFrame("TIT2", longTitle),
+		textFrame("TPE1", "New Artist"),
+		textFrame("TALB", "Album"),
+		textFrame("TDRC", "2001-05-04"),
+		textFrame("TRCK", "3/12"),
+		{ID: "COMM", Data: []byte("\x00engdesc\x00Ripped by me\x00")},
+	}}
+
+	tests := []struct {
+		name string
+		v1   *V1Tag
+		want []Conflict
+	}{
+		{
+			name: "agreeing, title cut",
+			v1:   &V1Tag{Title: longTitle[:30], Artist: "New Artist", Album: "Album", Year: "2001", Comment: "Ripped by me", Track: 3},
+		},
+		{
+			name: "empty ID3v1 fields",
+			v1:   &V1Tag{Genre: 255},
+		},
+		{
+			name: "drifted",
+			v1:   &V1Tag{Title: "Old title", Artist: "Old Artist", Album: "Album", Year: "1999", Comment: "Ripped by you", Track: 4},
+			want: []Conflict{
+				{Field: "title", ID: "TIT2", V2: longTitle, V1: "Old title"},
+				{Field: "artist", ID: "TPE1", V2: "New Artist", V1: "Old Artist"},
+				{Field: "year", ID: "TDRC", V2: "2001-05-04", V1: "1999"},
+				{Field: "comment", ID: "COMM", V2: "Ripped by me", V1: "Ripped by you"},
+				{Field: "track", ID: "TRCK", V2: "3", V1: "4"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tag.ReconcileV1(tt.v1); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReconcileV1() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenreName(t *testing.T) {
+	tests := []struct {
+		genre byte
+		want  string
+	}{
+		{0, "Blues"},
+		{17, "Rock"},
+		{79, "Hard Rock"},
+		{80, "Folk"},
+		{147, "Synthpop"},
+		{148, ""},
+		{255, ""},
+	}
+	for _, tt := range tests {
+		if got := GenreName(tt.genre); got != tt.want {
+			t.Errorf("GenreName(%d) = %q, want %q", tt.genre, got, tt.want)
+		}
+	}
+}
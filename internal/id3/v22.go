@@ -0,0 +1,110 @@
+package id3
+
+import "errors"
+
+// lenOfV22FrameHeader is the length of the frame headers of ID3v2.2: an ID
+// and a size of three bytes each, without flags.
+const lenOfV22FrameHeader = 6
+
+// flagV22Compression is set in the header of ID3v2.2 tags whose frames are
+// compressed, in a way the spec never defined. It's the bit of the extended
+// header in later versions.
+const flagV22Compression = 0x40
+
+var errCompressedV22 = errors.New("compressed ID3v2.2 tag is not supported")
+
+// v22FrameIDs maps the three-character IDs of ID3v2.2 frames to the IDs of
+// their ID3v2.3 counterparts of the same layout. PIC and LNK are left out:
+// their layouts differ from those of APIC and LINK.
+var v22FrameIDs = map[string]string{
+	"BUF": "RBUF",
+	"CNT": "PCNT",
+	"COM": "COMM",
+	"CRA": "AENC",
+	"ETC": "ETCO",
+	"GEO": "GEOB",
+	"IPL": "IPLS",
+	"MCI": "MCDI",
+	"MLL": "MLLT",
+	"POP": "POPM",
+	"REV": "RVRB",
+	"RVA": "RVAD",
+	"SLT": "SYLT",
+	"STC": "SYTC",
+	"TAL": "TALB",
+	"TBP": "TBPM",
+	"TCM": "TCOM",
+	"TCO": "TCON",
+	"TCR": "TCOP",
+	"TDA": "TDAT",
+	"TDY": "TDLY",
+	"TEN": "TENC",
+	"TFT": "TFLT",
+	"TIM": "TIME",
+	"TKE": "TKEY",
+	"TLA": "TLAN",
+	"TLE": "TLEN",
+	"TMT": "TMED",
+	"TOA": "TOPE",
+	"TOF": "TOFN",
+	"TOL": "TOLY",
+	"TOR": "TORY",
+	"TOT": "TOAL",
+	"TP1": "TPE1",
+	"TP2": "TPE2",
+	"TP3": "TPE3",
+	"TP4": "TPE4",
+	"TPA": "TPOS",
+	"TPB": "TPUB",
+	"TRC": "TSRC",
+	"TRD": "TRDA",
+	"TRK": "TRCK",
+	"TSI": "TSIZ",
+	"TSS": "TSSE",
+	"TT1": "TIT1",
+	"TT2": "TIT2",
+	"TT3": "TIT3",
+	"TXT": "TEXT",
+	"TXX": "TXXX",
+	"TYE": "TYER",
+	"UFI": "UFID",
+	"ULT": "USLT",
+	"WAF": "WOAF",
+	"WAR": "WOAR",
+	"WAS": "WOAS",
+	"WCM": "WCOM",
+	"WCP": "WCOP",
+	"WPB": "WPUB",
+	"WXX": "WXXX",
+}
+
+// frameID returns the ID3v2.3 ID of a frame of the given ID, the ID itself
+// unless it's an ID3v2.2 one in v22FrameIDs. Frames are looked up by it, so
+// that e.g. the TT2 frame of an ID3v2.2 tag is the title.
+func frameID(id string) string {
+	if v23, ok := v22FrameIDs[id]; ok {
+		return v23
+	}
+
+	return id
+}
+
+// lenOfFrameID returns the length of the frame IDs in a tag of the given
+// major version.
+func lenOfFrameID(version uint8) int {
+	if version == 2 {
+		return 3
+	}
+
+	return 4
+}
+
+// lenOfFrameHeader returns the length of the frame headers in a tag of the
+// given major version.
+func lenOfFrameHeader(version uint8) int {
+	if version == 2 {
+		return lenOfV22FrameHeader
+	}
+
+	return lenOfHeader
+}
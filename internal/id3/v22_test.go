@@ -0,0 +1,95 @@
+package id3
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// v22Tag returns an ID3v2.2 tag of a TT2 and a TAL frame, and 8 bytes of
+// padding.
+func v22Tag(flags byte) []byte {
+	frames := []byte("TT2\x00\x00\x06\x00Title" + "TAL\x00\x00\x06\x00Album")
+	tag := append([]byte{'I', 'D', '3', 2, 0, flags}, encodeTagSize(len(frames)+8)...)
+	tag = append(tag, frames...)
+
+	return append(tag, make([]byte, 8)...)
+}
+
+func TestDecoder_Decode_V22(t *testing.T) {
+	input := v22Tag(0)
+	tag, err := NewDecoder(bytes.NewReader(input)).Decode()
+
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	want := []Frame{
+		{ID: "TT2", Data: []byte("\x00Title"), Size: 6, Offset: 10},
+		{ID: "TAL", Data: []byte("\x00Album"), Size: 6, Offset: 22},
+	}
+
+	if !reflect.DeepEqual(tag.Frames, want) {
+		t.Errorf("Frames = %+v, want %+v", tag.Frames, want)
+	}
+
+	if tag.PaddingSize != 8 {
+		t.Errorf("PaddingSize = %v, want 8", tag.PaddingSize)
+	}
+
+	wantMap := map[string]string{KeyTitle: "Title", KeyAlbum: "Album"}
+
+	if got := tag.ToMap(); !reflect.DeepEqual(got, wantMap) {
+		t.Errorf("ToMap() = %v, want %v", got, wantMap)
+	}
+
+	if start, end := tag.Frames[1].ByteRange(0); start != 22 || end != 34 {
+		t.Errorf("ByteRange() = %v, %v, want 22, 34", start, end)
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(tag); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), input) {
+		t.Errorf("Encode() = % X, want % X", buf.Bytes(), input)
+	}
+}
+
+func TestDecoder_Decode_V22Compressed(t *testing.T) {
+	_, err := NewDecoder(bytes.NewReader(v22Tag(flagV22Compression))).Decode()
+
+	if err != errCompressedV22 {
+		t.Errorf("Decode() error = %v, want %v", err, errCompressedV22)
+	}
+}
+
+func TestSkipReader_V22(t *testing.T) {
+	input := v22Tag(0)
+	var got []string
+
+	s := NewSkipReader(bytes.NewReader(input))
+	s.OnFrame = func(id string, size int) {
+		got = append(got, id)
+	}
+
+	n, err := s.ReadThrough()
+
+	if err != nil || n != len(input) {
+		t.Fatalf("ReadThrough() = %v, %v, want %v, nil", n, err, len(input))
+	}
+
+	if want := []string{"TT2", "TAL"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OnFrame() called with %v, want %v", got, want)
+	}
+}
+
+func Test_frameID(t *testing.T) {
+	for id, want := range map[string]string{"TT2": "TIT2", "COM": "COMM", "PIC": "PIC", "TIT2": "TIT2"} {
+		if got := frameID(id); got != want {
+			t.Errorf("frameID(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
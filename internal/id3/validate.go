@@ -0,0 +1,46 @@
+package id3
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Validate checks the tag against the ID3v2 spec and returns a description of
+// each problem found, or nil. Problems reported here don't keep the tag from
+// being decoded.
+func (t *Tag) Validate() []string {
+	var warnings []string
+
+	// The spec reserves 0xFF for both version bytes.
+	if t.Revision == 0xFF {
+		warnings = append(warnings, fmt.Sprintf("revision %#02x is invalid", t.Revision))
+	}
+
+	if t.CRCValid != nil && !*t.CRCValid {
+		warnings = append(warnings, fmt.Sprintf("frames don't match the CRC %08X of the extended header", t.CRC))
+	}
+
+	for i := range t.Frames {
+		if frame := &t.Frames[i]; frame.hasText() && missesBOM(frame.Data) {
+			warnings = append(warnings, fmt.Sprintf("%s: UTF-16 text without BOM, byte order guessed", frame.ID))
+		}
+	}
+
+	return warnings
+}
+
+// missesBOM tells whether data, the payload of a text frame, is UTF-16 text
+// without BOM. An empty text, i.e. the terminator only, needs none.
+func missesBOM(data []byte) bool {
+	if len(data) < 3 || data[0] != 0x01 {
+		return false
+	}
+
+	bom := data[1:3]
+
+	if isFill(bom, 0x00) {
+		return false
+	}
+
+	return !bytes.Equal(bom, []byte{0xFE, 0xFF}) && !bytes.Equal(bom, []byte{0xFF, 0xFE})
+}
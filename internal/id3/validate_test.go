@@ -0,0 +1,45 @@
+package id3
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTag_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   []string
+	}{
+		{"revision 0", []byte("ID3\x03\x00\x00\x00\x00\x00\x00"), nil},
+		{"revision 1", []byte("ID3\x04\x01\x00\x00\x00\x00\x00"), nil},
+		{"revision 0xFF", []byte("ID3\x03\xFF\x00\x00\x00\x00\x00"), []string{"revision 0xff is invalid"}},
+	}
+	withoutBOM := generateDataFrame("TIT2", []byte("\x01H\x00i\x00\x00\x00"), 0)
+	withBOM := generateDataFrame("TPE1", []byte("\x01\xFF\xFEH\x00\x00\x00"), 0)
+	frames := append(withoutBOM, withBOM...)
+
+	tests = append(tests, struct {
+		name   string
+		header []byte
+		want   []string
+	}{
+		"UTF-16 without BOM",
+		append(append([]byte("ID3\x03\x00\x00"), encodeTagSize(len(frames))...), frames...),
+		[]string{"TIT2: UTF-16 text without BOM, byte order guessed"},
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, err := NewDecoder(bytes.NewReader(tt.header)).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if got := tag.Validate(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tag.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
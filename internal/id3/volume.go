@@ -0,0 +1,206 @@
+package id3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ChannelType identifies the channel a volume adjustment applies to, as
+// defined for RVA2 frames.
+type ChannelType uint8
+
+const (
+	ChannelOther ChannelType = iota
+	ChannelMaster
+	ChannelFrontRight
+	ChannelFrontLeft
+	ChannelBackRight
+	ChannelBackLeft
+	ChannelFrontCentre
+	ChannelBackCentre
+	ChannelSubwoofer
+)
+
+var errTruncatedVolume = errors.New("volume adjustment frame is truncated")
+
+// ChannelAdjust is the volume adjustment of one channel.
+type ChannelAdjust struct {
+	Type ChannelType
+
+	// Adjustment is the signed volume change. In RVA2 frames it's in units of
+	// 1/512 dB, see Decibels. In RVAD frames the unit is not specified.
+	Adjustment int32
+
+	// Peak is the peak volume, PeakBits wide. PeakBits is 0 when absent.
+	Peak     uint64
+	PeakBits int
+}
+
+// Decibels returns Adjustment in dB, assuming the RVA2 unit of 1/512 dB.
+func (c *ChannelAdjust) Decibels() float64 {
+	return float64(c.Adjustment) / 512
+}
+
+// VolumeAdjust holds the content of an RVA2 (ID3v2.4) or RVAD (ID3v2.3)
+// frame.
+type VolumeAdjust struct {
+	// Identification tells the situation the adjustment is for, e.g. "album".
+	// Always empty for RVAD.
+	Identification string
+	Channels       []ChannelAdjust
+}
+
+// VolumeAdjustment parses the frame data as a relative volume adjustment.
+// Returns error when the frame is neither RVA2 nor RVAD, or is malformed.
+func (frame *Frame) VolumeAdjustment() (*VolumeAdjust, error) {
+	switch frame.ID {
+	case "RVA2":
+		return parseRVA2(frame.Data)
+	case "RVAD":
+		return parseRVAD(frame.Data)
+	default:
+		return nil, fmt.Errorf("VolumeAdjustment(): Frame %q is not a volume adjustment frame", frame.ID)
+	}
+}
+
+// parseRVA2 parses
+//
+//	Identification          <text string> $00
+//	Type of channel         $xx
+//	Volume adjustment       $xx xx
+//	Bits representing peak  $xx
+//	Peak volume             $xx (xx ...)
+//
+// where all but the identification repeat for each channel.
+func parseRVA2(data []byte) (*VolumeAdjust, error) {
+	end := bytes.IndexByte(data, 0x00)
+
+	if end < 0 {
+		return nil, errors.New("RVA2 identification is not terminated")
+	}
+
+	adjust := &VolumeAdjust{Identification: decodeLatin1Text(data[:end])}
+	data = data[end+1:]
+
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, errTruncatedVolume
+		}
+
+		channel := ChannelAdjust{
+			Type:       ChannelType(data[0]),
+			Adjustment: int32(int16(uint16(data[1])<<8 | uint16(data[2]))),
+			PeakBits:   int(data[3]),
+		}
+
+		peak, rest, err := readVolumeField(data[4:], channel.PeakBits)
+
+		if err != nil {
+			return nil, err
+		}
+
+		channel.Peak = peak
+		adjust.Channels = append(adjust.Channels, channel)
+		data = rest
+	}
+
+	return adjust, nil
+}
+
+// rvadChannels lists the channels of an RVAD frame in the order they're
+// stored, with the bit of the increment flag for each.
+var rvadChannels = []struct {
+	typ ChannelType
+	bit byte
+}{
+	{ChannelFrontRight, 1 << 0},
+	{ChannelFrontLeft, 1 << 1},
+	{ChannelBackRight, 1 << 2},
+	{ChannelBackLeft, 1 << 3},
+	{ChannelFrontCentre, 1 << 4},
+	{ChannelSubwoofer, 1 << 5},
+}
+
+// parseRVAD parses
+//
+//	Increment/decrement           %00xxxxxx
+//	Bits used for volume descr.   $xx
+//	Relative volume change, right $xx xx (xx ...)
+//	Relative volume change, left  $xx xx (xx ...)
+//	Peak volume right             $xx xx (xx ...)
+//	Peak volume left              $xx xx (xx ...)
+//
+// optionally followed by the same for back right and left, then centre, then
+// bass, each group with its own peaks.
+func parseRVAD(data []byte) (*VolumeAdjust, error) {
+	if len(data) < 2 {
+		return nil, errTruncatedVolume
+	}
+
+	increment := data[0]
+	bits := int(data[1])
+
+	if bits == 0 || bits > 32 {
+		return nil, fmt.Errorf("RVAD: unsupported volume description width of %d bits", bits)
+	}
+
+	data = data[2:]
+	adjust := new(VolumeAdjust)
+
+	// right & left, back right & left, centre, bass
+	for _, group := range [][]int{{0, 1}, {2, 3}, {4}, {5}} {
+		if len(data) == 0 && len(adjust.Channels) > 0 {
+			break
+		}
+
+		values := make([]uint64, 2*len(group))
+
+		for i := range values {
+			var err error
+
+			if values[i], data, err = readVolumeField(data, bits); err != nil {
+				return nil, err
+			}
+		}
+
+		for i, index := range group {
+			channel := ChannelAdjust{
+				Type:       rvadChannels[index].typ,
+				Adjustment: int32(values[i]),
+				Peak:       values[len(group)+i],
+				PeakBits:   bits,
+			}
+
+			if increment&rvadChannels[index].bit == 0 {
+				channel.Adjustment = -channel.Adjustment
+			}
+
+			adjust.Channels = append(adjust.Channels, channel)
+		}
+	}
+
+	return adjust, nil
+}
+
+// readVolumeField reads a big-endian unsigned integer of bits bits, stored in
+// as many whole bytes as needed, and returns it with the rest of data.
+func readVolumeField(data []byte, bits int) (uint64, []byte, error) {
+	if bits > 64 {
+		return 0, nil, fmt.Errorf("unsupported volume field width of %d bits", bits)
+	}
+
+	n := (bits + 7) / 8
+
+	if len(data) < n {
+		return 0, nil, errTruncatedVolume
+	}
+
+	var value uint64
+
+	for _, b := range data[:n] {
+		value = value<<8 | uint64(b)
+	}
+
+	return value, data[n:], nil
+}
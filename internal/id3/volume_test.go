@@ -0,0 +1,114 @@
+package id3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrame_VolumeAdjustment(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   Frame
+		want    *VolumeAdjust
+		wantErr bool
+	}{
+		{
+			name: "RVA2 master and subwoofer",
+			frame: Frame{ID: "RVA2", Data: []byte{
+				'a', 'l', 'b', 'u', 'm', 0x00,
+				0x01, 0xFC, 0x00, 0x10, 0x7F, 0xFF, // master, -2 dB, 16-bit peak
+				0x08, 0x02, 0x00, 0x00, // subwoofer, +1 dB, no peak
+			}},
+			want: &VolumeAdjust{
+				Identification: "album",
+				Channels: []ChannelAdjust{
+					{Type: ChannelMaster, Adjustment: -1024, Peak: 0x7FFF, PeakBits: 16},
+					{Type: ChannelSubwoofer, Adjustment: 512},
+				},
+			},
+		},
+		{
+			name:  "RVA2 without channels",
+			frame: Frame{ID: "RVA2", Data: []byte{'t', 'r', 'a', 'c', 'k', 0x00}},
+			want:  &VolumeAdjust{Identification: "track"},
+		},
+		{
+			name:    "RVA2 unterminated identification",
+			frame:   Frame{ID: "RVA2", Data: []byte("track")},
+			wantErr: true,
+		},
+		{
+			name:    "RVA2 truncated peak",
+			frame:   Frame{ID: "RVA2", Data: []byte{0x00, 0x01, 0x00, 0x00, 0x10, 0x7F}},
+			wantErr: true,
+		},
+		{
+			name: "RVAD right and left",
+			frame: Frame{ID: "RVAD", Data: []byte{
+				0b01, 16, // right up, left down
+				0x00, 0x10, 0x00, 0x20, // changes
+				0x7F, 0x00, 0x6F, 0x00, // peaks
+			}},
+			want: &VolumeAdjust{
+				Channels: []ChannelAdjust{
+					{Type: ChannelFrontRight, Adjustment: 0x10, Peak: 0x7F00, PeakBits: 16},
+					{Type: ChannelFrontLeft, Adjustment: -0x20, Peak: 0x6F00, PeakBits: 16},
+				},
+			},
+		},
+		{
+			name: "RVAD with centre",
+			frame: Frame{ID: "RVAD", Data: []byte{
+				0b10011, 8,
+				1, 2, 3, 4,
+				5, 6, 7, 8,
+				9, 10,
+			}},
+			want: &VolumeAdjust{
+				Channels: []ChannelAdjust{
+					{Type: ChannelFrontRight, Adjustment: 1, Peak: 3, PeakBits: 8},
+					{Type: ChannelFrontLeft, Adjustment: 2, Peak: 4, PeakBits: 8},
+					{Type: ChannelBackRight, Adjustment: -5, Peak: 7, PeakBits: 8},
+					{Type: ChannelBackLeft, Adjustment: -6, Peak: 8, PeakBits: 8},
+					{Type: ChannelFrontCentre, Adjustment: 9, Peak: 10, PeakBits: 8},
+				},
+			},
+		},
+		{
+			name:    "RVAD zero bits",
+			frame:   Frame{ID: "RVAD", Data: []byte{0x00, 0x00}},
+			wantErr: true,
+		},
+		{
+			name:    "RVAD truncated",
+			frame:   Frame{ID: "RVAD", Data: []byte{0x00, 16, 0x00, 0x10}},
+			wantErr: true,
+		},
+		{
+			name:    "not a volume frame",
+			frame:   Frame{ID: "TIT2", Data: []byte{0x00}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.frame.VolumeAdjustment()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Frame.VolumeAdjustment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Frame.VolumeAdjustment() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelAdjust_Decibels(t *testing.T) {
+	c := ChannelAdjust{Adjustment: -1024}
+
+	if got := c.Decibels(); got != -2 {
+		t.Errorf("ChannelAdjust.Decibels() = %v, want %v", got, -2)
+	}
+}
@@ -0,0 +1,86 @@
+package mp3header
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// lameTagSize is the size of the LAME extension of a Xing header.
+const lameTagSize = 36
+
+// Name codes of the replay gain fields of a LAME tag
+const (
+	lameGainRadio      = 1 // i.e. track gain
+	lameGainAudiophile = 2 // i.e. album gain
+)
+
+// LAMETag is the extension written by LAME, and encoders mimicking it, after
+// the fields of a Xing header.
+type LAMETag struct {
+	Encoder string // e.g. "LAME3.100"
+
+	// Peak is the peak signal amplitude, 1 being full scale, or 0 if unknown.
+	Peak float64
+
+	// TrackGain and AlbumGain are the replay gains in dB, when HasTrackGain
+	// and HasAlbumGain.
+	TrackGain    float64
+	AlbumGain    float64
+	HasTrackGain bool
+	HasAlbumGain bool
+
+	// EncoderDelay and EncoderPadding are the samples added by the encoder at
+	// the start and at the end of the audio.
+	EncoderDelay   int
+	EncoderPadding int
+}
+
+// parseLAME parses the LAME tag at the start of data, right after the fields
+// of a Xing header. Returns nil if there's none.
+func parseLAME(data []byte) *LAMETag {
+	if len(data) < lameTagSize || !isLAMEEncoder(data[:4]) {
+		return nil
+	}
+
+	l := &LAMETag{
+		Encoder: strings.TrimRight(string(data[:9]), "\x00 "),
+		Peak:    float64(binary.BigEndian.Uint32(data[11:15])) / (1 << 23),
+	}
+
+	l.TrackGain, l.HasTrackGain = parseLAMEGain(binary.BigEndian.Uint16(data[15:17]), lameGainRadio)
+	l.AlbumGain, l.HasAlbumGain = parseLAMEGain(binary.BigEndian.Uint16(data[17:19]), lameGainAudiophile)
+
+	// 12 bits each
+	delay := int(data[21])<<16 | int(data[22])<<8 | int(data[23])
+	l.EncoderDelay, l.EncoderPadding = delay>>12, delay&0xFFF
+
+	return l
+}
+
+// isLAMEEncoder tells whether the encoder string starts like one of those
+// writing a LAME tag.
+func isLAMEEncoder(prefix []byte) bool {
+	switch string(prefix) {
+	case "LAME", "L3.9", "Lavf", "Lavc":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseLAMEGain parses a replay gain field of a LAME tag: 3 bits of name code,
+// 3 of originator, a sign bit, and 9 bits of tenths of dB. It tells whether
+// the field is set, with the expected name code.
+func parseLAMEGain(field uint16, name uint16) (float64, bool) {
+	if field>>13 != name || (field>>10)&0x7 == 0 {
+		return 0, false
+	}
+
+	gain := float64(field&0x1FF) / 10
+
+	if field&0x200 != 0 {
+		gain = -gain
+	}
+
+	return gain, true
+}
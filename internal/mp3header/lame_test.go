@@ -0,0 +1,78 @@
+package mp3header
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// generateLAME returns a LAME tag of the given fields.
+func generateLAME(encoder string, peak uint32, trackGain, albumGain uint16, delay, padding int) []byte {
+	data := make([]byte, lameTagSize)
+	copy(data, encoder)
+	binary.BigEndian.PutUint32(data[11:], peak)
+	binary.BigEndian.PutUint16(data[15:], trackGain)
+	binary.BigEndian.PutUint16(data[17:], albumGain)
+	data[21], data[22], data[23] = byte(delay>>4), byte(delay<<4|padding>>8), byte(padding)
+
+	return data
+}
+
+func TestParseXing_LAME(t *testing.T) {
+	stereo := MP3Header{AudioVersion: Version1, Layer: Layer3, ChannelMode: ChannelModeJointStereo}
+
+	tests := []struct {
+		name string
+		lame []byte
+		want *LAMETag
+	}{
+		{
+			name: "with replay gain",
+			// radio -6.4 dB, audiophile +1.5 dB, both set by the user (originator 1)
+			lame: generateLAME("LAME3.100", 1<<22, 0x2400|0x200|64, 0x4400|15, 576, 1234),
+			want: &LAMETag{
+				Encoder:        "LAME3.100",
+				Peak:           0.5,
+				TrackGain:      -6.4,
+				AlbumGain:      1.5,
+				HasTrackGain:   true,
+				HasAlbumGain:   true,
+				EncoderDelay:   576,
+				EncoderPadding: 1234,
+			},
+		},
+		{
+			name: "without replay gain",
+			lame: generateLAME("Lavc58.54", 0, 0, 0, 1105, 0),
+			want: &LAMETag{Encoder: "Lavc58.54", EncoderDelay: 1105},
+		},
+		{
+			name: "gain fields swapped",
+			lame: generateLAME("LAME3.99r", 0, 0x4400|15, 0x2400|64, 0, 0),
+			want: &LAMETag{Encoder: "LAME3.99r"},
+		},
+		{
+			name: "other encoder",
+			lame: generateLAME("XYZ", 0, 0x2400|64, 0, 0, 0),
+		},
+		{
+			name: "truncated",
+			lame: generateLAME("LAME3.100", 0, 0x2400|64, 0, 0, 0)[:20],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append(generateXing(stereo, "Info", xingFlagFrames, 1000, 0, nil, 0), tt.lame...)
+
+			got, ok := ParseXing(data, stereo)
+
+			if !ok {
+				t.Fatalf("ParseXing() ok = false, want true")
+			}
+
+			if !reflect.DeepEqual(got.LAME, tt.want) {
+				t.Errorf("ParseXing() LAME = %+v, want %+v", got.LAME, tt.want)
+			}
+		})
+	}
+}
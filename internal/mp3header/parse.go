@@ -13,7 +13,7 @@ const (
 	// mpegFlagProtectionBit = 0b00000000_00000001_00000000_00000000
 	mpegFlagBitRate    = 0b00000000_00000000_11110000_00000000
 	mpegFlagSampleFreq = 0b00000000_00000000_00001100_00000000
-	// mpegFlagPaddingBit    = 0b00000000_00000000_00000010_00000000
+	mpegFlagPaddingBit = 0b00000000_00000000_00000010_00000000
 	// mpegFlagPrivateBit    = 0b00000000_00000000_00000001_00000000
 	mpegFlagChannelMode = 0b00000000_00000000_00000000_11000000
 	// mpegFlagModeExtension = 0b00000000_00000000_00000000_00110000
@@ -48,29 +48,125 @@ type MP3Header struct {
 	Layer        int
 	BitRate      int
 	SampleFreq   int
+	Padding      bool
 	ChannelMode  int
 }
 
+// SamplesPerFrame returns how many PCM samples (per channel) a frame decodes
+// to. Returns 0 for invalid layers.
+func (h *MP3Header) SamplesPerFrame() int {
+	switch h.Layer {
+	case Layer1:
+		return 384
+	case Layer2:
+		return 1152
+	case Layer3:
+		if h.AudioVersion == Version1 {
+			return 1152
+		}
+		return 576
+	default:
+		return 0
+	}
+}
+
+// FrameLength returns the length of the frame in bytes, including the 4-byte
+// header. Returns 0 when it can't be known, e.g. for free format streams.
+func (h *MP3Header) FrameLength() int {
+	if h.BitRate <= 0 || h.SampleFreq <= 0 {
+		return 0
+	}
+
+	padding := 0
+	if h.Padding {
+		padding = 1
+	}
+
+	if h.Layer == Layer1 {
+		// Layer I counts in 4-byte slots
+		return (12*h.BitRate*1000/h.SampleFreq + padding) * 4
+	}
+
+	return h.SamplesPerFrame()/8*h.BitRate*1000/h.SampleFreq + padding
+}
+
+// SideInfoSize returns the size in bytes of the side information following
+// the header of a Layer III frame, where a Xing or Info header would start.
+// Returns 0 for other layers, which have none.
+func (h *MP3Header) SideInfoSize() int {
+	if h.Layer != Layer3 {
+		return 0
+	}
+
+	mono := h.ChannelMode == ChannelModeMono
+
+	switch {
+	case h.AudioVersion == Version1 && mono:
+		return 17
+	case h.AudioVersion == Version1:
+		return 32
+	case mono:
+		return 9
+	default:
+		return 17
+	}
+}
+
 func (h *MP3Header) String() string {
 	return fmt.Sprintf(
 		"MPEG-%s Layer %s, %d kbps, %dHz",
-		[]string{"2.5", "?", "2", "1"}[h.AudioVersion],
-		[]string{"?", "III", "II", "I"}[h.Layer],
+		h.VersionName(),
+		h.LayerName(),
 		h.BitRate,
 		h.SampleFreq,
 	)
 }
 
+// VersionName returns the MPEG version as in "MPEG-2.5", i.e. "2.5".
+func (h *MP3Header) VersionName() string {
+	if h.AudioVersion < 0 || h.AudioVersion > 3 {
+		return "?"
+	}
+
+	return []string{"2.5", "?", "2", "1"}[h.AudioVersion]
+}
+
+// LayerName returns the layer in roman numerals, e.g. "III".
+func (h *MP3Header) LayerName() string {
+	if h.Layer < 0 || h.Layer > 3 {
+		return "?"
+	}
+
+	return []string{"?", "III", "II", "I"}[h.Layer]
+}
+
+// ChannelModeName returns a readable channel mode, e.g. "joint stereo".
+func (h *MP3Header) ChannelModeName() string {
+	if h.ChannelMode < 0 || h.ChannelMode > 3 {
+		return "?"
+	}
+
+	return []string{"stereo", "joint stereo", "dual mono", "mono"}[h.ChannelMode]
+}
+
+// IsMPEG1 tells whether the stream is MPEG-1 audio, as opposed to the MPEG-2
+// and MPEG-2.5 low sampling frequency extensions.
+func (h *MP3Header) IsMPEG1() bool {
+	return h.AudioVersion == Version1
+}
+
 type bitRateArray [16]int
 type bitRateLayerDict map[int]bitRateArray
 
+// Bit rates in kbps, by version, layer and index, as in ISO/IEC 11172-3 and
+// 13818-3.
 // 0 means free format
 // -1 means bad bit rate
 var bitRateTopDict = map[int]bitRateLayerDict{
 	Version1: {
-		Layer1: [16]int{0, 32, 64, 92, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, -1},
+		Layer1: [16]int{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, -1},
 		Layer2: [16]int{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, -1},
-		Layer3: [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 176, 192, 224, 256, -1},
+		Layer3: [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, -1},
 	},
 	// Version2 is for Version2_5 too
 	Version2: {
@@ -132,7 +228,7 @@ func Parse(headerBits uint32) (header MP3Header, err error) {
 		SampleFreq:   -1,
 	}
 
-	if headerBits&mpegFlagFrameSync == 0 {
+	if headerBits&mpegFlagFrameSync != mpegFlagFrameSync {
 		err = fmt.Errorf("MP3 frame sync not found (expecting %X, but found %X)", mpegFlagFrameSync, headerBits)
 		return
 	}
@@ -141,6 +237,7 @@ func Parse(headerBits uint32) (header MP3Header, err error) {
 	header.Layer = int((headerBits & mpegFlagLayerDesc) >> 17)
 	bitRateIndex := int((headerBits & mpegFlagBitRate) >> 12)
 	sampleFreqIndex := int((headerBits & mpegFlagSampleFreq) >> 10)
+	header.Padding = headerBits&mpegFlagPaddingBit != 0
 	header.ChannelMode = int((headerBits & mpegFlagChannelMode) >> 6)
 
 	bitRate, err := getBitRate(header.AudioVersion, header.Layer, bitRateIndex)
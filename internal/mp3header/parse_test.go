@@ -0,0 +1,166 @@
+package mp3header
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		headerBits uint32
+		want       MP3Header
+		wantErr    bool
+	}{
+		{
+			name:       "MPEG-1 Layer III 128 kbps",
+			headerBits: 0xFFFB9044,
+			want:       MP3Header{Version1, Layer3, 128, 44100, false, ChannelModeJointStereo},
+		},
+		{
+			name:       "MPEG-1 Layer III 320 kbps",
+			headerBits: 0xFFFBE444,
+			want:       MP3Header{Version1, Layer3, 320, 48000, false, ChannelModeJointStereo},
+		},
+		{
+			name:       "MPEG-1 Layer I 96 kbps",
+			headerBits: 0xFFFF3000,
+			want:       MP3Header{Version1, Layer1, 96, 44100, false, ChannelModeStereo},
+		},
+		{
+			name:       "MPEG-2 Layer III 8 kbps padded mono",
+			headerBits: 0xFFF312C4,
+			want:       MP3Header{Version2, Layer3, 8, 22050, true, ChannelModeMono},
+		},
+		{
+			name:       "Error: no frame sync",
+			headerBits: 0x54414700, // "TAG\x00"
+			wantErr:    true,
+		},
+		{
+			name:       "Error: partial frame sync",
+			headerBits: 0xFF0B9044,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.headerBits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMP3Header_SamplesPerFrame(t *testing.T) {
+	tests := []struct {
+		name   string
+		header MP3Header
+		want   int
+	}{
+		{"MPEG-1 Layer I", MP3Header{AudioVersion: Version1, Layer: Layer1}, 384},
+		{"MPEG-1 Layer II", MP3Header{AudioVersion: Version1, Layer: Layer2}, 1152},
+		{"MPEG-1 Layer III", MP3Header{AudioVersion: Version1, Layer: Layer3}, 1152},
+		{"MPEG-2 Layer III", MP3Header{AudioVersion: Version2, Layer: Layer3}, 576},
+		{"MPEG-2.5 Layer III", MP3Header{AudioVersion: Version2_5, Layer: Layer3}, 576},
+		{"Invalid layer", MP3Header{AudioVersion: Version1, Layer: LayerInvalid}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.header.SamplesPerFrame(); got != tt.want {
+				t.Errorf("SamplesPerFrame() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMP3Header_FrameLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		header MP3Header
+		want   int
+	}{
+		{"MPEG-1 Layer III 128 kbps", MP3Header{Version1, Layer3, 128, 44100, false, 0}, 417},
+		{"MPEG-1 Layer III 128 kbps padded", MP3Header{Version1, Layer3, 128, 44100, true, 0}, 418},
+		{"MPEG-1 Layer III 320 kbps 48kHz", MP3Header{Version1, Layer3, 320, 48000, false, 0}, 960},
+		{"MPEG-2 Layer III 64 kbps", MP3Header{Version2, Layer3, 64, 22050, false, 0}, 208},
+		{"MPEG-1 Layer I 32 kbps", MP3Header{Version1, Layer1, 32, 32000, true, 0}, 52},
+		{"MPEG-1 Layer II 192 kbps", MP3Header{Version1, Layer2, 192, 48000, false, 0}, 576},
+		{"Free format", MP3Header{Version1, Layer3, 0, 44100, false, 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.header.FrameLength(); got != tt.want {
+				t.Errorf("FrameLength() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMP3Header_SideInfoSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		header MP3Header
+		want   int
+	}{
+		{"MPEG-1 stereo", MP3Header{AudioVersion: Version1, Layer: Layer3, ChannelMode: ChannelModeStereo}, 32},
+		{"MPEG-1 joint stereo", MP3Header{AudioVersion: Version1, Layer: Layer3, ChannelMode: ChannelModeJointStereo}, 32},
+		{"MPEG-1 dual mono", MP3Header{AudioVersion: Version1, Layer: Layer3, ChannelMode: ChannelModeDualMono}, 32},
+		{"MPEG-1 mono", MP3Header{AudioVersion: Version1, Layer: Layer3, ChannelMode: ChannelModeMono}, 17},
+		{"MPEG-2 stereo", MP3Header{AudioVersion: Version2, Layer: Layer3, ChannelMode: ChannelModeStereo}, 17},
+		{"MPEG-2 mono", MP3Header{AudioVersion: Version2, Layer: Layer3, ChannelMode: ChannelModeMono}, 9},
+		{"MPEG-2.5 mono", MP3Header{AudioVersion: Version2_5, Layer: Layer3, ChannelMode: ChannelModeMono}, 9},
+		{"Layer II", MP3Header{AudioVersion: Version1, Layer: Layer2, ChannelMode: ChannelModeStereo}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.header.SideInfoSize(); got != tt.want {
+				t.Errorf("SideInfoSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMP3Header_IsMPEG1(t *testing.T) {
+	tests := []struct {
+		version int
+		want    bool
+	}{
+		{Version1, true},
+		{Version2, false},
+		{Version2_5, false},
+	}
+	for _, tt := range tests {
+		h := MP3Header{AudioVersion: tt.version, Layer: Layer3}
+
+		if got := h.IsMPEG1(); got != tt.want {
+			t.Errorf("MP3Header{AudioVersion: %02b}.IsMPEG1() = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+// The entries of ISO/IEC 11172-3 that the table once had wrong: 92 for 96
+// kbps in Layer I, and 176 to 256 for 192 to 320 kbps in Layer III.
+func Test_getBitRate_MPEG1(t *testing.T) {
+	tests := []struct {
+		layer int
+		index int
+		want  int
+	}{
+		{Layer1, 3, 96},
+		{Layer3, 11, 192},
+		{Layer3, 12, 224},
+		{Layer3, 13, 256},
+		{Layer3, 14, 320},
+	}
+	for _, tt := range tests {
+		got, err := getBitRate(Version1, tt.layer, tt.index)
+
+		if err != nil || got != tt.want {
+			t.Errorf("getBitRate(Version1, %02b, %d) = %v, %v, want %v, nil", tt.layer, tt.index, got, err, tt.want)
+		}
+	}
+}
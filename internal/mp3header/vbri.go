@@ -0,0 +1,77 @@
+package mp3header
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// VBRIOffset is where the VBRI header of Fraunhofer encoders starts in the
+// first frame, counted from the end of the frame header. Unlike the Xing
+// header, it doesn't depend on the size of the side info.
+const VBRIOffset = 32
+
+// lenOfVBRI is the size of the fields of a VBRI header before its TOC.
+const lenOfVBRI = 26
+
+// VBRIHeader is the header written by Fraunhofer encoders in place of the
+// audio of the first frame, like XingHeader for LAME. Only version 1 is known.
+type VBRIHeader struct {
+	Version int
+	Delay   int // as declared, in an unspecified unit
+	Quality int
+	Bytes   int // size of the audio in bytes, as declared
+	Frames  int // number of audio frames, as declared
+}
+
+// ErrVBRIVersion is returned by ParseVBRI for a VBRI header of a version it
+// doesn't know, whose fields can't be trusted.
+type ErrVBRIVersion struct {
+	Version int
+}
+
+func (e ErrVBRIVersion) Error() string {
+	return fmt.Sprintf("unknown VBRI header version %d", e.Version)
+}
+
+// HasVBRI tells whether data, the bytes of a frame following the header,
+// hold a VBRI header.
+func HasVBRI(data []byte) bool {
+	return len(data) >= VBRIOffset+4 && bytes.Equal(data[VBRIOffset:VBRIOffset+4], []byte("VBRI"))
+}
+
+// ParseVBRI looks for a VBRI header in data, the bytes of a frame following
+// the header. It tells whether one was found, complete, i.e.
+//
+//	Magic           "VBRI"
+//	Version         $xx xx
+//	Delay           $xx xx
+//	Quality         $xx xx
+//	Bytes           $xx xx xx xx
+//	Frames          $xx xx xx xx
+//	TOC entries     $xx xx
+//	TOC scale       $xx xx
+//	Entry size      $xx xx
+//	Frames per entry $xx xx
+//
+// followed by the TOC, which isn't read. A header of another version than 1
+// is found, but returns ErrVBRIVersion.
+func ParseVBRI(data []byte) (VBRIHeader, bool, error) {
+	if !HasVBRI(data) || len(data) < VBRIOffset+lenOfVBRI {
+		return VBRIHeader{}, false, nil
+	}
+
+	data = data[VBRIOffset+4:]
+	v := VBRIHeader{Version: int(binary.BigEndian.Uint16(data[0:2]))}
+
+	if v.Version != 1 {
+		return VBRIHeader{}, true, ErrVBRIVersion{Version: v.Version}
+	}
+
+	v.Delay = int(binary.BigEndian.Uint16(data[2:4]))
+	v.Quality = int(binary.BigEndian.Uint16(data[4:6]))
+	v.Bytes = int(binary.BigEndian.Uint32(data[6:10]))
+	v.Frames = int(binary.BigEndian.Uint32(data[10:14]))
+
+	return v, true, nil
+}
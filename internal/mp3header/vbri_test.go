@@ -0,0 +1,69 @@
+package mp3header
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestHasVBRI(t *testing.T) {
+	data := make([]byte, 64)
+	copy(data[32:], "VBRI")
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"VBRI", data, true},
+		{"truncated", data[:35], false},
+		{"Xing", append(make([]byte, 32), "Xing"...), false},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasVBRI(tt.data); got != tt.want {
+				t.Errorf("HasVBRI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVBRI(t *testing.T) {
+	// vbri returns the bytes of a frame holding a VBRI header of the given
+	// version
+	vbri := func(version uint16) []byte {
+		data := make([]byte, 64)
+		copy(data[32:], "VBRI")
+		binary.BigEndian.PutUint16(data[36:], version)
+		binary.BigEndian.PutUint16(data[38:], 576)
+		binary.BigEndian.PutUint16(data[40:], 75)
+		binary.BigEndian.PutUint32(data[42:], 41700)
+		binary.BigEndian.PutUint32(data[46:], 200)
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    VBRIHeader
+		wantOK  bool
+		wantErr error
+	}{
+		{"version 1", vbri(1), VBRIHeader{Version: 1, Delay: 576, Quality: 75, Bytes: 41700, Frames: 200}, true, nil},
+		{"version 2", vbri(2), VBRIHeader{}, true, ErrVBRIVersion{Version: 2}},
+		{"truncated", vbri(1)[:50], VBRIHeader{}, false, nil},
+		{"none", make([]byte, 64), VBRIHeader{}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := ParseVBRI(tt.data)
+			if err != tt.wantErr {
+				t.Fatalf("ParseVBRI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParseVBRI() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package mp3header
+
+import (
+	"encoding/binary"
+)
+
+// Flags of the fields present in a Xing header
+const (
+	xingFlagFrames  = 0x1
+	xingFlagBytes   = 0x2
+	xingFlagTOC     = 0x4
+	xingFlagQuality = 0x8
+)
+
+// xingTOCSize is the number of entries of the TOC, one per percent of the
+// duration.
+const xingTOCSize = 100
+
+// XingHeader is the header written by LAME and other encoders in place of the
+// audio of the first frame, "Xing" for VBR streams and "Info" for CBR ones.
+// Fields absent from the header are zero, or nil for TOC.
+type XingHeader struct {
+	Magic   string // "Xing" or "Info"
+	Frames  int    // number of audio frames, as declared
+	Bytes   int    // size of the audio in bytes, as declared
+	TOC     []byte // the position of each percent of the duration, in 256ths of Bytes
+	Quality int
+	LAME    *LAMETag // following the Xing header, if any
+}
+
+// ParseXing looks for a Xing header in data, the bytes of the frame of header
+// h following the header. It tells whether one was found, complete.
+func ParseXing(data []byte, h MP3Header) (XingHeader, bool) {
+	var x XingHeader
+	offset := h.SideInfoSize()
+
+	if h.Layer != Layer3 || len(data) < offset+8 {
+		return x, false
+	}
+
+	x.Magic = string(data[offset : offset+4])
+
+	if x.Magic != "Xing" && x.Magic != "Info" {
+		return XingHeader{}, false
+	}
+
+	flags := binary.BigEndian.Uint32(data[offset+4:])
+	data = data[offset+8:]
+
+	field := func(flag uint32, size int) ([]byte, bool) {
+		if flags&flag == 0 {
+			return nil, true
+		}
+
+		if len(data) < size {
+			return nil, false
+		}
+
+		b := data[:size]
+		data = data[size:]
+
+		return b, true
+	}
+
+	frames, ok := field(xingFlagFrames, 4)
+	if !ok {
+		return XingHeader{}, false
+	}
+
+	size, ok := field(xingFlagBytes, 4)
+	if !ok {
+		return XingHeader{}, false
+	}
+
+	toc, ok := field(xingFlagTOC, xingTOCSize)
+	if !ok {
+		return XingHeader{}, false
+	}
+
+	quality, ok := field(xingFlagQuality, 4)
+	if !ok {
+		return XingHeader{}, false
+	}
+
+	if frames != nil {
+		x.Frames = int(binary.BigEndian.Uint32(frames))
+	}
+
+	if size != nil {
+		x.Bytes = int(binary.BigEndian.Uint32(size))
+	}
+
+	if toc != nil {
+		x.TOC = append([]byte{}, toc...)
+	}
+
+	if quality != nil {
+		x.Quality = int(binary.BigEndian.Uint32(quality))
+	}
+
+	x.LAME = parseLAME(data)
+
+	return x, true
+}
+
+// NormalizedTOC returns the TOC as fractions of the audio size, in [0, 1), and
+// whether it's valid, i.e. non-decreasing. Entries of an invalid TOC are
+// clamped to the previous one, so that offsets never go backwards. Returns nil
+// without a TOC.
+func (x *XingHeader) NormalizedTOC() ([]float64, bool) {
+	if len(x.TOC) != xingTOCSize {
+		return nil, false
+	}
+
+	fractions := make([]float64, xingTOCSize)
+	valid := true
+
+	for i, entry := range x.TOC {
+		fractions[i] = float64(entry) / 256
+
+		if i > 0 && fractions[i] < fractions[i-1] {
+			fractions[i] = fractions[i-1]
+			valid = false
+		}
+	}
+
+	return fractions, valid
+}
@@ -0,0 +1,145 @@
+package mp3header
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// generateXing returns the bytes of a frame of header h after the header, with
+// a Xing header of the given flags and fields.
+func generateXing(h MP3Header, magic string, flags uint32, frames, size int, toc []byte, quality int) []byte {
+	data := make([]byte, h.SideInfoSize())
+	data = append(data, magic...)
+	data = appendUint32(data, flags)
+
+	if flags&xingFlagFrames != 0 {
+		data = appendUint32(data, uint32(frames))
+	}
+	if flags&xingFlagBytes != 0 {
+		data = appendUint32(data, uint32(size))
+	}
+	if flags&xingFlagTOC != 0 {
+		data = append(data, toc...)
+	}
+	if flags&xingFlagQuality != 0 {
+		data = appendUint32(data, uint32(quality))
+	}
+
+	return data
+}
+
+func appendUint32(data []byte, v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return append(data, b...)
+}
+
+func linearTOC() []byte {
+	toc := make([]byte, xingTOCSize)
+	for i := range toc {
+		toc[i] = byte(i * 256 / xingTOCSize)
+	}
+	return toc
+}
+
+func TestParseXing(t *testing.T) {
+	stereo := MP3Header{AudioVersion: Version1, Layer: Layer3, ChannelMode: ChannelModeJointStereo}
+	mono := MP3Header{AudioVersion: Version2, Layer: Layer3, ChannelMode: ChannelModeMono}
+	all := uint32(xingFlagFrames | xingFlagBytes | xingFlagTOC | xingFlagQuality)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		header MP3Header
+		want   XingHeader
+		wantOK bool
+	}{
+		{
+			name:   "all fields",
+			data:   generateXing(stereo, "Xing", all, 1000, 417000, linearTOC(), 57),
+			header: stereo,
+			want:   XingHeader{Magic: "Xing", Frames: 1000, Bytes: 417000, TOC: linearTOC(), Quality: 57},
+			wantOK: true,
+		},
+		{
+			name:   "Info, frames only, MPEG-2 mono",
+			data:   generateXing(mono, "Info", xingFlagFrames, 1000, 0, nil, 0),
+			header: mono,
+			want:   XingHeader{Magic: "Info", Frames: 1000},
+			wantOK: true,
+		},
+		{
+			name:   "no magic",
+			data:   make([]byte, 413),
+			header: stereo,
+		},
+		{
+			name:   "magic at the wrong offset",
+			data:   generateXing(mono, "Xing", all, 1000, 417000, linearTOC(), 57),
+			header: stereo,
+		},
+		{
+			name:   "truncated TOC",
+			data:   generateXing(stereo, "Xing", all, 1000, 417000, linearTOC(), 57)[:32+8+8+50],
+			header: stereo,
+		},
+		{
+			name:   "Layer II",
+			data:   generateXing(stereo, "Xing", all, 1000, 417000, linearTOC(), 57),
+			header: MP3Header{AudioVersion: Version1, Layer: Layer2, ChannelMode: ChannelModeStereo},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseXing(tt.data, tt.header)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseXing() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseXing() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXingHeader_NormalizedTOC(t *testing.T) {
+	backwards := linearTOC()
+	backwards[50] = 10
+
+	tests := []struct {
+		name      string
+		toc       []byte
+		wantValid bool
+	}{
+		{"linear", linearTOC(), true},
+		{"backwards", backwards, false},
+		{"none", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := XingHeader{TOC: tt.toc}
+			got, valid := x.NormalizedTOC()
+
+			if valid != tt.wantValid {
+				t.Errorf("NormalizedTOC() valid = %v, want %v", valid, tt.wantValid)
+			}
+
+			if tt.toc == nil {
+				if got != nil {
+					t.Errorf("NormalizedTOC() = %v, want nil", got)
+				}
+				return
+			}
+
+			for i, f := range got {
+				if f < 0 || f >= 1 {
+					t.Errorf("NormalizedTOC()[%d] = %v, want within [0, 1)", i, f)
+				}
+				if i > 0 && f < got[i-1] {
+					t.Errorf("NormalizedTOC()[%d] = %v, before %v", i, f, got[i-1])
+				}
+			}
+		})
+	}
+}
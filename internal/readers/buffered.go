@@ -0,0 +1,55 @@
+package readers
+
+import (
+	"bufio"
+	"io"
+)
+
+// BufferedSeeker is a bufio.Reader that can also seek, dropping its buffer,
+// so that callers skipping large parts of the input, e.g. an ID3 tag, don't
+// read them.
+type BufferedSeeker struct {
+	*bufio.Reader
+	r io.Reader
+	s io.Seeker
+}
+
+// NewBufferedSeeker returns a BufferedSeeker reading from r and seeking with s,
+// which must move the same stream as r, e.g. r being s wrapped by
+// GuardProgress.
+func NewBufferedSeeker(r io.Reader, s io.Seeker) *BufferedSeeker {
+	return &BufferedSeeker{Reader: bufio.NewReader(r), r: r, s: s}
+}
+
+// Seek sets the offset of the next Read. Offsets are those of s, not counting
+// the bytes buffered but not read yet.
+func (b *BufferedSeeker) Seek(offset int64, whence int) (int64, error) {
+	buffered := int64(b.Buffered())
+
+	if whence == io.SeekCurrent {
+		if offset >= 0 && offset <= buffered {
+			// within the buffer
+			pos, err := b.s.Seek(0, io.SeekCurrent)
+
+			if err != nil {
+				return 0, err
+			}
+
+			_, err = b.Discard(int(offset))
+
+			return pos - buffered + offset, err
+		}
+
+		offset -= buffered
+	}
+
+	pos, err := b.s.Seek(offset, whence)
+
+	if err != nil {
+		return 0, err
+	}
+
+	b.Reset(b.r)
+
+	return pos, nil
+}
@@ -0,0 +1,71 @@
+package readers
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestBufferedSeeker_Seek(t *testing.T) {
+	// longer than the buffer
+	data := make([]byte, 6000)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+
+	tests := []struct {
+		name    string
+		offset  int64
+		whence  int
+		wantPos int64
+	}{
+		{"current, none", 0, io.SeekCurrent, 2},
+		{"current, within the buffer", 10, io.SeekCurrent, 12},
+		{"current, past the buffer", 5000, io.SeekCurrent, 5002},
+		{"start", 1500, io.SeekStart, 1500},
+		{"end", -1, io.SeekEnd, 5999},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(data)
+			b := NewBufferedSeeker(r, r)
+
+			if _, err := io.ReadFull(b, make([]byte, 2)); err != nil {
+				t.Fatal(err)
+			}
+
+			pos, err := b.Seek(tt.offset, tt.whence)
+
+			if err != nil {
+				t.Fatalf("Seek() error = %v", err)
+			}
+
+			if pos != tt.wantPos {
+				t.Errorf("Seek() = %v, want %v", pos, tt.wantPos)
+			}
+
+			if next, _ := b.ReadByte(); next != data[tt.wantPos] {
+				t.Errorf("next byte = %v, want %v", next, data[tt.wantPos])
+			}
+		})
+	}
+}
+
+func TestBufferedSeeker_SeekForward(t *testing.T) {
+	data := make([]byte, 6000)
+	r := bytes.NewReader(data)
+	b := NewBufferedSeeker(r, r)
+
+	if _, err := b.Peek(10); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := SeekForward(b, 5000); n != 5000 || err != nil {
+		t.Fatalf("SeekForward() = (%v, %v), want (5000, nil)", n, err)
+	}
+
+	if n, _ := io.Copy(ioutil.Discard, b); n != 1000 {
+		t.Errorf("read %d bytes after SeekForward(), want 1000", n)
+	}
+}
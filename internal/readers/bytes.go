@@ -0,0 +1,34 @@
+package readers
+
+import (
+	"io"
+)
+
+type byteReader struct {
+	br io.ByteReader
+}
+
+// FromByteReader adapts br, which reads a byte at a time, to an io.Reader.
+// A Read fills p until br fails, and returns the error along with the bytes
+// read before it. When br is an io.Reader already, it's returned as is.
+func FromByteReader(br io.ByteReader) io.Reader {
+	if r, ok := br.(io.Reader); ok {
+		return r
+	}
+
+	return &byteReader{br: br}
+}
+
+func (b *byteReader) Read(p []byte) (int, error) {
+	for i := range p {
+		c, err := b.br.ReadByte()
+
+		if err != nil {
+			return i, err
+		}
+
+		p[i] = c
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,57 @@
+package readers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// byteOnlyReader implements io.ByteReader, and nothing else.
+type byteOnlyReader struct {
+	data []byte
+	err  error // returned past data
+}
+
+func (r *byteOnlyReader) ReadByte() (byte, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+
+	c := r.data[0]
+	r.data = r.data[1:]
+
+	return c, nil
+}
+
+func TestFromByteReader(t *testing.T) {
+	want := []byte("ID3\x03\x00\x00\x00\x00\x00\x00")
+
+	t.Run("bytes", func(t *testing.T) {
+		got, err := ioutil.ReadAll(FromByteReader(&byteOnlyReader{data: want, err: io.EOF}))
+
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("ReadAll() = %q, %v, want %q", got, err, want)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		broken := errors.New("broken")
+		p := make([]byte, 20)
+
+		n, err := FromByteReader(&byteOnlyReader{data: want, err: broken}).Read(p)
+
+		if n != len(want) || err != broken {
+			t.Errorf("Read() = %d, %v, want %d, %v", n, err, len(want), broken)
+		}
+	})
+
+	t.Run("io.Reader", func(t *testing.T) {
+		r := bytes.NewReader(want)
+
+		if got := FromByteReader(r); got != io.Reader(r) {
+			t.Errorf("FromByteReader() = %T, want the reader itself", got)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+// Package readers holds io.Reader wrappers shared by the decoding packages.
+package readers
+
+import (
+	"io"
+)
+
+// MaxConsecutiveEmptyReads is how many times in a row a reader may return
+// (0, nil) before it's considered stuck. Same as bufio.
+const MaxConsecutiveEmptyReads = 100
+
+type progressGuard struct {
+	r io.Reader
+}
+
+// GuardProgress wraps r so that a Read keeps retrying reads returning (0, nil),
+// but gives up with io.ErrNoProgress after MaxConsecutiveEmptyReads of them.
+// Without it, io.ReadFull and io.CopyN would spin forever on such a reader.
+func GuardProgress(r io.Reader) io.Reader {
+	if _, ok := r.(*progressGuard); ok {
+		return r
+	}
+
+	return &progressGuard{r: r}
+}
+
+func (g *progressGuard) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return g.r.Read(p)
+	}
+
+	for i := 0; i < MaxConsecutiveEmptyReads; i++ {
+		n, err := g.r.Read(p)
+
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+
+	return 0, io.ErrNoProgress
+}
@@ -0,0 +1,56 @@
+package readers
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// stuckReader returns (0, nil) forever, counting the calls.
+type stuckReader struct {
+	calls int
+}
+
+func (r *stuckReader) Read(_ []byte) (int, error) {
+	r.calls++
+	return 0, nil
+}
+
+func TestGuardProgress(t *testing.T) {
+	t.Run("stuck reader", func(t *testing.T) {
+		stuck := new(stuckReader)
+
+		_, err := io.ReadFull(GuardProgress(stuck), make([]byte, 10))
+
+		if err != io.ErrNoProgress {
+			t.Errorf("ReadFull() error = %v, want %v", err, io.ErrNoProgress)
+		}
+
+		if stuck.calls != MaxConsecutiveEmptyReads {
+			t.Errorf("underlying Read() called %d times, want %d", stuck.calls, MaxConsecutiveEmptyReads)
+		}
+	})
+
+	t.Run("normal reader", func(t *testing.T) {
+		want := []byte("ID3\x03\x00\x00\x00\x00\x00\x00")
+
+		got, err := ioutil.ReadAll(GuardProgress(bytes.NewReader(want)))
+
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("ReadAll() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("not wrapped twice", func(t *testing.T) {
+		r := GuardProgress(bytes.NewReader(nil))
+
+		if GuardProgress(r) != r {
+			t.Errorf("GuardProgress() wrapped a guarded reader again")
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package readers
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"mp3len/internal/clock"
+)
+
+type rateLimiter struct {
+	r     io.Reader
+	ctx   context.Context
+	clock clock.Clock
+	rate  int64 // bytes per second, also the size of the bucket
+
+	tokens int64
+	last   time.Time // when tokens were last added
+}
+
+type rateLimitedSeeker struct {
+	*rateLimiter
+	s io.Seeker
+}
+
+// RateLimit wraps r so that reads average at most bytesPerSecond bytes per
+// second, timed by clk, with bursts of up to a second worth of bytes: a token
+// bucket. A read waiting for its turn returns the error of ctx as soon as ctx
+// is done.
+//
+// The result is an io.Seeker when r is, so that the input can still be skipped
+// through by seeking, which reads nothing.
+func RateLimit(ctx context.Context, r io.Reader, clk clock.Clock, bytesPerSecond int64) io.Reader {
+	l := &rateLimiter{
+		r:      r,
+		ctx:    ctx,
+		clock:  clk,
+		rate:   bytesPerSecond,
+		tokens: bytesPerSecond,
+		last:   clk.Now(),
+	}
+
+	if s, ok := r.(io.Seeker); ok {
+		return &rateLimitedSeeker{l, s}
+	}
+
+	return l
+}
+
+func (l *rateLimiter) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return l.r.Read(p)
+	}
+
+	need := int64(len(p))
+	if need > l.rate {
+		need = l.rate
+	}
+
+	if err := l.wait(need); err != nil {
+		return 0, err
+	}
+
+	if int64(len(p)) > l.tokens {
+		p = p[:l.tokens]
+	}
+
+	n, err := l.r.Read(p)
+	l.tokens -= int64(n)
+
+	return n, err
+}
+
+// wait blocks until the bucket holds n tokens, or ctx is done.
+func (l *rateLimiter) wait(n int64) error {
+	for {
+		if err := l.ctx.Err(); err != nil {
+			return err
+		}
+
+		l.refill()
+
+		if l.tokens >= n {
+			return nil
+		}
+
+		// rounded up, not to wake up a token short
+		d := time.Duration(((n-l.tokens)*int64(time.Second) + l.rate - 1) / l.rate)
+		timer := l.clock.NewTimer(d)
+
+		select {
+		case <-timer.C():
+		case <-l.ctx.Done():
+			timer.Stop()
+			return l.ctx.Err()
+		}
+	}
+}
+
+// refill adds the tokens earned since last.
+func (l *rateLimiter) refill() {
+	now := l.clock.Now()
+	elapsed := now.Sub(l.last)
+
+	if elapsed >= time.Second {
+		l.tokens, l.last = l.rate, now
+		return
+	}
+
+	earned := int64(elapsed) * l.rate / int64(time.Second)
+
+	if earned <= 0 {
+		return
+	}
+
+	// the remainder is kept for the next refill
+	l.tokens += earned
+	l.last = l.last.Add(time.Duration(earned * int64(time.Second) / l.rate))
+
+	if l.tokens >= l.rate {
+		l.tokens, l.last = l.rate, now
+	}
+}
+
+func (l *rateLimitedSeeker) Seek(offset int64, whence int) (int64, error) {
+	return l.s.Seek(offset, whence)
+}
@@ -0,0 +1,80 @@
+package readers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"mp3len/internal/testutil"
+)
+
+func TestRateLimit(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Unix(0, 0))
+	r := RateLimit(context.Background(), bytes.NewReader(make([]byte, 2500)), clk, 1000)
+	buf := make([]byte, 1000)
+
+	// a second worth of bytes at once
+	if n, err := r.Read(buf); n != 1000 || err != nil {
+		t.Fatalf("Read() = %v, %v, want 1000, nil", n, err)
+	}
+
+	reads := make(chan int)
+
+	read := func() {
+		n, _ := r.Read(buf)
+		reads <- n
+	}
+
+	for _, want := range []int{1000, 500} {
+		go read()
+
+		clk.BlockUntil(1)
+		clk.Advance(500 * time.Millisecond)
+
+		// still waiting for the whole chunk
+		clk.BlockUntil(1)
+
+		select {
+		case n := <-reads:
+			t.Fatalf("Read() = %v after half a second, want it to wait", n)
+		default:
+		}
+
+		clk.Advance(500 * time.Millisecond)
+
+		if n := <-reads; n != want {
+			t.Errorf("Read() = %v, want %v", n, want)
+		}
+	}
+
+	if _, err := r.(io.Seeker).Seek(0, io.SeekStart); err != nil {
+		t.Errorf("Seek() error = %v", err)
+	}
+}
+
+func TestRateLimit_Canceled(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	r := RateLimit(ctx, bytes.NewReader(make([]byte, 100)), clk, 10)
+
+	if _, err := r.Read(make([]byte, 10)); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	errs := make(chan error)
+
+	go func() {
+		_, err := r.Read(make([]byte, 10))
+		errs <- err
+	}()
+
+	// blocked for a second that never comes
+	clk.BlockUntil(1)
+	cancel()
+
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("Read() error = %v, want %v", err, context.Canceled)
+	}
+}
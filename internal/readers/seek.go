@@ -0,0 +1,32 @@
+package readers
+
+import "io"
+
+// SeekForward moves s n bytes forward, but never past its end: bounded
+// seekers such as io.SectionReader accept seeking beyond their end, which
+// would hide truncated input. Like io.CopyN, it returns the number of bytes
+// skipped and io.EOF if s ends before n bytes.
+func SeekForward(s io.Seeker, n int64) (int64, error) {
+	current, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	target := current + n
+	err = nil
+
+	if target > end {
+		target, err = end, io.EOF
+	}
+
+	if _, seekErr := s.Seek(target, io.SeekStart); seekErr != nil {
+		return 0, seekErr
+	}
+
+	return target - current, err
+}
@@ -0,0 +1,48 @@
+package readers
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestSeekForward(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+
+	tests := []struct {
+		name     string
+		n        int64
+		want     int64
+		wantErr  error
+		wantNext byte
+	}{
+		{"within the section", 3, 3, nil, '8'},
+		{"to the end", 5, 5, nil, 0},
+		{"past the end", 6, 5, io.EOF, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// "56789" of data
+			s := io.NewSectionReader(bytes.NewReader(data), 5, 5)
+
+			got, err := SeekForward(s, tt.n)
+
+			if err != tt.wantErr {
+				t.Fatalf("SeekForward() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("SeekForward() = %v, want %v", got, tt.want)
+			}
+
+			next := make([]byte, 1)
+			n, _ := s.Read(next)
+
+			if tt.wantNext == 0 && n != 0 {
+				t.Errorf("SeekForward() left %q to read, want nothing", next[:n])
+			} else if tt.wantNext != 0 && (n != 1 || next[0] != tt.wantNext) {
+				t.Errorf("SeekForward() left %q to read, want %q", next[:n], tt.wantNext)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package readers
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"mp3len/internal/clock"
+)
+
+// ErrTimeout is returned by the readers of Timeout when a read takes too long.
+var ErrTimeout = errors.New("read timed out")
+
+// deadliner is implemented by inputs whose reads can be given a deadline, e.g.
+// net.Conn and *os.File of a pipe.
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+type timeoutReader struct {
+	r       io.Reader
+	clock   clock.Clock
+	timeout time.Duration
+
+	buf     []byte          // read into by the goroutine, not to share p
+	results chan readResult // from the goroutine of the last read
+	err     error           // ErrTimeout once a read timed out
+}
+
+type timeoutSeeker struct {
+	*timeoutReader
+	s io.Seeker
+}
+
+// Timeout wraps r so that each read returns ErrTimeout when it doesn't
+// complete within timeout, timed by clk, however slowly r delivers its bytes.
+//
+// When r has SetReadDeadline, a deadline is set before each read and cleared
+// after it, so that r can still be read without one afterwards. Deadlines are
+// those of the OS, timed by the real clock rather than clk. Otherwise
+// the read runs in a goroutine, which is left behind when it times out, still
+// blocked in r until r is closed or fails. Reads after a timeout then fail
+// right away.
+//
+// The result is an io.Seeker when r is.
+func Timeout(r io.Reader, clk clock.Clock, timeout time.Duration) io.Reader {
+	t := &timeoutReader{
+		r:       r,
+		clock:   clk,
+		timeout: timeout,
+		results: make(chan readResult, 1),
+	}
+
+	if s, ok := r.(io.Seeker); ok {
+		return &timeoutSeeker{t, s}
+	}
+
+	return t
+}
+
+func (t *timeoutReader) Read(p []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+
+	if d, ok := t.r.(deadliner); ok {
+		if err := d.SetReadDeadline(time.Now().Add(t.timeout)); err == nil {
+			defer d.SetReadDeadline(time.Time{})
+			return t.readWithDeadline(p)
+		}
+	}
+
+	if cap(t.buf) < len(p) {
+		t.buf = make([]byte, len(p))
+	}
+
+	buf := t.buf[:len(p)]
+
+	go func() {
+		n, err := t.r.Read(buf)
+		t.results <- readResult{n, err}
+	}()
+
+	timer := t.clock.NewTimer(t.timeout)
+
+	select {
+	case result := <-t.results:
+		timer.Stop()
+		return copy(p, buf[:result.n]), result.err
+	case <-timer.C():
+		// the goroutine keeps buf
+		t.err = ErrTimeout
+		return 0, t.err
+	}
+}
+
+// readWithDeadline reads from r, whose deadline was set, turning the error of
+// an expired deadline into ErrTimeout.
+func (t *timeoutReader) readWithDeadline(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+
+	var netErr net.Error
+
+	if errors.Is(err, os.ErrDeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		t.err = ErrTimeout
+		return n, t.err
+	}
+
+	return n, err
+}
+
+func (t *timeoutSeeker) Seek(offset int64, whence int) (int64, error) {
+	return t.s.Seek(offset, whence)
+}
@@ -0,0 +1,122 @@
+package readers
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"mp3len/internal/clock"
+	"mp3len/internal/testutil"
+)
+
+// blockingReader delivers the chunks sent to it, one per read.
+type blockingReader chan []byte
+
+func (r blockingReader) Read(p []byte) (int, error) {
+	chunk, ok := <-r
+	if !ok {
+		return 0, io.EOF
+	}
+
+	return copy(p, chunk), nil
+}
+
+func TestTimeout(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Unix(0, 0))
+	src := make(blockingReader, 1)
+	r := Timeout(src, clk, time.Second)
+	buf := make([]byte, 10)
+
+	src <- []byte("abc")
+
+	if n, err := r.Read(buf); n != 3 || err != nil || string(buf[:n]) != "abc" {
+		t.Fatalf("Read() = %v, %v, want 3, nil", n, err)
+	}
+
+	errs := make(chan error)
+
+	go func() {
+		_, err := r.Read(buf)
+		errs <- err
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+
+	if err := <-errs; err != ErrTimeout {
+		t.Errorf("Read() error = %v, want %v", err, ErrTimeout)
+	}
+
+	// even once the blocked read completes
+	src <- []byte("def")
+
+	if _, err := r.Read(buf); err != ErrTimeout {
+		t.Errorf("Read() after a timeout error = %v, want %v", err, ErrTimeout)
+	}
+}
+
+func TestTimeout_Deadline(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	r := Timeout(client, clock.Real, 20*time.Millisecond)
+
+	go server.Write([]byte("abc"))
+
+	buf := make([]byte, 10)
+
+	if n, err := r.Read(buf); n != 3 || err != nil {
+		t.Fatalf("Read() = %v, %v, want 3, nil", n, err)
+	}
+
+	// nothing more is written
+	if _, err := r.Read(buf); err != ErrTimeout {
+		t.Errorf("Read() error = %v, want %v", err, ErrTimeout)
+	}
+
+	// the deadline of the last read is gone
+	go func() {
+		time.Sleep(40 * time.Millisecond)
+		server.Write([]byte("def"))
+	}()
+
+	if n, err := client.Read(buf); n != 3 || err != nil {
+		t.Errorf("client.Read() after the timeout = %v, %v, want 3, nil", n, err)
+	}
+}
+
+func TestTimeout_DeadlineFakeClock(t *testing.T) {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	// long past, as a deadline of the OS
+	clk := testutil.NewFakeClock(time.Unix(0, 0))
+	r := Timeout(client, clk, time.Second)
+
+	go server.Write([]byte("abc"))
+
+	if n, err := r.Read(make([]byte, 10)); n != 3 || err != nil {
+		t.Errorf("Read() = %v, %v, want 3, nil", n, err)
+	}
+}
+
+func TestTimeout_Seeker(t *testing.T) {
+	r := Timeout(bytes.NewReader([]byte("abc")), clock.Real, time.Second)
+
+	if _, err := r.(io.Seeker).Seek(1, io.SeekStart); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+
+	if b, err := ioutil.ReadAll(r); err != nil || string(b) != "bc" {
+		t.Errorf("ReadAll() = %q, %v, want %q", b, err, "bc")
+	}
+}
@@ -0,0 +1,68 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock_Sleep(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	done := make(chan time.Time)
+
+	go func() {
+		c.Sleep(time.Hour)
+		done <- c.Now()
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(59 * time.Minute)
+
+	select {
+	case <-done:
+		t.Fatal("Sleep() returned before its deadline")
+	default:
+	}
+
+	c.Advance(time.Minute)
+
+	if got := <-done; !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("Now() after Sleep() = %v, want %v", got, start.Add(time.Hour))
+	}
+}
+
+func TestFakeClock_Timer(t *testing.T) {
+	c := NewFakeClock(time.Time{})
+
+	fired := c.NewTimer(time.Second)
+	stopped := c.NewTimer(time.Second)
+
+	if !stopped.Stop() {
+		t.Errorf("Stop() = false, want true for a pending timer")
+	}
+
+	c.Advance(time.Second)
+
+	select {
+	case <-fired.C():
+	default:
+		t.Errorf("timer didn't fire at its deadline")
+	}
+
+	select {
+	case <-stopped.C():
+		t.Errorf("stopped timer fired")
+	default:
+	}
+
+	if fired.Stop() {
+		t.Errorf("Stop() = true, want false for a fired timer")
+	}
+}
+
+func TestFakeClock_ZeroDuration(t *testing.T) {
+	c := NewFakeClock(time.Time{})
+
+	// must not block
+	c.Sleep(0)
+}
@@ -0,0 +1,98 @@
+package mp3len
+
+import (
+	"encoding/json"
+)
+
+type jsonAudio struct {
+	Version        string `json:"version"`
+	Layer          string `json:"layer"`
+	BitRate        int    `json:"bitRate"` // of the first frame
+	AverageBitRate int    `json:"averageBitRate,omitempty"`
+	SampleRate     int    `json:"sampleRate"`
+	ChannelMode    string `json:"channelMode"`
+	VBR            bool   `json:"vbr"`
+}
+
+type jsonTags struct {
+	Source  string `json:"source"`
+	Title   string `json:"title,omitempty"`
+	Artist  string `json:"artist,omitempty"`
+	Album   string `json:"album,omitempty"`
+	Year    string `json:"year,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	Track   int    `json:"track,omitempty"`
+	Genre   string `json:"genre,omitempty"`
+}
+
+type jsonMetadata struct {
+	Duration         float64   `json:"duration"` // seconds
+	Confidence       string    `json:"confidence"`
+	DurationFromXing bool      `json:"durationFromXing,omitempty"`
+	VBRHeader        string    `json:"vbrHeader,omitempty"`
+	VBRHeaderOffset  int64     `json:"vbrHeaderOffset,omitempty"`
+	TagSize          int       `json:"tagSize"`
+	TagVersion       string    `json:"tagVersion,omitempty"`
+	TagLocation      string    `json:"tagLocation"`
+	GapAfterTag      int64     `json:"gapAfterTag"`
+	Audio            jsonAudio `json:"audio"`
+	FrameCount       int64     `json:"frameCount,omitempty"`
+	TotalSamples     int64     `json:"totalSamples,omitempty"`
+	OrphanBytes      int64     `json:"orphanBytes,omitempty"`
+	Tags             *jsonTags `json:"tags,omitempty"`
+	Warnings         []string  `json:"warnings,omitempty"`
+}
+
+// MarshalJSON encodes the metadata as a JSON object, that of its Report.
+func (metadata *Metadata) MarshalJSON() ([]byte, error) {
+	return metadata.Report().MarshalJSON()
+}
+
+// MarshalJSON encodes the report as a JSON object. Duration is in seconds.
+func (report *Report) MarshalJSON() ([]byte, error) {
+	var tags *jsonTags
+
+	if report.Tags != nil {
+		tags = &jsonTags{
+			Source:  report.Tags.Source.String(),
+			Title:   report.Tags.Title,
+			Artist:  report.Tags.Artist,
+			Album:   report.Tags.Album,
+			Year:    report.Tags.Year,
+			Comment: report.Tags.Comment,
+			Track:   report.Tags.Track,
+			Genre:   report.Tags.Genre,
+		}
+	}
+
+	v := jsonMetadata{
+		Duration:         report.Duration.Seconds(),
+		Confidence:       report.Confidence.String(),
+		DurationFromXing: report.DurationFromXing,
+		TagSize:          report.TagSize,
+		TagVersion:       report.TagVersion,
+		TagLocation:      report.TagLocation.String(),
+		GapAfterTag:      report.GapAfterTag,
+		Audio: jsonAudio{
+			Version:        report.Header.VersionName(),
+			Layer:          report.Header.LayerName(),
+			BitRate:        report.Header.BitRate,
+			AverageBitRate: report.AverageBitRate,
+			SampleRate:     report.Header.SampleFreq,
+			ChannelMode:    report.Header.ChannelModeName(),
+			VBR:            report.VBR,
+		},
+		FrameCount:   report.FrameCount,
+		TotalSamples: report.TotalSamples,
+		OrphanBytes:  report.OrphanBytes,
+		Tags:         tags,
+		Warnings:     report.Warnings,
+	}
+
+	// left out without header
+	if report.VBRHeader != "none" {
+		v.VBRHeader, v.VBRHeaderOffset = report.VBRHeader, report.VBRHeaderOffset
+	}
+
+	return json.Marshal(v)
+}
@@ -1,76 +1,984 @@
 package mp3len
 
 import (
-	"encoding/binary"
+	"bufio"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
-	"strings"
+	"math"
+	"os"
 	"time"
 
 	"mp3len/internal/id3"
 	"mp3len/internal/mp3header"
+	"mp3len/internal/readers"
 )
 
+// ErrImplausibleDuration is returned in strict mode when the computed duration
+// is negative or exceeds the configured ceiling.
+var ErrImplausibleDuration = errors.New("implausible duration")
+
+// ErrVBRIVersion is returned in strict mode when the first frame holds a VBRI
+// header of a version other than 1, whose frame count can't be trusted.
+// Otherwise the duration is estimated, with a warning.
+type ErrVBRIVersion = mp3header.ErrVBRIVersion
+
+// ErrTooSmall is returned when the input is too small to hold even an ID3 tag
+// header and an audio frame header.
+var ErrTooSmall = errors.New("file too small to be an MP3")
+
+// minSize is the size of an ID3 tag header plus an MP3 frame header.
+const minSize = 10 + 4
+
+// Confidence describes how far the duration can be trusted.
+type Confidence int
+
+const (
+	// ConfidenceEstimated means the duration was estimated from the file size
+	// and the bit rate of the first frame.
+	ConfidenceEstimated Confidence = iota
+	// ConfidenceSuspect means the duration looks wrong; see Warnings.
+	ConfidenceSuspect
+	// ConfidenceExact means the duration was computed from the frame count.
+	ConfidenceExact
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceEstimated:
+		return "estimated"
+	case ConfidenceSuspect:
+		return "suspect"
+	case ConfidenceExact:
+		return "exact"
+	default:
+		return fmt.Sprintf("Confidence(%d)", int(c))
+	}
+}
+
 // Metadata holds the parsed metadata of an MP3 input
 type Metadata struct {
-	duration  time.Duration // Estimated duration of the MP3
-	tagSize   int
-	mp3Header mp3header.MP3Header // MP3 Audio Frame Header (first frame only)
+	duration          time.Duration // Estimated duration of the MP3
+	confidence        Confidence
+	tagSize           int
+	tagVersion        uint8 // major version of the ID3v2 tag, e.g. 3 for ID3v2.3
+	tagLocation       TagLocation
+	appendedTagOffset int64               // where the appended tag starts, if any
+	appendedTagSize   int64               // bytes taken by an appended tag at the end of the input
+	gapAfterTag       int64               // bytes between the tag (or the start) and the first frame
+	rangeOffset       int64               // where the reader starts within the input, see GetInfoRange
+	audioOffset       int64               // where the first frame starts within the input
+	mp3Header         mp3header.MP3Header // MP3 Audio Frame Header (first frame only)
+	warnings          []string
+
+	frameCount       int64  // number of audio frames, 0 if unknown
+	vbrHeader        string // "Xing", "Info" or "VBRI", "" if none, see VBRHeaderType
+	vbrHeaderOffset  int64  // of the magic of vbrHeader in the input
+	durationFromXing bool   // frameCount is that of the Xing or VBRI header, see DurationFromXing
+	xingBytes        int64  // of the frames counted by that header, see useDeclaredFrames
+	vbr              bool   // bit rate varies between frames, see IsVBR
+	encoderDelay     int    // samples added by the encoder at the start, from the LAME tag
+	encoderPadding   int    // samples added by the encoder at the end, from the LAME tag
+
+	incompleteFrameBytes int64 // of the last frame, cut short by the end of the input
+
+	sampledFrames     int // frames sampled by GetInfoAt when the bit rate varies
+	sampledBitRateSum int // kbps
+
+	averageBitRate int // kbps, from the size and duration of the audio, 0 if unknown
+
+	crc32     uint32 // of the whole input, with GetInfoOptions.CRC32
+	bytesRead int64  // how far the input was advanced
+
+	seekTable *SeekTable // from the TOC of the Xing header, if any
+
+	v1Tag *id3.V1Tag // trailing ID3v1 tag of an input without ID3v2 tag, see GetInfoAt
+
+	declaredSize int64 // by the server, when larger than the input turned out, see SizeMismatch
+	actualSize   int64
+}
+
+// audioBytes returns the size of the audio, from the first frame on, in an
+// input of totalSize bytes.
+func (metadata *Metadata) audioBytes(totalSize int64) int64 {
+	if metadata.appendedTagSize > 0 {
+		// anything after it, i.e. an ID3v1 tag, isn't audio either
+		return metadata.appendedTagOffset - metadata.audioOffset
+	}
+
+	if metadata.v1Tag != nil {
+		return totalSize - id3.LenOfV1 - metadata.audioOffset
+	}
+
+	return totalSize - metadata.audioOffset
 }
 
-func (metadata *Metadata) calculateDuration(totalSize int64) {
-	// Algorithm from https://www.factorialcomplexity.com/blog/how-to-get-a-duration-of-a-remote-mp3-file
-	metadata.duration = time.Duration((totalSize - int64(metadata.tagSize+10)) / (int64(metadata.mp3Header.BitRate) / 8) * 1000000)
+func (metadata *Metadata) calculateDuration(totalSize int64) error {
+	var err error
+
+	// the bit rate alone makes a duration, but not a sensible one
+	if metadata.mp3Header.SampleFreq <= 0 {
+		return errZeroSampleFreq
+	}
+
+	audioBytes := metadata.audioBytes(totalSize)
+
+	if metadata.sampledFrames > 0 && metadata.sampledBitRateSum > 0 {
+		// the average bit rate of the sampled frames, as they're all as long
+		ms := audioBytes * 8 * int64(metadata.sampledFrames) / int64(metadata.sampledBitRateSum)
+		metadata.duration = time.Duration(ms) * time.Millisecond
+		return nil
+	}
+
+	if metadata.duration, err = EstimateDuration(metadata.mp3Header, audioBytes); err != nil {
+		return err
+	}
 
+	// legacy estimate of mono streams, kept as is
 	if metadata.mp3Header.ChannelMode == mp3header.ChannelModeMono {
 		metadata.duration *= 2
 	}
+
+	return nil
 }
 
-func (metadata *Metadata) String(verbose bool) string {
-	if !verbose {
-		return metadata.duration.String()
+// estimate computes the duration from totalSize and checks it makes sense.
+func (metadata *Metadata) estimate(totalSize int64, o *options) error {
+	if err := metadata.calculateDuration(totalSize); err != nil {
+		return err
+	}
+
+	return metadata.checkPlausibility(totalSize, o)
+}
+
+// useDeclaredFrames takes the frame count declared by the Xing or VBRI header
+// of the first frame, and the bytes of the audio it declares, or else
+// measured, for the average bit rate.
+func (metadata *Metadata) useDeclaredFrames(frames, declaredBytes int, vbr bool, totalSize int64) {
+	metadata.frameCount = int64(frames)
+	metadata.durationFromXing = true
+	metadata.vbr = vbr
+
+	audioBytes := int64(declaredBytes)
+
+	if audioBytes <= 0 && totalSize >= 0 {
+		audioBytes = metadata.audioBytes(totalSize)
+	}
+
+	// the frame count leaves out the frame of the header, the bytes don't
+	metadata.xingBytes = audioBytes - int64(metadata.mp3Header.FrameLength())
+}
+
+// vbrHeaders holds the Xing, Info or VBRI header the first frame may hold
+// rather than audio, whose bit rate may then differ from that of the audio.
+// Never both: a VBRI header only counts when there is no Xing one.
+type vbrHeaders struct {
+	xing    mp3header.XingHeader
+	hasXing bool
+	vbri    mp3header.VBRIHeader
+	hasVBRI bool
+	vbriErr error // of a VBRI header of unknown version
+}
+
+// headerFrame tells whether the first frame holds a header rather than audio.
+func (h vbrHeaders) headerFrame() bool {
+	return h.hasXing || h.hasVBRI
+}
+
+// declaresFrames tells whether the header declares the number of frames.
+func (h vbrHeaders) declaresFrames() bool {
+	return h.hasXing && h.xing.Frames > 0 || h.hasVBRI && h.vbriErr == nil && h.vbri.Frames > 0
+}
+
+// parseVBRHeaders parses the header held by the first frame, whose bytes after
+// the frame header are data, and records its type and offset.
+func (metadata *Metadata) parseVBRHeaders(data []byte) vbrHeaders {
+	var h vbrHeaders
+
+	h.xing, h.hasXing = mp3header.ParseXing(data, metadata.mp3Header)
+
+	if !h.hasXing {
+		h.vbri, h.hasVBRI, h.vbriErr = mp3header.ParseVBRI(data)
+	}
+
+	if h.hasXing {
+		metadata.vbrHeader = h.xing.Magic
+		metadata.vbrHeaderOffset = metadata.audioOffset + 4 + int64(metadata.mp3Header.SideInfoSize())
+	} else if h.hasVBRI {
+		metadata.vbrHeader = "VBRI"
+		metadata.vbrHeaderOffset = metadata.audioOffset + 4 + mp3header.VBRIOffset
+	}
+
+	return h
+}
+
+// useDeclaredHeader computes the exact duration from the frames declared by
+// h, see declaresFrames, and the encoder delay and padding of a LAME tag.
+func (metadata *Metadata) useDeclaredHeader(h vbrHeaders, totalSize int64, o *options) error {
+	if h.hasXing {
+		metadata.useDeclaredFrames(h.xing.Frames, h.xing.Bytes, h.xing.Magic == "Xing", totalSize)
+		metadata.useLAME(h)
+	} else {
+		// only written by VBR encoders
+		metadata.useDeclaredFrames(h.vbri.Frames, h.vbri.Bytes, true, totalSize)
 	}
 
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintln("Duration:", metadata.duration.String()))
+	if err := metadata.calculateExactDuration(); err != nil {
+		return err
+	}
+
+	return metadata.checkPlausibility(totalSize, o)
+}
+
+// useScan computes the exact duration from the frames counted by a full scan,
+// frameCount of them of frameBytes bytes, the first frame included. The frame
+// of the header of h, if any, holds no audio, as in the frames it declares.
+func (metadata *Metadata) useScan(frameBytes int64, h vbrHeaders, totalSize int64, o *options) error {
+	if h.headerFrame() {
+		metadata.frameCount--
+		frameBytes -= int64(metadata.mp3Header.FrameLength())
+
+		if frameBytes < 0 {
+			frameBytes = 0
+		}
+	}
+
+	metadata.useLAME(h)
+	metadata.checkIncompleteFrame(o)
+
+	if err := metadata.calculateExactDuration(); err != nil {
+		return err
+	}
+
+	metadata.averageBitRate = averageBitRate(frameBytes, metadata.duration)
 
-	sb.WriteString("Audio: ")
-	sb.WriteString(metadata.mp3Header.String())
-	sb.WriteByte('\n')
+	return metadata.checkPlausibility(totalSize, o)
+}
+
+// useLAME takes the encoder delay and padding of the LAME tag of h, if any.
+func (metadata *Metadata) useLAME(h vbrHeaders) {
+	if h.hasXing && h.xing.LAME != nil {
+		metadata.encoderDelay = h.xing.LAME.EncoderDelay
+		metadata.encoderPadding = h.xing.LAME.EncoderPadding
+	}
+}
+
+// checkVBRHeaders checks h once the duration is known: a VBRI header of
+// unknown version, see checkVBRI, and the seek table of a Xing header.
+func (metadata *Metadata) checkVBRHeaders(h vbrHeaders, totalSize int64, o *options) error {
+	if h.vbriErr != nil {
+		if err := metadata.checkVBRI(h.vbriErr, o); err != nil {
+			return err
+		}
+	}
 
-	sb.WriteString(fmt.Sprintf("ID3 Tag total size: %d\n", metadata.tagSize))
+	if h.hasXing {
+		return metadata.readSeekTable(h.xing, totalSize, o)
+	}
 
-	return sb.String()
+	return nil
+}
+
+// checkVBRI turns err, from parsing a VBRI header, into a warning, as the
+// duration can still be estimated. In strict mode it's returned.
+func (metadata *Metadata) checkVBRI(err error, o *options) error {
+	metadata.confidence = ConfidenceSuspect
+	metadata.warnings = append(metadata.warnings, fmt.Sprintf("%v, the duration is estimated", err))
+
+	if o.strict {
+		return err
+	}
+
+	return nil
+}
+
+func (metadata *Metadata) calculateExactDuration() error {
+	if metadata.mp3Header.SampleFreq <= 0 {
+		return errZeroSampleFreq
+	}
+
+	metadata.duration = ExactDuration(metadata.mp3Header, metadata.frameCount, metadata.encoderDelay, metadata.encoderPadding)
+	metadata.confidence = ConfidenceExact
+
+	if metadata.durationFromXing {
+		metadata.averageBitRate = averageBitRate(metadata.xingBytes, metadata.duration)
+	}
+
+	return nil
+}
+
+// TotalSamples returns the number of PCM samples (per channel) of the audio,
+// excluding the encoder delay and padding when a LAME tag declares them.
+//
+// Returns 0 when the frame count is unknown, i.e. the input has no Xing header
+// and was not read with WithFullScan.
+func (metadata *Metadata) TotalSamples() int64 {
+	if metadata.frameCount == 0 {
+		return 0
+	}
+
+	samples := metadata.frameCount*int64(metadata.mp3Header.SamplesPerFrame()) -
+		int64(metadata.encoderDelay+metadata.encoderPadding)
+
+	if samples < 0 {
+		return 0
+	}
+
+	return samples
+}
+
+// SizeMismatch tells whether GetInfoFromURL found the input shorter than the
+// server declared, with both sizes. The duration is then computed from the
+// actual size, when it's estimated, but can't be trusted.
+func (metadata *Metadata) SizeMismatch() (declared, actual int64, ok bool) {
+	return metadata.declaredSize, metadata.actualSize, metadata.declaredSize > 0
+}
+
+// checkSizeMismatch records that the input is actual bytes long, not declared,
+// re-estimating the duration from it. In strict mode it returns
+// ErrSizeMismatch.
+func (metadata *Metadata) checkSizeMismatch(declared, actual int64, o *options) error {
+	metadata.declaredSize, metadata.actualSize = declared, actual
+
+	msg := fmt.Sprintf("input is %d bytes, not the %d declared by the server", actual, declared)
+
+	if metadata.confidence == ConfidenceEstimated {
+		if err := metadata.calculateDuration(actual); err != nil {
+			return err
+		}
+	}
+
+	metadata.confidence = ConfidenceSuspect
+	metadata.warnings = append(metadata.warnings, msg)
+
+	if o.strict {
+		return fmt.Errorf("%w: %s", ErrSizeMismatch, msg)
+	}
+
+	return nil
+}
+
+// checkPlausibility downgrades confidence when the duration is negative or
+// longer than maxDuration. In strict mode it returns ErrImplausibleDuration.
+func (metadata *Metadata) checkPlausibility(totalSize int64, o *options) error {
+	if metadata.duration >= 0 && metadata.duration <= o.maxDuration {
+		return nil
+	}
+
+	msg := fmt.Sprintf(
+		"duration %s is out of range [0, %s] (total size: %d, tag size: %d, bit rate: %d kbps, channel mode: %d)",
+		metadata.duration, o.maxDuration, totalSize, metadata.tagSize, metadata.mp3Header.BitRate, metadata.mp3Header.ChannelMode,
+	)
+
+	metadata.confidence = ConfidenceSuspect
+	metadata.warnings = append(metadata.warnings, msg)
+
+	if o.strict {
+		return fmt.Errorf("%w: %s", ErrImplausibleDuration, msg)
+	}
+
+	return nil
+}
+
+// Duration returns the duration of the audio, see Confidence for how it was
+// obtained.
+func (metadata *Metadata) Duration() time.Duration {
+	return metadata.duration
+}
+
+// Header returns the header of the first audio frame.
+func (metadata *Metadata) Header() mp3header.MP3Header {
+	return metadata.mp3Header
+}
+
+// DurationFromXing tells whether the duration was computed from the frame
+// count declared by the Xing, Info or VBRI header of the first frame, rather
+// than estimated from the size and the bit rate, or counted with WithFullScan.
+func (metadata *Metadata) DurationFromXing() bool {
+	return metadata.durationFromXing
+}
+
+// VBRHeaderType tells which header the first frame holds in place of audio:
+// "Xing" for a VBR stream, "Info" for a CBR one, both written by LAME, "VBRI"
+// as written by Fraunhofer encoders, or "none".
+func (metadata *Metadata) VBRHeaderType() string {
+	if metadata.vbrHeader == "" {
+		return "none"
+	}
+
+	return metadata.vbrHeader
+}
+
+// VBRHeaderOffset returns the position in the input of the header named by
+// VBRHeaderType, i.e. of its magic, for tools rewriting or stripping it. It's
+// -1 without header.
+func (metadata *Metadata) VBRHeaderOffset() int64 {
+	if metadata.vbrHeader == "" {
+		return -1
+	}
+
+	return metadata.vbrHeaderOffset
+}
+
+// TagSize returns the size of the ID3v2 tags at the start of the input in
+// bytes, headers included, or 0 if there are none.
+func (metadata *Metadata) TagSize() int {
+	return metadata.tagSize
+}
+
+// CRC32 returns the CRC-32 (IEEE) of all the bytes read, which is the whole
+// input when measured with GetInfoOptions.CRC32, and 0 otherwise. For
+// GetInfoRange, it only covers the bytes from the offset on.
+func (metadata *Metadata) CRC32() uint32 {
+	return metadata.crc32
+}
+
+// BytesRead returns how far GetInfo, or GetInfoRange, advanced its reader, by
+// reading or by seeking. Reads are buffered, so it may be past the first frame.
+// A caller streaming the input on can send the bytes copied with WithTee, or
+// as many from the start, then the rest of the reader.
+func (metadata *Metadata) BytesRead() int64 {
+	return metadata.bytesRead
+}
+
+// IsVBR tells whether the bit rate varies between frames. Detected by a full
+// scan, by GetInfoAt from the first frames, or from a "Xing" rather than "Info"
+// header.
+func (metadata *Metadata) IsVBR() bool {
+	return metadata.vbr
+}
+
+// NominalBitrate returns the bit rate of the first frame, in kbps, which is
+// the bit rate of every frame unless IsVBR.
+func (metadata *Metadata) NominalBitrate() int {
+	return metadata.mp3Header.BitRate
+}
+
+// AverageBitrate returns the bit rate of the whole audio, in kbps rounded to
+// the nearest, from its size and duration. Unlike NominalBitrate, it's right
+// for VBR audio.
+//
+// Returns 0 when the duration wasn't computed from the frames, i.e. the input
+// was not read with WithFullScan and has no Xing header with a frame count, as
+// it's then derived from the bit rate.
+func (metadata *Metadata) AverageBitrate() int {
+	return metadata.averageBitRate
+}
+
+// averageBitRate returns the bit rate in kbps of size bytes lasting duration,
+// or 0 when the duration is unknown.
+func averageBitRate(size int64, duration time.Duration) int {
+	if size <= 0 || duration <= 0 {
+		return 0
+	}
+
+	// bits per millisecond are kbps
+	return int(math.Round(float64(size) * 8 / (float64(duration) / float64(time.Millisecond))))
+}
+
+// IncompleteFinalFrame tells whether the input ends in the middle of a frame,
+// as files cut at an arbitrary byte do. Only detected by a full scan.
+func (metadata *Metadata) IncompleteFinalFrame() bool {
+	return metadata.incompleteFrameBytes > 0
+}
+
+// OrphanBytes returns the number of bytes of the incomplete final frame,
+// header included, or 0 if there's none. See IncompleteFinalFrame.
+func (metadata *Metadata) OrphanBytes() int64 {
+	return metadata.incompleteFrameBytes
+}
+
+// checkIncompleteFrame warns about an incomplete final frame, and leaves it out
+// of the frame count with WithTrimIncompleteFrame.
+func (metadata *Metadata) checkIncompleteFrame(o *options) {
+	if metadata.incompleteFrameBytes == 0 {
+		return
+	}
+
+	if o.trimIncompleteFrame {
+		metadata.frameCount--
+	}
+
+	metadata.warnings = append(metadata.warnings, fmt.Sprintf(
+		"the input ends %d bytes into its last frame",
+		metadata.incompleteFrameBytes,
+	))
+}
+
+// gapWarningThreshold is the largest gap after the tag tolerated without a
+// warning, unless strict.
+const gapWarningThreshold = 16
+
+// checkGap warns about bytes between the tag and the first frame, which
+// usually means the tag size is wrong.
+func (metadata *Metadata) checkGap(o *options) {
+	if metadata.rangeOffset > 0 && metadata.tagLocation == TagNone {
+		// a range starting within the tag skips the rest of it
+		return
+	}
+
+	threshold := int64(gapWarningThreshold)
+	if o.strict {
+		threshold = 0
+	}
+
+	if metadata.gapAfterTag > threshold {
+		metadata.warnings = append(metadata.warnings, fmt.Sprintf(
+			"first audio frame found %d bytes after the tag (tag size: %d), the tag size may be wrong",
+			metadata.gapAfterTag, metadata.tagSize,
+		))
+	}
+}
+
+// AudioOffset returns the position of the first audio frame in the input, i.e.
+// the size of the leading tags and of any junk before the frame. Frames of
+// unknown length skipped to find a usable one count as junk.
+func (metadata *Metadata) AudioOffset() int64 {
+	return metadata.audioOffset
+}
+
+// GapAfterTag returns the number of bytes between the end of the leading tag
+// (or the start of the input) and the first audio frame, 0 in the clean case.
+func (metadata *Metadata) GapAfterTag() int64 {
+	return metadata.gapAfterTag
+}
+
+// Confidence tells how far the duration can be trusted.
+func (metadata *Metadata) Confidence() Confidence {
+	return metadata.confidence
+}
+
+// Warnings returns non-fatal problems found while reading the input.
+func (metadata *Metadata) Warnings() []string {
+	return metadata.warnings
+}
+
+// String returns the duration, or with verbose the lines of Report.String.
+//
+// Deprecated: use Duration or Report, whose String it wraps.
+func (metadata *Metadata) String(verbose bool) string {
+	if !verbose {
+		return metadata.duration.String()
+	}
+
+	return metadata.Report().String()
 }
 
 // GetInfo takes a reader, then returns metadata of the MP3, includes estimated duration
 // If the data doesn't seem like an MP3, it returns an error
 //
-// totalSize is int64 to align with FileInfo.Size() and http.Response.ContentLength
-func GetInfo(r io.Reader, totalSize int64) (*Metadata, error) {
-	var metadata Metadata
-	var err error
+// totalSize is int64 to align with FileInfo.Size() and http.Response.ContentLength.
+// When it's negative, i.e. unknown, the whole input is read as with WithFullScan.
+func GetInfo(r io.Reader, totalSize int64, opts ...Option) (*Metadata, error) {
+	return getInfo(r, totalSize, newOptions(opts), new(Metadata))
+}
+
+// FromByteReader adapts br, a source reading a byte at a time, to the
+// io.Reader of GetInfo, e.g. GetInfo(FromByteReader(br), size). GetInfo
+// buffers its reads, so they become calls to ReadByte in a loop.
+func FromByteReader(br io.ByteReader) io.Reader {
+	return readers.FromByteReader(br)
+}
+
+// getInfo fills metadata from r. Fields about the end of the input must be set
+// by the caller beforehand, as r is only read from the start.
+func getInfo(r io.Reader, totalSize int64, o *options, metadata *Metadata) (*Metadata, error) {
+	s, canSeek := seekable(r)
+
+	if o.rewind && !canSeek {
+		return metadata, errors.New("WithRewind needs an input that can seek")
+	}
+
+	var start int64
 
-	skipReader := id3.NewSkipReader(r)
-	metadata.tagSize, err = skipReader.ReadThrough()
+	if canSeek {
+		var err error
+
+		if start, err = s.Seek(0, io.SeekCurrent); err != nil {
+			return metadata, err
+		}
+	}
+
+	if o.readTimeout > 0 {
+		// still seekable if r is
+		r = readers.Timeout(r, o.clock, o.readTimeout)
+	}
+
+	if o.rateLimit > 0 {
+		// still seekable if r is
+		r = readers.RateLimit(o.ctx, r, o.clock, o.rateLimit)
+	}
+
+	// seekable inputs are counted by their position, as tags may be skipped
+	counter := &readCounter{r: r}
+	in := r
+
+	if !canSeek {
+		in = counter
+	}
+
+	if o.tee != nil {
+		// not seekable anymore, so that w sees every byte
+		in = io.TeeReader(in, o.tee)
+	}
+
+	noted := len(metadata.warnings)
+	metadata, err := checksumInfo(in, totalSize, o, metadata)
+
+	if o.warnings != nil {
+		for _, warning := range metadata.warnings[noted:] {
+			o.warnings(warning)
+		}
+	}
+
+	if canSeek {
+		pos, seekErr := s.Seek(0, io.SeekCurrent)
+
+		if seekErr == nil {
+			metadata.bytesRead = pos - start
+		}
+
+		if o.rewind {
+			_, seekErr = s.Seek(start, io.SeekStart)
+		}
+
+		if err == nil {
+			err = seekErr
+		}
+	} else {
+		metadata.bytesRead = counter.n
+	}
+
+	return metadata, err
+}
+
+// readCounter counts the bytes read from r.
+type readCounter struct {
+	r io.Reader
+	n int64
+}
+
+func (c *readCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// checksumInfo is readInfo, but with the CRC32 option, the rest of r is read
+// after the measurement, so that every byte goes through the hash once.
+func checksumInfo(r io.Reader, totalSize int64, o *options, metadata *Metadata) (*Metadata, error) {
+	if !o.crc32 {
+		return readInfo(r, totalSize, o, metadata)
+	}
+
+	hash := crc32.NewIEEE()
+	metadata, err := readInfo(io.TeeReader(r, hash), totalSize, o, metadata)
 
 	if err != nil {
-		return &metadata, err
+		return metadata, err
+	}
+
+	if _, err = io.Copy(hash, readers.GuardProgress(r)); err != nil {
+		return metadata, err
+	}
+
+	metadata.crc32 = hash.Sum32()
+
+	return metadata, nil
+}
+
+// readInfo does the work of getInfo.
+func readInfo(r io.Reader, totalSize int64, o *options, metadata *Metadata) (*Metadata, error) {
+	// negative when unknown
+	if totalSize >= 0 && totalSize < minSize {
+		return metadata, ErrTooSmall
+	}
+
+	var br *bufio.Reader
+
+	if s, ok := seekable(r); ok {
+		// so that tags are skipped by seeking, e.g. over a cloud object
+		bs := readers.NewBufferedSeeker(readers.GuardProgress(r), s)
+		br, r = bs.Reader, bs
+	} else {
+		br = bufio.NewReader(readers.GuardProgress(r))
+		r = br
+	}
+
+	var err error
+
+	// Enough to see "ID3" behind a BOM. Errors are left for the reads below.
+	start, _ := br.Peek(6)
+
+	if err = sniffContainer(start); err != nil {
+		return metadata, err
+	}
+
+	if id3.HasLeadingTag(start, false) {
+		skipReader := id3.NewSkipReader(r)
+		skipReader.BufferPool = o.bufferPool
+		skipReader.OnFrame = o.onFrame
+		skipReader.MaxFrames, skipReader.MaxFrameBytes = o.tagMaxFrames, o.tagMaxFrameBytes
+		_, err = skipReader.ReadThrough()
+		metadata.tagSize = skipReader.BytesRead()
+		metadata.tagVersion, _ = skipReader.Version()
+		metadata.tagLocation = TagPrepended
+
+		if err = metadata.checkTagLimits(err, o); err != nil {
+			return metadata, err
+		}
+
+		if err = metadata.skipStackedTags(br, totalSize, o); err != nil {
+			return metadata, err
+		}
+	}
+
+	// Would be skipped as garbage, until a false frame sync within the AAC
+	if data, _ := br.Peek(7); isADTS(data) {
+		return metadata, ErrNotMP3Format{DetectedFormat: "aac"}
 	}
 
 	// Read MP3 frame header
-	var headerBits uint32
+	var skipped int64
+
+	if metadata.mp3Header, metadata.gapAfterTag, skipped, err = findUsableFrame(br, o.maxScanBytes); err != nil {
+		return metadata, err
+	}
+
+	metadata.audioOffset = metadata.rangeOffset + int64(metadata.tagSize) + metadata.gapAfterTag + skipped
+	metadata.checkGap(o)
+
+	if skipped > 0 {
+		metadata.warnings = append(metadata.warnings, fmt.Sprintf(
+			"skipped %d bytes of frames of unknown length (free format or bad bit rate) before the first usable one",
+			skipped,
+		))
+	}
+
+	for _, require := range o.requirements {
+		if err = require(metadata.mp3Header); err != nil {
+			return metadata, err
+		}
+	}
+
+	var headers vbrHeaders
+
+	if length := metadata.mp3Header.FrameLength(); length > 4 {
+		// within the buffer, which is larger than any frame
+		data, _ := br.Peek(length - 4)
+		headers = metadata.parseVBRHeaders(data)
+	}
+
+	// Without the size, the duration can only be found by counting frames.
+	if o.fullScan || totalSize < 0 {
+		// frames can't be walked either, their length is unknown
+		if metadata.mp3Header.SampleFreq <= 0 {
+			return metadata, errZeroSampleFreq
+		}
+
+		var frameBytes int64
+
+		if metadata.frameCount, metadata.vbr, metadata.incompleteFrameBytes, frameBytes, err = walkFrames(r, metadata.mp3Header, headers.headerFrame()); err != nil {
+			return metadata, err
+		}
+
+		if err = metadata.useScan(frameBytes, headers, totalSize, o); err != nil {
+			return metadata, err
+		}
+	} else if headers.declaresFrames() {
+		if err = metadata.useDeclaredHeader(headers, totalSize, o); err != nil {
+			return metadata, err
+		}
+	} else {
+		if o.readerAt != nil {
+			if err = metadata.sampleVBR(o.readerAt, headers.headerFrame()); err != nil {
+				return metadata, err
+			}
+		}
+
+		if err = metadata.estimate(totalSize, o); err != nil {
+			return metadata, err
+		}
+	}
+
+	if err = metadata.checkVBRHeaders(headers, totalSize, o); err != nil {
+		return metadata, err
+	}
+
+	return metadata, nil
+}
+
+// seekable returns r as an io.Seeker if it can actually seek, unlike e.g. an
+// *os.File of a pipe.
+func seekable(r io.Reader) (io.Seeker, bool) {
+	s, ok := r.(io.Seeker)
+
+	if !ok {
+		return nil, false
+	}
+
+	if _, err := s.Seek(0, io.SeekCurrent); err != nil {
+		return nil, false
+	}
+
+	return s, true
+}
+
+// skipStackedTags skips further ID3v2 tags right after the leading one, as
+// written by some taggers, adding them to tagSize. A tag is only looked for at
+// the end of the previous one, never inside it, and must fit in the input.
+func (metadata *Metadata) skipStackedTags(br *bufio.Reader, totalSize int64, o *options) error {
+	for {
+		header, _ := br.Peek(10)
+		size, _, err := id3.ParseHeader(header)
+
+		if err != nil {
+			return nil
+		}
 
-	if err = binary.Read(r, binary.BigEndian, &headerBits); err != nil {
-		return &metadata, err
+		remaining := totalSize - metadata.rangeOffset - int64(metadata.tagSize)
+
+		if totalSize >= 0 && int64(size) > remaining {
+			// not a tag, but audio or garbage that happens to start with "ID3"
+			return nil
+		}
+
+		skipReader := id3.NewSkipReader(br)
+		skipReader.BufferPool = o.bufferPool
+		skipReader.OnFrame = o.onFrame
+		skipReader.MaxFrames, skipReader.MaxFrameBytes = o.tagMaxFrames, o.tagMaxFrameBytes
+		_, err = skipReader.ReadThrough()
+		metadata.tagSize += skipReader.BytesRead()
+
+		if err = metadata.checkTagLimits(err, o); err != nil {
+			return err
+		}
+	}
+}
+
+// checkTagLimits turns err, from skipping an ID3v2 tag, into a warning when
+// it's an ErrTagLimits, as the tag was still skipped. In strict mode, it's
+// returned.
+func (metadata *Metadata) checkTagLimits(err error, o *options) error {
+	var limits ErrTagLimits
+
+	if !errors.As(err, &limits) || o.strict {
+		return err
 	}
 
-	if metadata.mp3Header, err = mp3header.Parse(headerBits); err != nil {
-		return &metadata, err
+	metadata.warnings = append(metadata.warnings, fmt.Sprintf("%v, the rest was skipped", limits))
+
+	return nil
+}
+
+func (metadata *Metadata) tagVersionName() string {
+	if metadata.tagVersion == 0 {
+		return ""
 	}
 
-	metadata.calculateDuration(totalSize)
+	return fmt.Sprintf("ID3v2.%d", metadata.tagVersion)
+}
+
+// GetInfoAt is like GetInfo, but reads from a random access input of the given
+// size. Unlike GetInfo, it can find an ID3v2.4 tag appended at the end of the
+// input, or before a trailing ID3v1 tag, and exclude it from the audio. Without
+// ID3v2 tag, it reads the trailing ID3v1 tag for Title and the like, and
+// excludes it from the audio too. It also
+// samples the headers of the first frames, see QuickVBRCheck, and estimates
+// the duration of VBR inputs from their average bit rate.
+//
+// It's the way to measure objects in cloud storage, with ra adapting ranged GET
+// requests: only the start of the input, the first frames and the trailing
+// tags are read. Leading tags, artwork included, are skipped without reading.
+//
+// A gzip-compressed input is measured from its uncompressed content, whose
+// size is read from the gzip trailer, without looking for trailing tags nor
+// sampling frames.
+func GetInfoAt(ra io.ReaderAt, size int64, opts ...Option) (*Metadata, error) {
+	metadata := new(Metadata)
+
+	start := make([]byte, 6)
+	n, err := ra.ReadAt(start, 0)
+
+	if err != nil && err != io.EOF {
+		return metadata, err
+	}
+
+	o := newOptions(opts)
+
+	if hasGzipMagic(start[:n]) {
+		return getInfoGzip(ra, size, o)
+	}
+
+	metadata.appendedTagOffset, metadata.appendedTagSize, err = findAppendedTag(ra, size)
+
+	if err != nil {
+		return metadata, err
+	}
+
+	leading := id3.HasLeadingTag(start[:n], false)
+
+	// a leading tag takes precedence
+	if metadata.appendedTagSize > 0 && !leading {
+		metadata.tagLocation = TagAppended
+	}
+
+	// the only tag, then
+	if metadata.appendedTagSize == 0 && !leading {
+		if metadata.v1Tag, err = readV1Tag(ra, size); err != nil {
+			return metadata, err
+		}
+	}
+
+	o.readerAt = ra
+
+	return getInfo(io.NewSectionReader(ra, 0, size), size, o, metadata)
+}
+
+// GetInfoRange is like GetInfo, but r only holds the input from offset on, e.g.
+// the body of an HTTP 206 response, and totalSize is the size of the whole
+// input. offset must not be past the first audio frame; when it's within the
+// ID3 tag, the rest of the tag is skipped like a gap, within the scan limit of
+// GetInfoOptions.
+func GetInfoRange(r io.Reader, offset int64, totalSize int64, opts ...Option) (*Metadata, error) {
+	if offset < 0 || totalSize >= 0 && offset > totalSize {
+		return nil, fmt.Errorf("range offset %d is out of [0, %d]", offset, totalSize)
+	}
+
+	return getInfo(r, totalSize, newOptions(opts), &Metadata{rangeOffset: offset})
+}
+
+// GetInfoFromFile opens the file at path and returns metadata of the MP3, like
+// GetInfoAt. When path isn't a regular file, e.g. a named pipe, its size is
+// unknown and every frame is counted instead, like GetInfo with a negative
+// totalSize.
+func GetInfoFromFile(path string, opts ...Option) (*Metadata, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	stat, err := f.Stat()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !stat.Mode().IsRegular() {
+		// FIFOs, sockets and devices report no meaningful size and can't be
+		// read at random offsets.
+		return GetInfo(f, -1, opts...)
+	}
+
+	return GetInfoAt(f, stat.Size(), opts...)
+}
 
-	return &metadata, nil
+// TagLocation tells where the ID3v2 tag was found.
+func (metadata *Metadata) TagLocation() TagLocation {
+	return metadata.tagLocation
 }
@@ -0,0 +1,947 @@
+package mp3len
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"mp3len/internal/id3"
+	"mp3len/internal/mp3header"
+)
+
+// MPEG-1 Layer III, 128 kbps, 44100Hz, joint stereo, no CRC
+const testHeaderBits = 0xFFFB9044
+const testFrameLength = 417 // 144 * 128000 / 44100
+
+var emptyTag = []byte("ID3\x03\x00\x00\x00\x00\x00\x00")
+
+// generateMP3 builds an MP3 stream of tag followed by count audio frames, each
+// starting with headerBits and padded with zero bytes to frameLength.
+func generateMP3(tag []byte, headerBits uint32, frameLength int, count int) []byte {
+	var buf bytes.Buffer
+	buf.Write(tag)
+
+	frame := make([]byte, frameLength)
+	binary.BigEndian.PutUint32(frame, headerBits)
+
+	for i := 0; i < count; i++ {
+		buf.Write(frame)
+	}
+
+	return buf.Bytes()
+}
+
+func TestGetInfo(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+
+	metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	if metadata.tagSize != 10 {
+		t.Errorf("GetInfo() tagSize = %v, want %v", metadata.tagSize, 10)
+	}
+
+	if want := 2606 * time.Millisecond; metadata.duration != want {
+		t.Errorf("GetInfo() duration = %v, want %v", metadata.duration, want)
+	}
+}
+
+func TestGetInfo_ImplausibleDuration(t *testing.T) {
+	// MPEG-2 Layer III, 8 kbps, 22050Hz, mono
+	const lowRateHeaderBits = 0xFFF310C4
+	// 100 bytes of padding in the tag, so that a small total size leaves less
+	// than nothing for the audio
+	tag := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x64"), make([]byte, 100)...)
+	data := generateMP3(tag, lowRateHeaderBits, 26, 10)
+
+	tests := []struct {
+		name           string
+		totalSize      int64
+		opts           []Option
+		wantConfidence Confidence
+		wantErr        error
+	}{
+		{"plausible", int64(len(data)), nil, ConfidenceEstimated, nil},
+		{"exceeds default ceiling", 2 << 30, nil, ConfidenceSuspect, nil},
+		{"exceeds custom ceiling", int64(len(data)), []Option{WithMaxDuration(time.Millisecond)}, ConfidenceSuspect, nil},
+		{"negative", 50, nil, ConfidenceSuspect, nil},
+		{"strict", 2 << 30, []Option{WithStrict()}, ConfidenceSuspect, ErrImplausibleDuration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := GetInfo(bytes.NewReader(data), tt.totalSize, tt.opts...)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetInfo() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if metadata.Confidence() != tt.wantConfidence {
+				t.Errorf("GetInfo() confidence = %v, want %v", metadata.Confidence(), tt.wantConfidence)
+			}
+
+			if wantWarnings := tt.wantConfidence == ConfidenceSuspect; (len(metadata.Warnings()) > 0) != wantWarnings {
+				t.Errorf("GetInfo() warnings = %q", metadata.Warnings())
+			}
+		})
+	}
+}
+
+func TestGetInfo_FullScan(t *testing.T) {
+	var data []byte
+	data = append(data, generateMP3(emptyTag, testHeaderBits, testFrameLength, 99)...)
+	data = append(data, generateMP3(nil, testHeaderBits|0x200, testFrameLength+1, 1)...) // padded frame
+	data = append(data, []byte("TAG")...)                                                // looks like an ID3v1 trailer
+
+	metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)), WithFullScan())
+
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	if metadata.frameCount != 100 {
+		t.Errorf("GetInfo() frameCount = %v, want %v", metadata.frameCount, 100)
+	}
+
+	if want := int64(100 * 1152); metadata.TotalSamples() != want {
+		t.Errorf("TotalSamples() = %v, want %v", metadata.TotalSamples(), want)
+	}
+
+	// 115200 samples / 44100Hz
+	if want := 2612244897 * time.Nanosecond; metadata.duration != want {
+		t.Errorf("GetInfo() duration = %v, want %v", metadata.duration, want)
+	}
+
+	if metadata.Confidence() != ConfidenceExact {
+		t.Errorf("GetInfo() confidence = %v, want %v", metadata.Confidence(), ConfidenceExact)
+	}
+}
+
+func TestGetInfo_FullScanJunk(t *testing.T) {
+	audio := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+
+	tests := []struct {
+		name       string
+		headerBits uint32
+	}{
+		{"bad bit rate", 0xFFFBF044},
+		{"free format", 0xFFFB0044},
+		{"reserved sample rate", 0xFFFB9C44},
+		{"other sample rate", 0xFFFB9444},
+		{"other layer", 0xFFFD9044},
+		{"MPEG-2", 0xFFF39044},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			junk := make([]byte, 4+testFrameLength)
+			binary.BigEndian.PutUint32(junk, tt.headerBits)
+			copy(junk[4:], "APETAGEX")
+			data := append(append([]byte{}, audio...), junk...)
+
+			for _, size := range []int64{int64(len(data)), -1} {
+				metadata, err := GetInfo(bytes.NewReader(data), size, WithFullScan())
+
+				if err != nil {
+					t.Fatalf("GetInfo(size %d) error = %v", size, err)
+				}
+
+				if metadata.frameCount != 100 {
+					t.Errorf("GetInfo(size %d) frameCount = %v, want 100", size, metadata.frameCount)
+				}
+			}
+
+			d := NewIncremental(int64(len(data)), WithFullScan())
+			d.Write(data)
+
+			if metadata, err := d.Result(); err != nil || metadata.frameCount != 100 {
+				t.Errorf("Incremental Result() = %v frames, %v, want 100, nil", metadata.frameCount, err)
+			}
+		})
+	}
+}
+
+func TestGetInfo_IncompleteFinalFrame(t *testing.T) {
+	complete := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+
+	header, err := mp3header.Parse(testHeaderBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		data           []byte
+		opts           []Option
+		wantIncomplete bool
+		wantOrphan     int64
+		wantFrames     int64
+	}{
+		{"complete", complete, nil, false, 0, 100},
+		{"cut mid-frame", complete[:len(complete)-100], nil, true, testFrameLength - 100, 100},
+		{"cut mid-frame, trimmed", complete[:len(complete)-100], []Option{WithTrimIncompleteFrame()}, true, testFrameLength - 100, 99},
+		{"cut after a frame header", complete[:len(complete)-testFrameLength+4], []Option{WithTrimIncompleteFrame()}, true, 4, 99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithFullScan()}, tt.opts...)
+			metadata, err := GetInfo(bytes.NewReader(tt.data), int64(len(tt.data)), opts...)
+
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if metadata.IncompleteFinalFrame() != tt.wantIncomplete || metadata.OrphanBytes() != tt.wantOrphan {
+				t.Errorf("GetInfo() IncompleteFinalFrame(), OrphanBytes() = %v, %v, want %v, %v",
+					metadata.IncompleteFinalFrame(), metadata.OrphanBytes(), tt.wantIncomplete, tt.wantOrphan)
+			}
+
+			if metadata.frameCount != tt.wantFrames {
+				t.Errorf("GetInfo() frameCount = %v, want %v", metadata.frameCount, tt.wantFrames)
+			}
+
+			// one frame shorter when trimmed
+			if want := ExactDuration(header, tt.wantFrames, 0, 0); metadata.Duration() != want {
+				t.Errorf("GetInfo() Duration() = %v, want %v", metadata.Duration(), want)
+			}
+
+			if (len(metadata.Warnings()) > 0) != tt.wantIncomplete {
+				t.Errorf("GetInfo() Warnings() = %v, want a warning %v", metadata.Warnings(), tt.wantIncomplete)
+			}
+		})
+	}
+}
+
+func TestGetInfo_FullScanLAME(t *testing.T) {
+	// an Info frame declaring the 100 audio frames after it, and a LAME tag
+	// of 576 samples of delay and 1000 of padding
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 101)
+	xing := data[len(emptyTag)+4+32:]
+	copy(xing, "Info")
+	binary.BigEndian.PutUint32(xing[4:], 0x1)
+	binary.BigEndian.PutUint32(xing[8:], 100)
+	copy(xing[12:], "LAME3.100")
+	xing[12+21], xing[12+22], xing[12+23] = 576>>4, 576<<4&0xF0|1000>>8, 1000&0xFF
+
+	want := int64(100*1152 - 576 - 1000)
+
+	tests := []struct {
+		name string
+		size int64
+		opts []Option
+	}{
+		{"from Info", int64(len(data)), nil},
+		{"full scan", int64(len(data)), []Option{WithFullScan()}},
+		{"unknown size", -1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := GetInfo(bytes.NewReader(data), tt.size, tt.opts...)
+
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if got := metadata.TotalSamples(); got != want {
+				t.Errorf("TotalSamples() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetInfo_Mono(t *testing.T) {
+	// testHeaderBits, but mono: as long frames, estimated twice as long
+	data := generateMP3(emptyTag, testHeaderBits|0xC0, testFrameLength, 100)
+	metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	if want := 2 * 2606 * time.Millisecond; metadata.Duration() != want {
+		t.Errorf("Duration() = %v, want %v", metadata.Duration(), want)
+	}
+}
+
+func TestMetadata_TotalSamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata Metadata
+		want     int64
+	}{
+		{"unknown frame count", Metadata{}, 0},
+		{"MPEG-2 Layer III", Metadata{frameCount: 10, mp3Header: mp3header.MP3Header{AudioVersion: mp3header.Version2, Layer: mp3header.Layer3}}, 5760},
+		{"LAME delay and padding", Metadata{frameCount: 10, encoderDelay: 576, encoderPadding: 1000, mp3Header: mp3header.MP3Header{AudioVersion: mp3header.Version1, Layer: mp3header.Layer3}}, 9944},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.metadata.TotalSamples(); got != tt.want {
+				t.Errorf("TotalSamples() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// stuckAfterReader serves data, then returns (0, nil) forever.
+type stuckAfterReader struct {
+	r io.Reader
+}
+
+func (s *stuckAfterReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err == io.EOF {
+		return 0, nil
+	}
+	return n, err
+}
+
+func TestGetInfo_NoProgress(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 1)
+	r := &stuckAfterReader{r: bytes.NewReader(data[:12])}
+
+	_, err := GetInfo(r, int64(len(data)))
+
+	if !errors.Is(err, io.ErrNoProgress) {
+		t.Errorf("GetInfo() error = %v, want %v", err, io.ErrNoProgress)
+	}
+}
+
+func mustParseHeader(t *testing.T, headerBits uint32) mp3header.MP3Header {
+	h, err := mp3header.Parse(headerBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestGetInfo_TooSmall(t *testing.T) {
+	for _, data := range [][]byte{{}, []byte("ID3\x03\x00")} {
+		_, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+
+		if err != ErrTooSmall {
+			t.Errorf("GetInfo() of %d bytes error = %v, want %v", len(data), err, ErrTooSmall)
+		}
+	}
+
+	if _, err := GetInfoAt(bytes.NewReader(nil), 0); err != ErrTooSmall {
+		t.Errorf("GetInfoAt() of 0 bytes error = %v, want %v", err, ErrTooSmall)
+	}
+}
+
+func TestGetInfo_GapAfterTag(t *testing.T) {
+	tests := []struct {
+		name         string
+		gap          int
+		opts         []Option
+		wantWarnings int
+	}{
+		{"no gap", 0, nil, 0},
+		{"small gap", 2, nil, 0},
+		{"small gap, strict", 2, []Option{WithStrict()}, 1},
+		{"large gap", 5000, nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := append(append([]byte{}, emptyTag...), make([]byte, tt.gap)...)
+			data := generateMP3(tag, testHeaderBits, testFrameLength, 100)
+
+			metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)), tt.opts...)
+
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if got := metadata.GapAfterTag(); got != int64(tt.gap) {
+				t.Errorf("GetInfo() GapAfterTag() = %v, want %v", got, tt.gap)
+			}
+
+			if got, want := metadata.AudioOffset(), int64(len(tag)); got != want {
+				t.Errorf("GetInfo() AudioOffset() = %v, want %v", got, want)
+			}
+
+			if got := len(metadata.Warnings()); got != tt.wantWarnings {
+				t.Errorf("GetInfo() Warnings() = %v, want %d warnings", metadata.Warnings(), tt.wantWarnings)
+			}
+
+			if want := 2606 * time.Millisecond; metadata.Duration() != want {
+				t.Errorf("GetInfo() Duration() = %v, want %v", metadata.Duration(), want)
+			}
+		})
+	}
+}
+
+func TestGetInfo_NoFrameSync(t *testing.T) {
+	data := append(append([]byte{}, emptyTag...), bytes.Repeat([]byte{0xAB}, DefaultMaxScanBytes+100)...)
+
+	_, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+
+	if !errors.Is(err, ErrNoFrame) {
+		t.Errorf("GetInfo() error = %v, want %v", err, ErrNoFrame)
+	}
+}
+
+func TestGetInfo_MaxScanBytes(t *testing.T) {
+	tests := []struct {
+		name         string
+		gap          int
+		maxScanBytes int
+		wantErr      error
+	}{
+		{"within the default", 5000, 0, nil},
+		{"within the limit", 5000, 8192, nil},
+		{"beyond the limit", 5000, 4096, ErrNoFrame},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := append(append([]byte{}, emptyTag...), make([]byte, tt.gap)...)
+			data := generateMP3(tag, testHeaderBits, testFrameLength, 100)
+
+			_, err := GetInfo(bytes.NewReader(data), int64(len(data)), GetInfoOptions{MaxScanBytes: tt.maxScanBytes})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetInfo() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetInfoRange(t *testing.T) {
+	// 100 bytes of padding after the header
+	tag := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x64"), make([]byte, 100)...)
+	data := generateMP3(tag, testHeaderBits, testFrameLength, 100)
+	total := int64(len(data))
+
+	tests := []struct {
+		name    string
+		offset  int64
+		want    time.Duration
+		wantErr bool
+	}{
+		{"whole input", 0, 2606 * time.Millisecond, false},
+		{"within tag", 40, 2606 * time.Millisecond, false},
+		{"at first frame", 110, 2606 * time.Millisecond, false},
+		{"past the end", total + 1, 0, true},
+		{"negative", -1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r io.Reader
+			if tt.offset >= 0 && tt.offset <= total {
+				r = bytes.NewReader(data[tt.offset:])
+			}
+
+			metadata, err := GetInfoRange(r, tt.offset, total)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetInfoRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if metadata.Duration() != tt.want {
+				t.Errorf("GetInfoRange() Duration() = %v, want %v", metadata.Duration(), tt.want)
+			}
+
+			if len(metadata.Warnings()) != 0 {
+				t.Errorf("GetInfoRange() Warnings() = %v, want none", metadata.Warnings())
+			}
+		})
+	}
+}
+
+func TestGetInfo_Require(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 10)
+	errRejected := errors.New("rejected")
+
+	tests := []struct {
+		name    string
+		require func(mp3header.MP3Header) error
+		wantErr error
+	}{
+		{"accepted", func(h mp3header.MP3Header) error { return nil }, nil},
+		{"rejected", func(h mp3header.MP3Header) error { return errRejected }, errRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetInfo(bytes.NewReader(data), int64(len(data)), WithRequire(tt.require))
+
+			if err != tt.wantErr {
+				t.Errorf("GetInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func BenchmarkGetInfo_LargeTag(b *testing.B) {
+	// 1 MiB of padding in the tag
+	tag := append([]byte("ID3\x03\x00\x00\x00\x40\x00\x00"), make([]byte, 1<<20)...)
+	data := generateMP3(tag, testHeaderBits, testFrameLength, 100)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := GetInfo(bytes.NewReader(data), int64(len(data))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// countingPool is a BufferPool counting buffers handed out and given back.
+type countingPool struct {
+	mu   sync.Mutex
+	gets int
+	puts int
+}
+
+func (p *countingPool) Get(n int) []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.gets++
+
+	return make([]byte, n)
+}
+
+func (p *countingPool) Put(b []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.puts++
+}
+
+func TestGetInfo_BufferPool(t *testing.T) {
+	tag := append([]byte("ID3\x03\x00\x00\x00\x01\x00\x00"), make([]byte, 1<<14)...)
+	data := generateMP3(tag, testHeaderBits, testFrameLength, 100)
+	pool := new(countingPool)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// not seekable, so that the tag is discarded through the pool
+			r := struct{ io.Reader }{bytes.NewReader(data)}
+
+			if _, err := GetInfo(r, int64(len(data)), WithBufferPool(pool)); err != nil {
+				t.Errorf("GetInfo() error = %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if pool.gets == 0 || pool.gets != pool.puts {
+		t.Errorf("BufferPool got %d Get and %d Put calls, want as many of each", pool.gets, pool.puts)
+	}
+}
+
+func TestGetInfo_UnknownSize(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+
+	metadata, err := GetInfo(bytes.NewReader(data), -1)
+
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	if metadata.Confidence() != ConfidenceExact {
+		t.Errorf("GetInfo() Confidence() = %v, want %v", metadata.Confidence(), ConfidenceExact)
+	}
+
+	// 100 * 1152 samples at 44100Hz
+	if want := 2612244897 * time.Nanosecond; metadata.Duration() != want {
+		t.Errorf("GetInfo() Duration() = %v, want %v", metadata.Duration(), want)
+	}
+}
+
+// byteOnlyReader implements io.ByteReader, and nothing else.
+type byteOnlyReader struct {
+	r *bytes.Reader
+}
+
+func (b byteOnlyReader) ReadByte() (byte, error) {
+	return b.r.ReadByte()
+}
+
+func TestGetInfo_ByteReader(t *testing.T) {
+	title := id3.Frame{ID: "TIT2", Data: []byte("\x00Title\x00")}
+	data := generateMP3(generateTag(t, title), testHeaderBits, testFrameLength, 100)
+
+	tests := []struct {
+		name       string
+		totalSize  int64
+		opts       []Option
+		want       time.Duration
+		confidence Confidence
+	}{
+		{"estimated", int64(len(data)), nil, 2606 * time.Millisecond, ConfidenceEstimated},
+		{"unknown size", -1, nil, 2612244897 * time.Nanosecond, ConfidenceExact},
+		{"strict full scan", int64(len(data)), []Option{WithStrict(), WithFullScan()}, 2612244897 * time.Nanosecond, ConfidenceExact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := FromByteReader(byteOnlyReader{bytes.NewReader(data)})
+
+			metadata, err := GetInfo(r, tt.totalSize, tt.opts...)
+
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if metadata.Duration() != tt.want || metadata.Confidence() != tt.confidence {
+				t.Errorf("GetInfo() = %v (%v), want %v (%v)", metadata.Duration(), metadata.Confidence(), tt.want, tt.confidence)
+			}
+		})
+	}
+}
+
+func TestGetInfo_UnusableFirstFrame(t *testing.T) {
+	tests := []struct {
+		name         string
+		firstBits    uint32
+		followed     bool
+		wantErr      bool
+		wantOffset   int64
+		wantWarnings int
+	}{
+		{"free format", 0xFFFB0044, true, false, 10 + 300, 1},
+		{"bad bit rate index", 0xFFFBF044, true, false, 10 + 300, 1},
+		{"free format only", 0xFFFB0044, false, true, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := generateMP3(emptyTag, tt.firstBits, 300, 1)
+
+			if tt.followed {
+				data = append(data, generateMP3(nil, testHeaderBits, testFrameLength, 100)...)
+			}
+
+			metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := metadata.AudioOffset(); got != tt.wantOffset {
+				t.Errorf("GetInfo() AudioOffset() = %v, want %v", got, tt.wantOffset)
+			}
+
+			if got := len(metadata.Warnings()); got != tt.wantWarnings {
+				t.Errorf("GetInfo() Warnings() = %v, want %d warnings", metadata.Warnings(), tt.wantWarnings)
+			}
+
+			if want := 2606 * time.Millisecond; metadata.Duration() != want {
+				t.Errorf("GetInfo() Duration() = %v, want %v", metadata.Duration(), want)
+			}
+		})
+	}
+}
+
+func TestGetInfo_CRC32(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	data = append(data, append([]byte("TAG"), make([]byte, 125)...)...)
+	want := crc32.ChecksumIEEE(data)
+
+	tests := []struct {
+		name    string
+		getInfo func(opts ...Option) (*Metadata, error)
+		opts    []Option
+		want    uint32
+	}{
+		{"GetInfo", func(opts ...Option) (*Metadata, error) {
+			return GetInfo(bytes.NewReader(data), int64(len(data)), opts...)
+		}, []Option{GetInfoOptions{CRC32: true}}, want},
+		{"GetInfo, full scan", func(opts ...Option) (*Metadata, error) {
+			return GetInfo(bytes.NewReader(data), int64(len(data)), opts...)
+		}, []Option{GetInfoOptions{CRC32: true}, WithFullScan()}, want},
+		{"GetInfoAt", func(opts ...Option) (*Metadata, error) {
+			return GetInfoAt(bytes.NewReader(data), int64(len(data)), opts...)
+		}, []Option{GetInfoOptions{CRC32: true}}, want},
+		{"disabled", func(opts ...Option) (*Metadata, error) {
+			return GetInfo(bytes.NewReader(data), int64(len(data)), opts...)
+		}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.getInfo(tt.opts...)
+
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			if metadata.CRC32() != tt.want {
+				t.Errorf("CRC32() = %08X, want %08X", metadata.CRC32(), tt.want)
+			}
+		})
+	}
+}
+
+// rangeRecorder is an io.ReaderAt recording the ranges read, like a cloud
+// object store would see them as ranged GET requests.
+type rangeRecorder struct {
+	ra     io.ReaderAt
+	ranges [][2]int64 // start and end of each read
+}
+
+func (r *rangeRecorder) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.ra.ReadAt(p, off)
+	r.ranges = append(r.ranges, [2]int64{off, off + int64(n)})
+
+	return n, err
+}
+
+func TestGetInfoAt_ReadRanges(t *testing.T) {
+	title := id3.Frame{ID: "TIT2"}
+	if err := title.SetText("Title"); err != nil {
+		t.Fatal(err)
+	}
+
+	artwork := id3.Frame{ID: "APIC", Data: make([]byte, 1<<20)}
+	audio := generateMP3(nil, testHeaderBits, testFrameLength, 20000)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"untagged", audio},
+		{"tag with artwork", append(generateTag(t, title, artwork), audio...)},
+		{"appended tag", append(append([]byte{}, audio...), generateAppendedTag(t, title)...)},
+		{"ID3v1 tag", append(append([]byte{}, audio...), generateID3v1("Title")...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := int64(len(tt.data))
+			recorder := &rangeRecorder{ra: bytes.NewReader(tt.data)}
+
+			metadata, err := GetInfoAt(recorder, size)
+
+			if err != nil {
+				t.Fatalf("GetInfoAt() error = %v", err)
+			}
+
+			// the start of the input, the first frames and the trailing tags
+			const startSize, headSize, tailSize = 4 << 10, 16 << 10, 256
+			read := int64(0)
+
+			for _, r := range recorder.ranges {
+				read += r[1] - r[0]
+
+				inStart := r[1] <= startSize
+				inHead := r[0] >= metadata.audioOffset && r[1] <= metadata.audioOffset+headSize
+				inTail := r[0] >= size-tailSize
+
+				if !inStart && !inHead && !inTail {
+					t.Errorf("GetInfoAt() read %d-%d of %d bytes, audio at %d", r[0], r[1], size, metadata.audioOffset)
+				}
+			}
+
+			if read > startSize+headSize {
+				t.Errorf("GetInfoAt() read %d bytes, want at most %d", read, startSize+headSize)
+			}
+		})
+	}
+}
+
+func TestGetInfo_Tee(t *testing.T) {
+	data := generateMP3(generateTag(t, id3.Frame{ID: "APIC", Data: make([]byte, 10000)}), testHeaderBits, testFrameLength, 100)
+
+	tests := []struct {
+		name string
+		r    io.Reader
+		opts []Option
+	}{
+		{"seekable", bytes.NewReader(data), nil},
+		{"not seekable", struct{ io.Reader }{bytes.NewReader(data)}, nil},
+		{"with CRC32", bytes.NewReader(data), []Option{GetInfoOptions{CRC32: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prefix bytes.Buffer
+
+			metadata, err := GetInfo(tt.r, int64(len(data)), append(tt.opts, WithTee(&prefix))...)
+
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if metadata.BytesRead() != int64(prefix.Len()) {
+				t.Errorf("GetInfo() BytesRead() = %v, want %v copied", metadata.BytesRead(), prefix.Len())
+			}
+
+			// what a client would receive
+			got, err := ioutil.ReadAll(io.MultiReader(&prefix, tt.r))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, data) {
+				t.Errorf("prefix and rest of input = %d bytes, want the %d bytes of the input", len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestGetInfo_Rewind(t *testing.T) {
+	data := generateMP3(generateTag(t, id3.Frame{ID: "APIC", Data: make([]byte, 10000)}), testHeaderBits, testFrameLength, 100)
+
+	for _, opts := range [][]Option{{WithRewind()}, {WithRewind(), WithFullScan()}, {WithRewind(), WithTee(ioutil.Discard)}} {
+		r := bytes.NewReader(data)
+		metadata, err := GetInfo(r, int64(len(data)), opts...)
+
+		if err != nil {
+			t.Fatalf("GetInfo() error = %v", err)
+		}
+
+		if metadata.BytesRead() == 0 {
+			t.Errorf("GetInfo() BytesRead() = 0, want the bytes read before rewinding")
+		}
+
+		if got, _ := ioutil.ReadAll(r); !bytes.Equal(got, data) {
+			t.Errorf("input after GetInfo() = %d bytes, want the %d bytes of the input", len(got), len(data))
+		}
+	}
+
+	if _, err := GetInfo(struct{ io.Reader }{bytes.NewReader(data)}, int64(len(data)), WithRewind()); err == nil {
+		t.Errorf("GetInfo() of a reader that can't seek error = nil, want error")
+	}
+}
+
+func TestGetInfoOptions_Warnings(t *testing.T) {
+	audio := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	junk := generateMP3(append(append([]byte{}, emptyTag...), make([]byte, 100)...), testHeaderBits, testFrameLength, 100)
+
+	tests := []struct {
+		name         string
+		data         []byte
+		opts         []Option
+		wantWarnings int
+		wantErr      bool
+	}{
+		{"clean", audio, nil, 0, false},
+		{"junk after the tag", junk, nil, 1, false},
+		{"junk and truncated", junk[:len(junk)-10], []Option{WithFullScan()}, 2, false},
+		{"failing", junk, []Option{WithMaxDuration(time.Second), WithStrict()}, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			opts := append(tt.opts, GetInfoOptions{Warnings: func(warning string) {
+				got = append(got, warning)
+			}})
+
+			metadata, err := GetInfo(bytes.NewReader(tt.data), int64(len(tt.data)), opts...)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(got) != tt.wantWarnings {
+				t.Errorf("Warnings got %q, want %d warnings", got, tt.wantWarnings)
+			}
+
+			if err == nil && !reflect.DeepEqual(got, metadata.Warnings()) {
+				t.Errorf("Warnings got %q, want Metadata.Warnings() %q", got, metadata.Warnings())
+			}
+		})
+	}
+}
+
+func TestGetInfo_RateLimit(t *testing.T) {
+	data := generateMP3(generateTag(t, id3.Frame{ID: "APIC", Data: make([]byte, 100000)}), testHeaderBits, testFrameLength, 100)
+
+	t.Run("within the limit", func(t *testing.T) {
+		metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)), WithRateLimit(1<<20))
+
+		if err != nil {
+			t.Fatalf("GetInfo() error = %v", err)
+		}
+
+		if want := 2606 * time.Millisecond; metadata.Duration() != want {
+			t.Errorf("GetInfo() Duration() = %v, want %v", metadata.Duration(), want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := GetInfo(bytes.NewReader(data), int64(len(data)), WithRateLimit(10), WithContext(ctx))
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("GetInfo() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestGetInfo_ReadTimeout(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	stuck := make(chan struct{})
+	t.Cleanup(func() {
+		close(stuck)
+	})
+
+	// the tag, then nothing
+	r := io.MultiReader(bytes.NewReader(data[:len(emptyTag)]), readerFunc(func(p []byte) (int, error) {
+		<-stuck
+		return 0, io.EOF
+	}))
+
+	_, err := GetInfo(r, int64(len(data)), WithReadTimeout(20*time.Millisecond))
+
+	if !errors.Is(err, ErrReadTimeout) {
+		t.Errorf("GetInfo() error = %v, want %v", err, ErrReadTimeout)
+	}
+
+	metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)), WithReadTimeout(time.Second))
+
+	if err != nil || metadata.Duration() != 2606*time.Millisecond {
+		t.Errorf("GetInfo() = %v, %v, want %v", metadata.Duration(), err, 2606*time.Millisecond)
+	}
+}
+
+func TestGetInfo_ReadTimeoutConn(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	go func() {
+		server.Write(data)
+
+		// later than the timeout of the last read of GetInfo
+		time.Sleep(100 * time.Millisecond)
+		server.Write([]byte("rest"))
+		server.Close()
+	}()
+
+	if _, err := GetInfo(client, int64(len(data)), WithReadTimeout(20*time.Millisecond)); err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	// the rest of the body, e.g. streamed elsewhere
+	b, err := ioutil.ReadAll(client)
+
+	if err != nil || !bytes.HasSuffix(b, []byte("rest")) {
+		t.Errorf("ReadAll() after GetInfo() = %d bytes, %v, want the rest of the input", len(b), err)
+	}
+}
@@ -0,0 +1,32 @@
+package mp3lentest_test
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"mp3len"
+	"mp3len/mp3lentest"
+)
+
+func ExampleNewServer() {
+	// 100 frames of MPEG-1 Layer III at 128 kbps, 44.1 kHz
+	frame := make([]byte, 417)
+	binary.BigEndian.PutUint32(frame, 0xFFFB9044)
+
+	var episode []byte
+	for i := 0; i < 100; i++ {
+		episode = append(episode, frame...)
+	}
+
+	server := mp3lentest.NewServer(map[string][]byte{"/episode.mp3": episode})
+	defer server.Close()
+
+	metadata, err := mp3len.GetInfoFromURL(server.URL + "/episode.mp3")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(metadata.Duration())
+	// Output: 2.606s
+}
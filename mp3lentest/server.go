@@ -0,0 +1,309 @@
+// Package mp3lentest serves MP3 fixtures over HTTP, for testing code that
+// calls mp3len.GetInfoFromURL without real files or network access.
+package mp3lentest
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Option configures the behaviour of a fixtures server.
+type Option interface {
+	apply(*handler)
+}
+
+type optionFunc func(*handler)
+
+func (f optionFunc) apply(h *handler) {
+	f(h)
+}
+
+// WithLatency delays every response by d, as a slow server would.
+func WithLatency(d time.Duration) Option {
+	return optionFunc(func(h *handler) {
+		h.latency = d
+	})
+}
+
+// WithTruncateAt cuts every response body after n bytes and drops the
+// connection, as a server failing mid-transfer would. Headers still declare
+// the whole body.
+func WithTruncateAt(n int64) Option {
+	return optionFunc(func(h *handler) {
+		h.truncateAt = n
+	})
+}
+
+// WithDeclaredSize declares every fixture n bytes long, as a misbehaving
+// origin would: bodies end at the real end of the fixture, dropping the
+// connection, and ranges starting past it are not satisfiable.
+func WithDeclaredSize(n int64) Option {
+	return optionFunc(func(h *handler) {
+		h.declaredSize = n
+	})
+}
+
+// WithoutRanges ignores Range headers and always serves the whole body, as
+// some servers do.
+func WithoutRanges() Option {
+	return optionFunc(func(h *handler) {
+		h.ignoreRanges = true
+	})
+}
+
+// WithOnRequest calls fn with each request before it's served. Calls are one
+// at a time, so fn may record requests, change the map of fixtures, e.g. to
+// simulate a file changing between requests, or alter the request, e.g.
+// delete its Range header.
+func WithOnRequest(fn func(r *http.Request)) Option {
+	return optionFunc(func(h *handler) {
+		h.onRequest = fn
+	})
+}
+
+// NewServer starts a server of fixtures, mapping URL paths like "/a.mp3" to
+// their content. Close it when done, e.g. with t.Cleanup(server.Close).
+//
+// It honours single byte ranges, answers 304 to a matching If-None-Match and
+// ignores Range when If-Range doesn't match. Paths not in fixtures are 404.
+func NewServer(fixtures map[string][]byte, opts ...Option) *httptest.Server {
+	return httptest.NewServer(Handler(fixtures, opts...))
+}
+
+// Handler returns the handler of NewServer, for wrapping it, e.g. with an
+// authentication check, or serving it another way.
+func Handler(fixtures map[string][]byte, opts ...Option) http.Handler {
+	h := &handler{fixtures: fixtures}
+
+	for _, opt := range opts {
+		opt.apply(h)
+	}
+
+	return h
+}
+
+type handler struct {
+	mu           sync.Mutex
+	fixtures     map[string][]byte
+	latency      time.Duration
+	truncateAt   int64
+	declaredSize int64
+	ignoreRanges bool
+	onRequest    func(r *http.Request)
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+
+	if h.onRequest != nil {
+		h.onRequest(r)
+	}
+
+	content, ok := h.fixtures[r.URL.Path]
+
+	if !ok {
+		content, ok = h.fixtures[strings.TrimPrefix(r.URL.Path, "/")]
+	}
+
+	h.mu.Unlock()
+
+	if h.latency > 0 {
+		time.Sleep(h.latency)
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	size := int64(len(content))
+	tag := etag(content)
+
+	if h.declaredSize > 0 {
+		size = h.declaredSize
+	}
+
+	w.Header().Set("ETag", tag)
+	w.Header().Set("Content-Type", "audio/mpeg")
+
+	if !h.ignoreRanges {
+		w.Header().Set("Accept-Ranges", "bytes")
+	}
+
+	if matchesETag(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	start, end, status := int64(0), size, http.StatusOK
+
+	if byteRange := r.Header.Get("Range"); byteRange != "" && !h.ignoreRanges && ifRange(r, tag) {
+		first, last, err := parseRange(byteRange, size)
+
+		if err == nil && first >= int64(len(content)) {
+			// past the real end
+			err, size = errUnsatisfiable, int64(len(content))
+		}
+
+		switch err {
+		case nil:
+			start, end, status = first, last+1, http.StatusPartialContent
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", first, last, size))
+		case errUnsatisfiable:
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			http.Error(w, "range not satisfiable", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(end-start, 10))
+	w.WriteHeader(status)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	cut := int64(-1)
+
+	if end > int64(len(content)) {
+		// declared larger than it is
+		end, cut = int64(len(content)), int64(len(content))-start
+	}
+
+	body := content[start:end]
+
+	if h.truncateAt > 0 && int64(len(body)) > h.truncateAt {
+		cut = h.truncateAt
+	}
+
+	if cut >= 0 {
+		w.Write(body[:cut])
+
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+
+		// drop the connection without logging
+		panic(http.ErrAbortHandler)
+	}
+
+	w.Write(body)
+}
+
+// etag returns a strong ETag for content.
+func etag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf(`"%x"`, sum[:8])
+}
+
+// matchesETag tells whether the If-None-Match header value matches tag.
+func matchesETag(header string, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifRange tells whether the Range header of r applies, i.e. it has no
+// If-Range or one matching tag. Dates never match, there's no Last-Modified.
+func ifRange(r *http.Request, tag string) bool {
+	header := r.Header.Get("If-Range")
+	return header == "" || header == tag
+}
+
+var (
+	errUnsatisfiable = errors.New("range not satisfiable")
+	errIgnoredRange  = errors.New("range ignored")
+)
+
+// parseRange parses a Range header of a single byte range, "bytes=first-last",
+// "bytes=first-" or "bytes=-suffix", into the positions of its first and last
+// bytes in a body of size bytes.
+//
+// It returns errIgnoredRange when the header is malformed or has several
+// ranges, which are then served as the whole body, and errUnsatisfiable when
+// the range is past the end.
+func parseRange(header string, size int64) (first, last int64, err error) {
+	spec := strings.TrimPrefix(header, "bytes=")
+
+	if spec == header || strings.Contains(spec, ",") {
+		return 0, 0, errIgnoredRange
+	}
+
+	dash := strings.IndexByte(spec, '-')
+
+	if dash < 0 {
+		return 0, 0, errIgnoredRange
+	}
+
+	from, to := strings.TrimSpace(spec[:dash]), strings.TrimSpace(spec[dash+1:])
+
+	if from == "" {
+		suffix, err := parseOffset(to)
+
+		if err != nil {
+			return 0, 0, errIgnoredRange
+		}
+
+		if suffix == 0 || size == 0 {
+			return 0, 0, errUnsatisfiable
+		}
+
+		if suffix > size {
+			suffix = size
+		}
+
+		return size - suffix, size - 1, nil
+	}
+
+	first, err = parseOffset(from)
+
+	if err != nil {
+		return 0, 0, errIgnoredRange
+	}
+
+	last = size - 1
+
+	if to != "" {
+		if last, err = parseOffset(to); err != nil || last < first {
+			return 0, 0, errIgnoredRange
+		}
+
+		if last >= size {
+			last = size - 1
+		}
+	}
+
+	if first >= size {
+		return 0, 0, errUnsatisfiable
+	}
+
+	return first, last, nil
+}
+
+// parseOffset parses a non-negative decimal number of bytes.
+func parseOffset(s string) (int64, error) {
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, errIgnoredRange
+	}
+
+	return strconv.ParseInt(s, 10, 64)
+}
@@ -0,0 +1,273 @@
+package mp3lentest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_parseRange(t *testing.T) {
+	tests := []struct {
+		header    string
+		size      int64
+		wantFirst int64
+		wantLast  int64
+		wantErr   error
+	}{
+		{"bytes=0-9", 100, 0, 9, nil},
+		{"bytes=10-", 100, 10, 99, nil},
+		{"bytes=90-200", 100, 90, 99, nil},
+		{"bytes=-10", 100, 90, 99, nil},
+		{"bytes=-200", 100, 0, 99, nil},
+		{"bytes= 5 - 6", 100, 5, 6, nil},
+		{"bytes=100-", 100, 0, 0, errUnsatisfiable},
+		{"bytes=-0", 100, 0, 0, errUnsatisfiable},
+		{"bytes=-10", 0, 0, 0, errUnsatisfiable},
+		{"bytes=9-0", 100, 0, 0, errIgnoredRange},
+		{"bytes=0-1,5-6", 100, 0, 0, errIgnoredRange},
+		{"bytes=a-b", 100, 0, 0, errIgnoredRange},
+		{"bytes=-", 100, 0, 0, errIgnoredRange},
+		{"bytes=+1-2", 100, 0, 0, errIgnoredRange},
+		{"bytes=5", 100, 0, 0, errIgnoredRange},
+		{"items=0-9", 100, 0, 0, errIgnoredRange},
+		{"bytes=99999999999999999999-", 100, 0, 0, errIgnoredRange},
+	}
+	for _, tt := range tests {
+		first, last, err := parseRange(tt.header, tt.size)
+
+		if err != tt.wantErr {
+			t.Errorf("parseRange(%q, %d) error = %v, wantErr %v", tt.header, tt.size, err, tt.wantErr)
+			continue
+		}
+
+		if first != tt.wantFirst || last != tt.wantLast {
+			t.Errorf("parseRange(%q, %d) = %d, %d, want %d, %d", tt.header, tt.size, first, last, tt.wantFirst, tt.wantLast)
+		}
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	content := []byte("0123456789")
+	tag := etag(content)
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		header           map[string]string
+		opts             []Option
+		wantStatus       int
+		wantBody         string
+		wantContentRange string
+	}{
+		{name: "whole", path: "/a.mp3", wantStatus: http.StatusOK, wantBody: "0123456789"},
+		{name: "path without slash", path: "/b.mp3", wantStatus: http.StatusOK, wantBody: "0123456789"},
+		{name: "not found", path: "/c.mp3", wantStatus: http.StatusNotFound},
+		{name: "method not allowed", method: http.MethodPost, path: "/a.mp3", wantStatus: http.StatusMethodNotAllowed, wantBody: "method not allowed\n"},
+		{name: "HEAD", method: http.MethodHead, path: "/a.mp3", wantStatus: http.StatusOK},
+		{
+			name:             "range",
+			path:             "/a.mp3",
+			header:           map[string]string{"Range": "bytes=2-4"},
+			wantStatus:       http.StatusPartialContent,
+			wantBody:         "234",
+			wantContentRange: "bytes 2-4/10",
+		},
+		{
+			name:             "suffix range",
+			path:             "/a.mp3",
+			header:           map[string]string{"Range": "bytes=-3"},
+			wantStatus:       http.StatusPartialContent,
+			wantBody:         "789",
+			wantContentRange: "bytes 7-9/10",
+		},
+		{
+			name:             "unsatisfiable range",
+			path:             "/a.mp3",
+			header:           map[string]string{"Range": "bytes=10-"},
+			wantStatus:       http.StatusRequestedRangeNotSatisfiable,
+			wantBody:         "range not satisfiable\n",
+			wantContentRange: "bytes */10",
+		},
+		{
+			name:       "several ranges",
+			path:       "/a.mp3",
+			header:     map[string]string{"Range": "bytes=0-1,3-4"},
+			wantStatus: http.StatusOK,
+			wantBody:   "0123456789",
+		},
+		{
+			name:       "ranges ignored",
+			path:       "/a.mp3",
+			header:     map[string]string{"Range": "bytes=2-4"},
+			opts:       []Option{WithoutRanges()},
+			wantStatus: http.StatusOK,
+			wantBody:   "0123456789",
+		},
+		{
+			name:       "If-None-Match",
+			path:       "/a.mp3",
+			header:     map[string]string{"If-None-Match": `"other", ` + tag},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:       "If-None-Match changed",
+			path:       "/a.mp3",
+			header:     map[string]string{"If-None-Match": `"other"`},
+			wantStatus: http.StatusOK,
+			wantBody:   "0123456789",
+		},
+		{
+			name:             "If-Range",
+			path:             "/a.mp3",
+			header:           map[string]string{"Range": "bytes=2-4", "If-Range": tag},
+			wantStatus:       http.StatusPartialContent,
+			wantBody:         "234",
+			wantContentRange: "bytes 2-4/10",
+		},
+		{
+			name:       "If-Range changed",
+			path:       "/a.mp3",
+			header:     map[string]string{"Range": "bytes=2-4", "If-Range": `"other"`},
+			wantStatus: http.StatusOK,
+			wantBody:   "0123456789",
+		},
+		{
+			name:       "on request",
+			path:       "/a.mp3",
+			header:     map[string]string{"Range": "bytes=2-4"},
+			opts:       []Option{WithOnRequest(func(r *http.Request) { r.Header.Del("Range") })},
+			wantStatus: http.StatusOK,
+			wantBody:   "0123456789",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(map[string][]byte{"/a.mp3": content, "b.mp3": content}, tt.opts...)
+			t.Cleanup(server.Close)
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req, err := http.NewRequest(method, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+
+			if tt.wantStatus != http.StatusNotFound && string(body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+
+			if got := resp.Header.Get("Content-Range"); got != tt.wantContentRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.wantContentRange)
+			}
+		})
+	}
+}
+
+func TestNewServer_TruncateAt(t *testing.T) {
+	server := NewServer(map[string][]byte{"/a.mp3": []byte("0123456789")}, WithTruncateAt(4))
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/a.mp3")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if err == nil {
+		t.Errorf("ReadAll() error = nil, want an unexpected EOF")
+	}
+
+	if string(body) != "0123" {
+		t.Errorf("body = %q, want %q", body, "0123")
+	}
+}
+
+func TestNewServer_DeclaredSize(t *testing.T) {
+	server := NewServer(map[string][]byte{"/a.mp3": []byte("0123456789")}, WithDeclaredSize(100))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		byteRange        string
+		wantStatus       int
+		wantContentRange string
+		wantBody         string
+	}{
+		{"", http.StatusOK, "", "0123456789"},
+		{"bytes=0-49", http.StatusPartialContent, "bytes 0-49/100", "0123456789"},
+		{"bytes=5-", http.StatusPartialContent, "bytes 5-99/100", "56789"},
+		{"bytes=10-", http.StatusRequestedRangeNotSatisfiable, "bytes */10", "range not satisfiable\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.byteRange, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/a.mp3", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.byteRange != "" {
+				req.Header.Set("Range", tt.byteRange)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+
+			if (err != nil) != (tt.wantStatus != http.StatusRequestedRangeNotSatisfiable) {
+				t.Errorf("ReadAll() error = %v", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus || resp.Header.Get("Content-Range") != tt.wantContentRange || string(body) != tt.wantBody {
+				t.Errorf("GET = %d %q %q, want %d %q %q", resp.StatusCode, resp.Header.Get("Content-Range"), body, tt.wantStatus, tt.wantContentRange, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestNewServer_Latency(t *testing.T) {
+	const latency = 50 * time.Millisecond
+
+	server := NewServer(map[string][]byte{"/a.mp3": []byte("0123456789")}, WithLatency(latency))
+	t.Cleanup(server.Close)
+
+	start := time.Now()
+
+	resp, err := http.Get(server.URL + "/a.mp3")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < latency {
+		t.Errorf("Get() took %v, want at least %v", elapsed, latency)
+	}
+}
@@ -0,0 +1,158 @@
+// Package openers maps URL schemes to functions opening the resources they
+// locate, so that programs measuring MP3 files can support storage such as
+// s3:// or gs:// next to the built-in file and http(s) schemes.
+//
+// A program registers its openers once, e.g. in init, then measures with:
+//
+//	rc, size, err := openers.Open(ctx, u)
+//	if err != nil {
+//		return err
+//	}
+//	defer rc.Close()
+//
+//	metadata, err := mp3len.GetInfo(rc, size)
+package openers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Opener opens the resource at u. size is the size of the resource in bytes,
+// or -1 when unknown.
+type Opener func(ctx context.Context, u *url.URL) (rc io.ReadCloser, size int64, err error)
+
+// ErrUnsupportedScheme is returned by Open for a URL whose scheme has no
+// registered opener.
+type ErrUnsupportedScheme struct {
+	Scheme    string
+	Supported []string // the registered schemes, sorted
+}
+
+func (e ErrUnsupportedScheme) Error() string {
+	return fmt.Sprintf("unsupported scheme %q, want one of: %s", e.Scheme, strings.Join(e.Supported, ", "))
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Opener{}
+)
+
+func init() {
+	Register("file", OpenFile)
+	Register("http", OpenHTTP)
+	Register("https", OpenHTTP)
+}
+
+// Register makes opener available for URLs of scheme, e.g. "s3". Like
+// sql.Register, it panics when opener is nil or scheme already has one, so
+// that which opener is used never depends on the order of registration.
+func Register(scheme string, opener Opener) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	scheme = strings.ToLower(scheme)
+
+	if opener == nil {
+		panic("openers: Register opener is nil")
+	}
+
+	if _, dup := registry[scheme]; dup {
+		panic("openers: Register called twice for scheme " + scheme)
+	}
+
+	registry[scheme] = opener
+}
+
+// Lookup returns the opener registered for scheme. Schemes are case
+// insensitive.
+func Lookup(scheme string) (Opener, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	opener, ok := registry[strings.ToLower(scheme)]
+
+	return opener, ok
+}
+
+// Schemes returns the registered schemes, sorted.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	schemes := make([]string, 0, len(registry))
+
+	for scheme := range registry {
+		schemes = append(schemes, scheme)
+	}
+
+	sort.Strings(schemes)
+
+	return schemes
+}
+
+// Open opens u with the opener of its scheme. A URL without scheme is a file
+// path. Returns ErrUnsupportedScheme when the scheme has no opener.
+func Open(ctx context.Context, u *url.URL) (io.ReadCloser, int64, error) {
+	scheme := u.Scheme
+
+	if scheme == "" {
+		scheme = "file"
+	}
+
+	opener, ok := Lookup(scheme)
+
+	if !ok {
+		return nil, 0, ErrUnsupportedScheme{Scheme: u.Scheme, Supported: Schemes()}
+	}
+
+	return opener(ctx, u)
+}
+
+// OpenFile is the opener of file URLs and paths.
+func OpenFile(_ context.Context, u *url.URL) (io.ReadCloser, int64, error) {
+	f, err := os.Open(u.Path)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := f.Stat()
+
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+
+	return f, info.Size(), nil
+}
+
+// OpenHTTP is the opener of http and https URLs, with a plain GET request.
+// mp3len.GetInfoFromURL reads them more efficiently, with ranges.
+func OpenHTTP(ctx context.Context, u *url.URL) (io.ReadCloser, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("GET %s: %s", u.Redacted(), resp.Status)
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
@@ -0,0 +1,135 @@
+package openers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var memFiles = map[string][]byte{"a.mp3": []byte("audio")}
+
+func init() {
+	Register("MEM", func(_ context.Context, u *url.URL) (io.ReadCloser, int64, error) {
+		data, ok := memFiles[u.Host]
+		if !ok {
+			return nil, 0, os.ErrNotExist
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+	})
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.mp3")
+
+	if err := ioutil.WriteFile(path, []byte("audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/a.mp3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("audio"))
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name     string
+		location string
+		wantSize int64
+		wantErr  bool
+	}{
+		{"registered", "mem://a.mp3", 5, false},
+		{"registered, upper case", "MEM://a.mp3", 5, false},
+		{"registered, missing", "mem://b.mp3", 0, true},
+		{"path", path, 5, false},
+		{"file", "file://" + filepath.ToSlash(path), 5, false},
+		{"http", server.URL + "/a.mp3", 5, false},
+		{"http, not found", server.URL + "/b.mp3", 0, true},
+		{"unsupported", "s3://bucket/a.mp3", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.location)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rc, size, err := Open(context.Background(), u)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			defer rc.Close()
+
+			data, err := ioutil.ReadAll(rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if size != tt.wantSize || string(data) != "audio" {
+				t.Errorf("Open() = %q, %d, want %q, %d", data, size, "audio", tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestOpen_Unsupported(t *testing.T) {
+	_, _, err := Open(context.Background(), &url.URL{Scheme: "s3", Host: "bucket", Path: "/a.mp3"})
+
+	var unsupported ErrUnsupportedScheme
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Open() error = %v, want ErrUnsupportedScheme", err)
+	}
+
+	want := `unsupported scheme "s3", want one of: file, http, https, mem`
+
+	if err.Error() != want {
+		t.Errorf("Open() error = %q, want %q", err, want)
+	}
+}
+
+func TestSchemes(t *testing.T) {
+	want := []string{"file", "http", "https", "mem"}
+
+	if got := Schemes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Schemes() = %v, want %v", got, want)
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	tests := []struct {
+		name   string
+		scheme string
+		opener Opener
+	}{
+		{"duplicate", "Mem", OpenFile},
+		{"nil", "nil", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register() did not panic")
+				}
+			}()
+
+			Register(tt.scheme, tt.opener)
+		})
+	}
+}
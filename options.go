@@ -0,0 +1,276 @@
+package mp3len
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"mp3len/internal/buffers"
+	"mp3len/internal/clock"
+	"mp3len/internal/id3"
+	"mp3len/internal/mp3header"
+	"mp3len/internal/readers"
+)
+
+// Option configures how GetInfo and friends read their input.
+type Option interface {
+	apply(*options)
+}
+
+type optionFunc func(*options)
+
+func (f optionFunc) apply(o *options) {
+	f(o)
+}
+
+type options struct {
+	requestDecorators   []RequestDecorator
+	logf                func(format string, v ...interface{})
+	maxDuration         time.Duration
+	maxScanBytes        int
+	crc32               bool
+	strict              bool
+	fullScan            bool
+	trimIncompleteFrame bool
+	tee                 io.Writer
+	rewind              bool
+	warnings            func(string)
+	requirements        []func(mp3header.MP3Header) error
+	bufferPool          buffers.Pool
+	onFrame             func(id string, size int) // frames of skipped ID3v2 tags
+	tagMaxFrames        int                       // bounds of the frames seen by onFrame,
+	tagMaxFrameBytes    int                       // 0 for the defaults
+	readerAt            io.ReaderAt               // the input, when it's random access
+	clock               clock.Clock               // replaced in tests
+	ctx                 context.Context
+	rateLimit           int64         // bytes per second, 0 for unlimited
+	readTimeout         time.Duration // of every read of the input, 0 for none
+	hosts               *hostLimiter  // of GetInfoFromURL, nil for no limit
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		maxDuration:  DefaultMaxDuration,
+		maxScanBytes: DefaultMaxScanBytes,
+		clock:        clock.Real,
+		ctx:          context.Background(),
+	}
+
+	for _, opt := range opts {
+		opt.apply(o)
+	}
+
+	return o
+}
+
+// WithLogger sets a printf-style function receiving diagnostic messages, such
+// as outgoing HTTP requests. Credentials are redacted before logging.
+func WithLogger(logf func(format string, v ...interface{})) Option {
+	return optionFunc(func(o *options) {
+		o.logf = logf
+	})
+}
+
+func (o *options) log(format string, v ...interface{}) {
+	if o.logf != nil {
+		o.logf(format, v...)
+	}
+}
+
+// DefaultMaxDuration is the longest duration considered plausible unless
+// changed by WithMaxDuration.
+const DefaultMaxDuration = 24 * time.Hour
+
+// WithMaxDuration sets the ceiling above which a computed duration is deemed
+// implausible.
+func WithMaxDuration(d time.Duration) Option {
+	return optionFunc(func(o *options) {
+		o.maxDuration = d
+	})
+}
+
+// WithStrict turns conditions that are otherwise reported as warnings into
+// errors.
+func WithStrict() Option {
+	return optionFunc(func(o *options) {
+		o.strict = true
+	})
+}
+
+// WithFullScan makes GetInfo read the whole input and count every audio frame,
+// giving an exact duration and sample count instead of an estimate.
+func WithFullScan() Option {
+	return optionFunc(func(o *options) {
+		o.fullScan = true
+	})
+}
+
+// WithTrimIncompleteFrame leaves a final frame cut short by the end of the
+// input out of the frame count and duration of a full scan, as players drop
+// it. By default it's counted. See Metadata.IncompleteFinalFrame.
+func WithTrimIncompleteFrame() Option {
+	return optionFunc(func(o *options) {
+		o.trimIncompleteFrame = true
+	})
+}
+
+// WithTee copies every byte read from the input to w, e.g. to stream the input
+// on after measuring it: the bytes copied to w come first, then the rest of the
+// reader. The input isn't seeked, even if it can. See Metadata.BytesRead.
+func WithTee(w io.Writer) Option {
+	return optionFunc(func(o *options) {
+		o.tee = w
+	})
+}
+
+// WithRewind seeks the input back to where it was before returning, so it can
+// be read again from the start. The input must be an io.Seeker.
+func WithRewind() Option {
+	return optionFunc(func(o *options) {
+		o.rewind = true
+	})
+}
+
+// WithRequire makes GetInfo fail with the error returned by require, checked
+// against the first frame header before computing the duration. Use it to
+// reject formats early, e.g. anything but MPEG-1 Layer III.
+func WithRequire(require func(mp3header.MP3Header) error) Option {
+	return optionFunc(func(o *options) {
+		o.requirements = append(o.requirements, require)
+	})
+}
+
+// BufferPool hands out the scratch buffers used while reading, e.g. to skip ID3
+// tags. Implementations must be safe for concurrent use.
+type BufferPool interface {
+	// Get returns a buffer of length n. Its content is undefined.
+	Get(n int) []byte
+	// Put gives back a buffer returned by Get, which must not be used anymore.
+	Put(b []byte)
+}
+
+// WithBufferPool makes GetInfo take its scratch buffers from p instead of the
+// package's own sync.Pool.
+func WithBufferPool(p BufferPool) Option {
+	return optionFunc(func(o *options) {
+		o.bufferPool = p
+	})
+}
+
+// DefaultMaxScanBytes is how far forward a frame is looked for, unless changed
+// with GetInfoOptions.
+const DefaultMaxScanBytes = 64 << 10
+
+// GetInfoOptions is an Option setting several limits at once. Zero fields
+// keep their defaults.
+type GetInfoOptions struct {
+	// MaxScanBytes bounds every search forward for a frame: past junk before
+	// the audio, past the rest of the tag for GetInfoRange, and past frames of
+	// unknown length. Beyond it, GetInfo fails with ErrNoFrame.
+	MaxScanBytes int
+
+	// CRC32 reads the whole input, after measuring it, for Metadata.CRC32,
+	// e.g. as a cheap key to find duplicate files.
+	CRC32 bool
+
+	// Warnings receives each note of Metadata.Warnings, e.g. junk skipped
+	// before the audio or a truncated last frame, as GetInfo returns, even
+	// when it fails. They don't change whether it fails.
+	Warnings func(string)
+}
+
+func (g GetInfoOptions) apply(o *options) {
+	if g.MaxScanBytes > 0 {
+		o.maxScanBytes = g.MaxScanBytes
+	}
+
+	if g.CRC32 {
+		o.crc32 = true
+	}
+
+	if g.Warnings != nil {
+		o.warnings = g.Warnings
+	}
+}
+
+// ErrTagLimits is returned in strict mode when an ID3v2 tag whose frames are
+// listed, i.e. by Probe, has more frames or frame data than allowed by
+// WithLimits. It tells which limit tripped and where.
+type ErrTagLimits = id3.ErrTagLimits
+
+// WithLimits bounds the number of frames and their total declared size in the
+// ID3v2 tags whose frames are listed, i.e. by Probe, against tags stuffed with
+// tiny frames. 0 means the default, 10,000 frames or 64 MiB, and a negative
+// value unlimited.
+//
+// Past a limit, the rest of the tag is skipped with a warning, or fails with
+// ErrTagLimits in strict mode.
+func WithLimits(maxFrames, maxFrameBytes int) Option {
+	return optionFunc(func(o *options) {
+		o.tagMaxFrames = maxFrames
+		o.tagMaxFrameBytes = maxFrameBytes
+	})
+}
+
+// WithContext makes GetInfoFromURL send its requests with ctx, and reads
+// waiting for their turn under WithRateLimit give up with the error of ctx as
+// soon as it's done.
+func WithContext(ctx context.Context) Option {
+	return optionFunc(func(o *options) {
+		o.ctx = ctx
+	})
+}
+
+// WithRateLimit caps the average rate the input is read at to bytesPerSecond,
+// allowing bursts of a second worth of bytes, e.g. to be polite to a server
+// when measuring many URLs. Parts of the input skipped by seeking don't count.
+// See WithContext to cancel waiting reads.
+func WithRateLimit(bytesPerSecond int64) Option {
+	return optionFunc(func(o *options) {
+		o.rateLimit = bytesPerSecond
+	})
+}
+
+// WithMaxConcurrentHosts limits the inputs GetInfoFromURL measures at the
+// same time from each hostname to n, e.g. to be polite to a server when
+// measuring many URLs concurrently. The limit is shared by the calls given the
+// same Option, which should then be created once:
+//
+//	limit := mp3len.WithMaxConcurrentHosts(2)
+//
+//	for _, u := range urls {
+//		go mp3len.GetInfoFromURL(u, limit)
+//	}
+//
+// A call waits for its turn before sending any request, and gives up with the
+// error of the context of WithContext as soon as it's done. A limit below 1
+// means no limit.
+func WithMaxConcurrentHosts(n int) Option {
+	var hosts *hostLimiter
+
+	if n > 0 {
+		hosts = newHostLimiter(n)
+	}
+
+	return optionFunc(func(o *options) {
+		o.hosts = hosts
+	})
+}
+
+// ErrReadTimeout is returned when a read of the input takes longer than
+// allowed by WithReadTimeout.
+var ErrReadTimeout = readers.ErrTimeout
+
+// WithReadTimeout fails with ErrReadTimeout as soon as a single read of the
+// input takes longer than d, so that a source trickling its bytes can't hold
+// a measurement within a frame, whatever the deadline of WithContext. Waiting
+// for WithRateLimit doesn't count.
+//
+// Inputs with SetReadDeadline, e.g. a net.Conn, are given a deadline before
+// each read. Other reads run in a goroutine, left blocked in the input when
+// they time out, until the input is closed.
+func WithReadTimeout(d time.Duration) Option {
+	return optionFunc(func(o *options) {
+		o.readTimeout = d
+	})
+}
@@ -0,0 +1,90 @@
+package mp3len
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"mp3len/internal/id3"
+)
+
+// ErrTagIncomplete is returned by GetPartialInfo when the available bytes end
+// before the ID3 tag and the first audio frame header.
+var ErrTagIncomplete = errors.New("ID3 tag is not fully available")
+
+// PartialInfo describes how much of a partially downloaded MP3 is playable.
+type PartialInfo struct {
+	Available time.Duration // duration of the audio in the available bytes
+	Expected  time.Duration // duration of the whole input, 0 if unknown
+	Complete  bool          // all of the declared bytes are available
+
+	// Needed is how many more bytes are needed to reach the first audio frame,
+	// when GetPartialInfo returns ErrTagIncomplete. While the tag header itself
+	// is incomplete, the tag size is unknown and Needed only covers the header.
+	Needed int64
+}
+
+// GetPartialInfo reads the first bytesAvailable bytes of r, a download in
+// progress, and estimates the duration of the audio available so far and of
+// the whole input, whose size is declaredTotal (negative or 0 when unknown).
+//
+// When the ID3 tag isn't fully available, it returns ErrTagIncomplete and sets
+// PartialInfo.Needed.
+func GetPartialInfo(r io.Reader, bytesAvailable int64, declaredTotal int64) (*PartialInfo, error) {
+	info := new(PartialInfo)
+	br := bufio.NewReader(io.LimitReader(r, bytesAvailable))
+
+	// Enough for a BOM and a whole tag header. Errors are left for getInfo.
+	start, _ := br.Peek(3 + 10)
+
+	if id3.HasLeadingTag(start, false) {
+		junk := bytes.Index(start, []byte("ID3"))
+		headerEnd := int64(junk + 10)
+		needed := headerEnd + 4 - bytesAvailable
+
+		if tagSize, _, err := id3.ParseHeader(start[junk:]); err == nil {
+			needed = int64(junk+tagSize) + 4 - bytesAvailable
+		}
+
+		if needed > 0 {
+			info.Needed = needed
+			return info, fmt.Errorf("%w: %d more bytes needed", ErrTagIncomplete, needed)
+		}
+	}
+
+	metadata, err := getInfo(br, bytesAvailable, newOptions(nil), new(Metadata))
+
+	if err != nil {
+		return info, err
+	}
+
+	info.Available = metadata.duration
+
+	if metadata.durationFromXing {
+		// that of the whole audio, whatever is available
+		info.Expected = metadata.duration
+		info.Complete = declaredTotal > 0 && bytesAvailable >= declaredTotal
+
+		if err := metadata.calculateDuration(bytesAvailable); err != nil {
+			return info, err
+		}
+
+		info.Available = metadata.duration
+
+		return info, nil
+	}
+
+	if declaredTotal > 0 {
+		if err := metadata.calculateDuration(declaredTotal); err != nil {
+			return info, err
+		}
+
+		info.Expected = metadata.duration
+		info.Complete = bytesAvailable >= declaredTotal
+	}
+
+	return info, nil
+}
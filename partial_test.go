@@ -0,0 +1,45 @@
+package mp3len
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetPartialInfo(t *testing.T) {
+	// 100 bytes of padding after the header
+	tag := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x64"), make([]byte, 100)...)
+	data := generateMP3(tag, testHeaderBits, testFrameLength, 100)
+	total := int64(len(data))
+	wantExpected := 2606 * time.Millisecond
+
+	tests := []struct {
+		name          string
+		available     int64
+		declaredTotal int64
+		want          PartialInfo
+		wantErr       error
+	}{
+		{"mid tag header", 5, total, PartialInfo{Needed: 9}, ErrTagIncomplete},
+		{"mid tag", 60, total, PartialInfo{Needed: 54}, ErrTagIncomplete},
+		{"mid frame header", 112, total, PartialInfo{Needed: 2}, ErrTagIncomplete},
+		{"first frame", 110 + testFrameLength, total, PartialInfo{Available: 26 * time.Millisecond, Expected: wantExpected}, nil},
+		{"half", 110 + 50*testFrameLength, total, PartialInfo{Available: 1303 * time.Millisecond, Expected: wantExpected}, nil},
+		{"complete", total, total, PartialInfo{Available: wantExpected, Expected: wantExpected, Complete: true}, nil},
+		{"unknown total", total, -1, PartialInfo{Available: wantExpected}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetPartialInfo(bytes.NewReader(data), tt.available, tt.declaredTotal)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetPartialInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if *got != tt.want {
+				t.Errorf("GetPartialInfo() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
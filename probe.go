@@ -0,0 +1,140 @@
+package mp3len
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"mp3len/internal/clock"
+)
+
+// ErrBudgetExceeded is returned by Probe when reading the input would take
+// more bytes or time than its Budget allows.
+var ErrBudgetExceeded = errors.New("probe budget exceeded")
+
+// Budget caps the resources spent by Probe. Zero values mean no limit.
+type Budget struct {
+	// MaxBytes is the most bytes read from the input.
+	MaxBytes int64
+	// MaxTime is the most time spent reading the input.
+	MaxTime time.Duration
+}
+
+// ProbeResult is the compact answer of Probe.
+type ProbeResult struct {
+	// IsMP3 tells whether an MP3 frame was found.
+	IsMP3              bool
+	Duration           time.Duration
+	DurationConfidence Confidence
+	// Bitrate of the first frame, in kbps.
+	Bitrate int
+	// SampleRate in Hz.
+	SampleRate int
+	// HasArtwork tells whether the ID3v2 tags have an attached picture.
+	HasArtwork bool
+	// TagSize is the size of the leading ID3v2 tags in bytes.
+	TagSize int
+}
+
+// Probe is a cheap GetInfo for feed validators, reading no more of r than
+// budget allows. When the budget runs out, or ctx is done, it returns what was
+// found so far with ErrBudgetExceeded or the error of ctx.
+//
+// Bytes are strictly capped. Time is checked before every read of r, so a read
+// blocking longer is not interrupted; r should have a timeout of its own, e.g.
+// an http.Client timeout.
+//
+// The frames of ID3v2 tags are listed to find artwork, within the bounds of
+// WithLimits.
+func Probe(ctx context.Context, r io.Reader, totalSize int64, budget Budget, opts ...Option) (ProbeResult, error) {
+	return probe(ctx, r, totalSize, budget, newOptions(opts))
+}
+
+func probe(ctx context.Context, r io.Reader, totalSize int64, budget Budget, o *options) (ProbeResult, error) {
+	var result ProbeResult
+	o.ctx = ctx
+
+	o.onFrame = func(id string, _ int) {
+		if id == "APIC" {
+			result.HasArtwork = true
+		}
+	}
+
+	br := &budgetReader{r: r, ctx: ctx, budget: budget, clock: o.clock}
+
+	if budget.MaxTime > 0 {
+		br.deadline = o.clock.Now().Add(budget.MaxTime)
+	}
+
+	metadata, err := getInfo(br, totalSize, o, new(Metadata))
+
+	if err != nil && br.err != nil {
+		// the reads after were only failing because of it
+		err = br.err
+	}
+
+	result.TagSize = metadata.tagSize
+
+	// only set once a frame header was fully parsed
+	if metadata.mp3Header.SampleFreq > 0 {
+		result.IsMP3 = true
+		result.Bitrate = metadata.mp3Header.BitRate
+		result.SampleRate = metadata.mp3Header.SampleFreq
+	}
+
+	if err != nil {
+		return result, err
+	}
+
+	result.Duration = metadata.duration
+	result.DurationConfidence = metadata.confidence
+
+	return result, nil
+}
+
+// budgetReader fails reads past the budget of Probe.
+type budgetReader struct {
+	r        io.Reader
+	ctx      context.Context
+	budget   Budget
+	clock    clock.Clock
+	deadline time.Time // zero when unlimited
+	n        int64
+	err      error // set once the budget or ctx ran out
+}
+
+func (b *budgetReader) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	if err := b.ctx.Err(); err != nil {
+		b.err = err
+		return 0, err
+	}
+
+	if !b.deadline.IsZero() && !b.clock.Now().Before(b.deadline) {
+		b.err = fmt.Errorf("%w: took more than %v", ErrBudgetExceeded, b.budget.MaxTime)
+		return 0, b.err
+	}
+
+	if b.budget.MaxBytes > 0 {
+		remaining := b.budget.MaxBytes - b.n
+
+		if remaining <= 0 {
+			b.err = fmt.Errorf("%w: read %d bytes", ErrBudgetExceeded, b.n)
+			return 0, b.err
+		}
+
+		if int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+
+	return n, err
+}
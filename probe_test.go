@@ -0,0 +1,204 @@
+package mp3len
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	"mp3len/internal/id3"
+	"mp3len/internal/testutil"
+)
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestProbe(t *testing.T) {
+	artwork := id3.Frame{ID: "APIC", Data: make([]byte, 20000)}
+	title := id3.Frame{ID: "TIT2", Data: []byte("\x00Title\x00")}
+
+	tests := []struct {
+		name           string
+		tag            []byte
+		wantHasArtwork bool
+	}{
+		{"with artwork", generateTag(t, title, artwork), true},
+		{"without artwork", generateTag(t, title), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := generateMP3(tt.tag, testHeaderBits, testFrameLength, 100)
+
+			got, err := Probe(context.Background(), bytes.NewReader(data), int64(len(data)), Budget{MaxBytes: 64 << 10})
+
+			if err != nil {
+				t.Fatalf("Probe() error = %v", err)
+			}
+
+			want := ProbeResult{
+				IsMP3:              true,
+				Duration:           2606 * time.Millisecond,
+				DurationConfidence: ConfidenceEstimated,
+				Bitrate:            128,
+				SampleRate:         44100,
+				HasArtwork:         tt.wantHasArtwork,
+				TagSize:            len(tt.tag),
+			}
+
+			if got != want {
+				t.Errorf("Probe() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestProbe_NotMP3(t *testing.T) {
+	data := bytes.Repeat([]byte{0xAB}, 1000)
+
+	got, err := Probe(context.Background(), bytes.NewReader(data), int64(len(data)), Budget{})
+
+	if err == nil {
+		t.Errorf("Probe() error = nil, want error")
+	}
+
+	if got.IsMP3 {
+		t.Errorf("Probe() IsMP3 = true, want false")
+	}
+}
+
+func TestProbe_Budget(t *testing.T) {
+	artwork := id3.Frame{ID: "APIC", Data: make([]byte, 100000)}
+	data := generateMP3(generateTag(t, artwork), testHeaderBits, testFrameLength, 100)
+
+	t.Run("bytes", func(t *testing.T) {
+		const maxBytes = 50000
+		r := &countingReader{r: bytes.NewReader(data)}
+
+		got, err := Probe(context.Background(), r, int64(len(data)), Budget{MaxBytes: maxBytes})
+
+		if !errors.Is(err, ErrBudgetExceeded) {
+			t.Errorf("Probe() error = %v, want %v", err, ErrBudgetExceeded)
+		}
+
+		if r.n > maxBytes {
+			t.Errorf("Probe() read %d bytes, want at most %d", r.n, maxBytes)
+		}
+
+		if !got.HasArtwork || got.IsMP3 {
+			t.Errorf("Probe() = %+v, want HasArtwork only", got)
+		}
+	})
+
+	t.Run("time", func(t *testing.T) {
+		clock := testutil.NewFakeClock(time.Unix(0, 0))
+		o := newOptions(nil)
+		o.clock = clock
+
+		// every read takes a second
+		r := readerFunc(func(p []byte) (int, error) {
+			clock.Advance(time.Second)
+			return bytes.NewReader(data).Read(p[:1])
+		})
+
+		_, err := probe(context.Background(), r, int64(len(data)), Budget{MaxTime: 3 * time.Second}, o)
+
+		if !errors.Is(err, ErrBudgetExceeded) {
+			t.Errorf("probe() error = %v, want %v", err, ErrBudgetExceeded)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := Probe(ctx, bytes.NewReader(data), int64(len(data)), Budget{})
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Probe() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+// readerFunc is an io.Reader calling itself.
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestProbe_ArtworkNotBuffered(t *testing.T) {
+	const artworkSize = 8 << 20
+	artwork := id3.Frame{ID: "APIC", Data: make([]byte, artworkSize)}
+	data := generateMP3(generateTag(t, artwork), testHeaderBits, testFrameLength, 100)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	got, err := Probe(context.Background(), bytes.NewReader(data), int64(len(data)), Budget{})
+
+	runtime.ReadMemStats(&after)
+
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+
+	if !got.HasArtwork {
+		t.Errorf("Probe() HasArtwork = false, want true")
+	}
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > artworkSize/8 {
+		t.Errorf("Probe() allocated %d bytes, want less than %d", allocated, artworkSize/8)
+	}
+}
+
+func TestProbe_TagLimits(t *testing.T) {
+	var frames []id3.Frame
+	for i := 0; i < 20000; i++ {
+		frames = append(frames, id3.Frame{ID: "PRIV", Data: []byte{0}})
+	}
+	frames = append(frames, id3.Frame{ID: "APIC", Data: make([]byte, 100)})
+
+	tag := generateTag(t, frames...)
+	data := generateMP3(tag, testHeaderBits, testFrameLength, 100)
+
+	tests := []struct {
+		name           string
+		opts           []Option
+		wantHasArtwork bool
+		wantErr        bool
+	}{
+		{"default limits", nil, false, false},
+		{"unlimited", []Option{WithLimits(-1, 0)}, true, false},
+		{"strict", []Option{WithStrict()}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Probe(context.Background(), bytes.NewReader(data), int64(len(data)), Budget{}, tt.opts...)
+
+			var limits ErrTagLimits
+			if errors.As(err, &limits) != tt.wantErr {
+				t.Fatalf("Probe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got.HasArtwork != tt.wantHasArtwork {
+				t.Errorf("Probe() HasArtwork = %v, want %v", got.HasArtwork, tt.wantHasArtwork)
+			}
+
+			if !tt.wantErr && got.TagSize != len(tag) {
+				t.Errorf("Probe() TagSize = %v, want %v", got.TagSize, len(tag))
+			}
+		})
+	}
+}
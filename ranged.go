@@ -0,0 +1,241 @@
+package mp3len
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"mp3len/internal/buffers"
+)
+
+// ErrResourceChanged is returned when a remote file changes size while it's
+// being read in several requests.
+var ErrResourceChanged = errors.New("remote resource changed during measurement")
+
+// ErrSizeMismatch is returned in strict mode when a remote file turns out to
+// be shorter than the server declared.
+var ErrSizeMismatch = errors.New("remote resource is shorter than declared")
+
+// headRangeSize is the size of the first range requested, enough for the tag
+// and the first frame of most files. Should more be needed, the rest of the
+// file is requested at once.
+const headRangeSize = 64 << 10
+
+// rangeReader reads a remote file from its start, first asking for a range of
+// headRangeSize bytes and then for the rest, when needed. Servers ignoring
+// Range are read in one response.
+type rangeReader struct {
+	o      *options
+	u      *url.URL
+	body   io.ReadCloser
+	offset int64 // bytes read from the start of the file
+	total  int64 // size of the file, -1 if unknown
+	more   bool  // the rest of the file is still to be requested
+	whole  bool  // the body holds the whole file, Range was ignored
+	short  bool  // the file ended at offset, before total
+	gzip   bool  // the file is gzip-compressed, see Content-Encoding
+}
+
+func openRange(o *options, u *url.URL) (*rangeReader, error) {
+	rr := &rangeReader{o: o, u: u, total: -1}
+
+	resp, err := rr.get(fmt.Sprintf("bytes=0-%d", headRangeSize-1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	rr.body = resp.Body
+	rr.gzip = isGzip(resp)
+
+	if resp.StatusCode != http.StatusPartialContent {
+		// Range ignored, the body holds the whole file
+		rr.total = resp.ContentLength
+		rr.whole = true
+		return rr, nil
+	}
+
+	start, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+
+	if err != nil || start != 0 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: unexpected Content-Range %q", u.Redacted(), resp.Header.Get("Content-Range"))
+	}
+
+	rr.total = total
+	rr.more = true
+
+	return rr, nil
+}
+
+func (rr *rangeReader) Read(p []byte) (int, error) {
+	n, err := rr.body.Read(p)
+	rr.offset += int64(n)
+
+	if err != io.EOF && err != io.ErrUnexpectedEOF || rr.total >= 0 && rr.offset >= rr.total {
+		return n, err
+	}
+
+	// the body ended before the file, as a range does
+	switch {
+	case rr.more:
+		if err = rr.requestRest(); err != nil {
+			return n, err
+		}
+
+		if n == 0 {
+			return rr.Read(p)
+		}
+
+		return n, nil
+	case rr.whole && rr.total >= 0:
+		// the server declared more than it had, or the connection dropped:
+		// taken as the former, the end is where the data is
+		rr.short = true
+		return n, io.EOF
+	}
+
+	return n, err
+}
+
+// checkHead reads the rest of the first range, when it's all that was read, to
+// find out whether the file is shorter than declared. A range ending early is
+// confirmed by asking for what follows, which is unsatisfiable then.
+func (rr *rangeReader) checkHead() error {
+	if !rr.more || rr.total < 0 {
+		return nil
+	}
+
+	n, err := io.Copy(ioutil.Discard, rr.body)
+	rr.offset += n
+
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	if rr.offset >= headRangeSize || rr.offset >= rr.total {
+		return nil
+	}
+
+	if err = rr.requestRest(); err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// requestRest replaces the body with one holding the file from rr.offset on.
+func (rr *rangeReader) requestRest() error {
+	rr.more = false
+	rr.body.Close()
+
+	resp, err := rr.get(fmt.Sprintf("bytes=%d-", rr.offset))
+
+	if err != nil {
+		var status *statusError
+		if errors.As(err, &status) && status.code == http.StatusRequestedRangeNotSatisfiable {
+			// the head range was the whole file, even if declared larger
+			rr.short = rr.total >= 0
+			rr.body = http.NoBody
+			return io.EOF
+		}
+
+		rr.body = http.NoBody
+		return err
+	}
+
+	rr.body = resp.Body
+
+	if resp.StatusCode != http.StatusPartialContent {
+		// Range ignored this time, skip what was already read
+		if rr.total >= 0 && resp.ContentLength >= 0 && resp.ContentLength != rr.total {
+			return fmt.Errorf("%w: size changed from %d to %d", ErrResourceChanged, rr.total, resp.ContentLength)
+		}
+
+		rr.whole = true
+		_, err = buffers.Discard(rr.o.bufferPool, resp.Body, rr.offset)
+		return err
+	}
+
+	start, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+
+	if err != nil || start != rr.offset {
+		return fmt.Errorf("GET %s: unexpected Content-Range %q", rr.u.Redacted(), resp.Header.Get("Content-Range"))
+	}
+
+	if total != rr.total {
+		return fmt.Errorf("%w: size changed from %d to %d", ErrResourceChanged, rr.total, total)
+	}
+
+	return nil
+}
+
+func (rr *rangeReader) Close() error {
+	return rr.body.Close()
+}
+
+// statusError is returned by get for responses other than 2xx.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+func (rr *rangeReader) get(byteRange string) (*http.Response, error) {
+	req, err := rr.o.newRequest("GET", rr.u)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Range", byteRange)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, &statusError{
+			code: resp.StatusCode,
+			msg:  fmt.Sprintf("GET %s: unexpected status %q", rr.u.Redacted(), resp.Status),
+		}
+	}
+
+	return resp, nil
+}
+
+// parseContentRange parses "bytes start-end/total". total is -1 when the
+// header says "*".
+func parseContentRange(header string) (start int64, total int64, err error) {
+	spec := strings.TrimPrefix(header, "bytes ")
+	slash := strings.IndexByte(spec, '/')
+	dash := strings.IndexByte(spec, '-')
+
+	if spec == header || slash < 0 || dash < 0 || dash > slash {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+
+	if start, err = strconv.ParseInt(spec[:dash], 10, 64); err != nil {
+		return 0, 0, err
+	}
+
+	if spec[slash+1:] == "*" {
+		return start, -1, nil
+	}
+
+	if total, err = strconv.ParseInt(spec[slash+1:], 10, 64); err != nil {
+		return 0, 0, err
+	}
+
+	return start, total, nil
+}
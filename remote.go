@@ -0,0 +1,127 @@
+package mp3len
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// RequestDecorator modifies an outgoing HTTP request before it is sent, e.g. to
+// add authentication headers. It is applied to every request made for a URL.
+type RequestDecorator func(*http.Request)
+
+// WithRequestDecorator adds d to the decorators applied to HTTP requests.
+func WithRequestDecorator(d RequestDecorator) Option {
+	return optionFunc(func(o *options) {
+		o.requestDecorators = append(o.requestDecorators, d)
+	})
+}
+
+// sensitiveHeaders are never written to logs verbatim.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// GetInfoFromURL fetches an HTTP(S) URL and returns metadata of the MP3. It
+// asks for the first bytes only, and for the rest of the file if needed, using
+// the size from Content-Range. Servers ignoring Range are read in a single
+// request, using Content-Length.
+//
+// A file served gzip-compressed as a whole, per Content-Encoding, is measured
+// from its uncompressed content, whose size is read from the gzip trailer
+// when the server honours ranges. Otherwise every frame is counted.
+func GetInfoFromURL(location string, opts ...Option) (*Metadata, error) {
+	o := newOptions(opts)
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.hosts != nil {
+		release, err := o.hosts.acquire(o.ctx, u.Hostname())
+		if err != nil {
+			return nil, err
+		}
+
+		defer release()
+	}
+
+	rr, err := openRange(o, u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rr.Close()
+
+	var in io.Reader = rr
+	total := rr.total
+
+	if rr.gzip {
+		if in, total, err = rr.gunzip(); err != nil {
+			return nil, err
+		}
+	}
+
+	metadata, err := getInfo(in, total, o, new(Metadata))
+
+	if err != nil {
+		return metadata, err
+	}
+
+	if !o.fullScan {
+		if err = rr.checkHead(); err != nil {
+			return metadata, err
+		}
+	}
+
+	// sizes of a compressed file aren't those of the audio
+	if rr.short && !rr.gzip {
+		err = metadata.checkSizeMismatch(rr.total, rr.offset, o)
+	}
+
+	return metadata, err
+}
+
+// newRequest builds a request with all decorators applied. Every request sent
+// on behalf of the caller must be created here.
+func (o *options) newRequest(method string, u *url.URL) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(o.ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, decorate := range o.requestDecorators {
+		decorate(req)
+	}
+
+	o.log("%s %s %s", method, u.Redacted(), redactHeader(req.Header))
+
+	return req, nil
+}
+
+// redactHeader formats h for logging with credentials masked out.
+func redactHeader(h http.Header) string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		value := strings.Join(h[k], ", ")
+
+		for _, s := range sensitiveHeaders {
+			if http.CanonicalHeaderKey(k) == s {
+				value = "[REDACTED]"
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("%s: %s; ", k, value))
+	}
+
+	return strings.TrimSuffix(sb.String(), " ")
+}
@@ -0,0 +1,317 @@
+package mp3len
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"mp3len/mp3lentest"
+)
+
+func TestGetInfoFromURL_RequestDecorator(t *testing.T) {
+	const token = "s3cr3t-t0k3n"
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+
+	requests := 0
+	fixtures := mp3lentest.Handler(map[string][]byte{"/test.mp3": data})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fixtures.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	var logged strings.Builder
+	logf := func(format string, v ...interface{}) {
+		fmt.Fprintf(&logged, format+"\n", v...)
+	}
+
+	decorate := func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	metadata, err := GetInfoFromURL(server.URL+"/test.mp3", WithRequestDecorator(decorate), WithLogger(logf))
+
+	if err != nil {
+		t.Fatalf("GetInfoFromURL() error = %v", err)
+	}
+
+	if metadata.duration == 0 {
+		t.Errorf("GetInfoFromURL() duration = 0")
+	}
+
+	if requests == 0 {
+		t.Errorf("GetInfoFromURL() made no requests")
+	}
+
+	if logged.Len() == 0 {
+		t.Errorf("GetInfoFromURL() logged nothing")
+	}
+
+	if strings.Contains(logged.String(), token) {
+		t.Errorf("GetInfoFromURL() logged the credentials: %q", logged.String())
+	}
+}
+
+func TestGetInfoFromURL_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := GetInfoFromURL(strings.Replace(server.URL, "http://", "http://user:hunter2@", 1))
+
+	if err == nil {
+		t.Fatal("GetInfoFromURL() error = nil, want error")
+	}
+
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("GetInfoFromURL() error leaks the password: %v", err)
+	}
+}
+
+func Test_redactHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Basic dXNlcjpwYXNz")
+	h.Set("Cookie", "session=abc")
+	h.Set("Accept", "audio/mpeg")
+
+	want := "Accept: audio/mpeg; Authorization: [REDACTED]; Cookie: [REDACTED];"
+
+	if got := redactHeader(h); got != want {
+		t.Errorf("redactHeader() = %q, want %q", got, want)
+	}
+}
+
+func TestGetInfoFromURL_Ranges(t *testing.T) {
+	// a tag larger than the first range
+	tag := append([]byte("ID3\x03\x00\x00\x00\x08\x00\x00"), make([]byte, 0x20000)...)
+	data := generateMP3(tag, testHeaderBits, testFrameLength, 100)
+	changed := append(append([]byte{}, data...), 0)
+
+	want, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// onRequest sees the i-th request before it's served, with the fixtures.
+	type onRequest func(r *http.Request, i int, fixtures map[string][]byte)
+
+	honour := func(r *http.Request, i int, fixtures map[string][]byte) {}
+	ignore := func(r *http.Request, i int, fixtures map[string][]byte) {
+		r.Header.Del("Range")
+	}
+
+	tests := []struct {
+		name         string
+		onRequest    onRequest
+		wantRanges   []string
+		wantErr      error
+		wantDuration time.Duration
+	}{
+		{
+			name:         "honours Range",
+			onRequest:    honour,
+			wantRanges:   []string{"bytes=0-65535", "bytes=65536-"},
+			wantDuration: want.Duration(),
+		},
+		{
+			name:         "ignores Range",
+			onRequest:    ignore,
+			wantRanges:   []string{"bytes=0-65535"},
+			wantDuration: want.Duration(),
+		},
+		{
+			name: "ignores Range after the first request",
+			onRequest: func(r *http.Request, i int, fixtures map[string][]byte) {
+				if i > 0 {
+					ignore(r, i, fixtures)
+				}
+			},
+			wantRanges:   []string{"bytes=0-65535", "bytes=65536-"},
+			wantDuration: want.Duration(),
+		},
+		{
+			name: "changed between requests",
+			onRequest: func(r *http.Request, i int, fixtures map[string][]byte) {
+				if i > 0 {
+					fixtures["/test.mp3"] = changed
+				}
+			},
+			wantRanges: []string{"bytes=0-65535", "bytes=65536-"},
+			wantErr:    ErrResourceChanged,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+
+			fixtures := map[string][]byte{"/test.mp3": data}
+			server := mp3lentest.NewServer(fixtures, mp3lentest.WithOnRequest(func(r *http.Request) {
+				ranges = append(ranges, r.Header.Get("Range"))
+				tt.onRequest(r, len(ranges)-1, fixtures)
+			}))
+			t.Cleanup(server.Close)
+
+			metadata, err := GetInfoFromURL(server.URL + "/test.mp3")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetInfoFromURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(ranges, tt.wantRanges) {
+				t.Errorf("GetInfoFromURL() requested %q, want %q", ranges, tt.wantRanges)
+			}
+
+			if err == nil && metadata.Duration() != tt.wantDuration {
+				t.Errorf("GetInfoFromURL() Duration() = %v, want %v", metadata.Duration(), tt.wantDuration)
+			}
+		})
+	}
+}
+
+func TestGetInfoFromURL_Truncated(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+
+	server := mp3lentest.NewServer(map[string][]byte{"/test.mp3": data}, mp3lentest.WithTruncateAt(100))
+	t.Cleanup(server.Close)
+
+	if _, err := GetInfoFromURL(server.URL+"/test.mp3", WithFullScan()); err == nil {
+		t.Errorf("GetInfoFromURL() error = nil, want error")
+	}
+}
+
+func TestGetInfoFromURL_Canceled(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+
+	server := mp3lentest.NewServer(map[string][]byte{"/test.mp3": data})
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GetInfoFromURL(server.URL+"/test.mp3", WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetInfoFromURL() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestGetInfoFromURL_SizeMismatch(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	const declared = 500 << 20
+
+	ignore := func(r *http.Request) {
+		r.Header.Del("Range")
+	}
+
+	tests := []struct {
+		name         string
+		onRequest    func(r *http.Request)
+		opts         []Option
+		wantRanges   []string
+		wantErr      error
+		wantDuration time.Duration
+	}{
+		{
+			name:         "estimated",
+			wantRanges:   []string{"bytes=0-65535", "bytes=41710-"},
+			wantDuration: 2606 * time.Millisecond,
+		},
+		{
+			name:         "full scan",
+			opts:         []Option{WithFullScan()},
+			wantRanges:   []string{"bytes=0-65535", "bytes=41710-"},
+			wantDuration: 2612244897 * time.Nanosecond,
+		},
+		{
+			name:         "full scan, ignores Range",
+			onRequest:    ignore,
+			opts:         []Option{WithFullScan()},
+			wantRanges:   []string{"bytes=0-65535"},
+			wantDuration: 2612244897 * time.Nanosecond,
+		},
+		{
+			name:       "strict",
+			opts:       []Option{WithStrict()},
+			wantRanges: []string{"bytes=0-65535", "bytes=41710-"},
+			wantErr:    ErrSizeMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+
+			server := mp3lentest.NewServer(map[string][]byte{"/test.mp3": data}, mp3lentest.WithDeclaredSize(declared), mp3lentest.WithOnRequest(func(r *http.Request) {
+				ranges = append(ranges, r.Header.Get("Range"))
+				if tt.onRequest != nil {
+					tt.onRequest(r)
+				}
+			}))
+			t.Cleanup(server.Close)
+
+			metadata, err := GetInfoFromURL(server.URL+"/test.mp3", tt.opts...)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetInfoFromURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(ranges, tt.wantRanges) {
+				t.Errorf("GetInfoFromURL() requested %q, want %q", ranges, tt.wantRanges)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if gotDeclared, gotActual, ok := metadata.SizeMismatch(); !ok || gotDeclared != declared || gotActual != int64(len(data)) {
+				t.Errorf("SizeMismatch() = %v, %v, %v, want %v, %v, true", gotDeclared, gotActual, ok, declared, len(data))
+			}
+
+			if metadata.Duration() != tt.wantDuration {
+				t.Errorf("GetInfoFromURL() Duration() = %v, want %v", metadata.Duration(), tt.wantDuration)
+			}
+
+			if metadata.Confidence() != ConfidenceSuspect {
+				t.Errorf("GetInfoFromURL() Confidence() = %v, want %v", metadata.Confidence(), ConfidenceSuspect)
+			}
+		})
+	}
+}
+
+func Test_parseContentRange(t *testing.T) {
+	tests := []struct {
+		header    string
+		wantStart int64
+		wantTotal int64
+		wantErr   bool
+	}{
+		{"bytes 0-65535/123456", 0, 123456, false},
+		{"bytes 100-199/*", 100, -1, false},
+		{"bytes */123456", 0, 0, true},
+		{"0-65535/123456", 0, 0, true},
+		{"", 0, 0, true},
+	}
+	for _, tt := range tests {
+		start, total, err := parseContentRange(tt.header)
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseContentRange(%q) error = %v, wantErr %v", tt.header, err, tt.wantErr)
+			continue
+		}
+
+		if start != tt.wantStart || total != tt.wantTotal {
+			t.Errorf("parseContentRange(%q) = %v, %v, want %v, %v", tt.header, start, total, tt.wantStart, tt.wantTotal)
+		}
+	}
+}
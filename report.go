@@ -0,0 +1,105 @@
+package mp3len
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mp3len/internal/mp3header"
+)
+
+// Report holds everything there is to show about a measured input. String,
+// MarshalJSON and the output of the mp3len command are all rendered from it,
+// so they never disagree.
+type Report struct {
+	Duration         time.Duration
+	Confidence       Confidence
+	DurationFromXing bool                // see Metadata.DurationFromXing
+	VBRHeader        string              // see Metadata.VBRHeaderType
+	VBRHeaderOffset  int64               // -1 when VBRHeader is "none"
+	Header           mp3header.MP3Header // of the first frame
+	VBR              bool
+	AverageBitRate   int // kbps, 0 if unknown, see Metadata.AverageBitrate
+	TagSize          int
+	TagVersion       string // e.g. "ID3v2.3", "" without ID3v2 tag
+	TagLocation      TagLocation
+	GapAfterTag      int64
+	FrameCount       int64 // 0 if unknown, see Metadata.TotalSamples
+	TotalSamples     int64
+	OrphanBytes      int64
+	Tags             *TagReport // nil when TagSource is TagSourceNone
+	Warnings         []string
+}
+
+// TagReport is the tag summary of a Report.
+type TagReport struct {
+	Source  TagSource
+	Title   string
+	Artist  string
+	Album   string
+	Year    string
+	Comment string
+	Track   int // 0 if unknown
+	Genre   string
+	Values  map[string]string // as returned by Metadata.Tags
+}
+
+// Report returns what's known about the input, for display.
+func (metadata *Metadata) Report() *Report {
+	report := &Report{
+		Duration:         metadata.duration,
+		Confidence:       metadata.confidence,
+		DurationFromXing: metadata.durationFromXing,
+		VBRHeader:        metadata.VBRHeaderType(),
+		VBRHeaderOffset:  metadata.VBRHeaderOffset(),
+		Header:           metadata.mp3Header,
+		VBR:              metadata.vbr,
+		AverageBitRate:   metadata.averageBitRate,
+		TagSize:          metadata.tagSize,
+		TagVersion:       metadata.tagVersionName(),
+		TagLocation:      metadata.tagLocation,
+		GapAfterTag:      metadata.gapAfterTag,
+		FrameCount:       metadata.frameCount,
+		TotalSamples:     metadata.TotalSamples(),
+		OrphanBytes:      metadata.incompleteFrameBytes,
+		Warnings:         metadata.warnings,
+	}
+
+	if source := metadata.TagSource(); source != TagSourceNone {
+		report.Tags = &TagReport{
+			Source:  source,
+			Title:   metadata.Title(),
+			Artist:  metadata.Artist(),
+			Album:   metadata.Album(),
+			Year:    metadata.Year(),
+			Comment: metadata.Comment(),
+			Track:   metadata.Track(),
+			Genre:   metadata.Genre(),
+			Values:  metadata.Tags(),
+		}
+	}
+
+	return report
+}
+
+// String renders the report as the lines of the -verbose output of mp3len.
+func (report *Report) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintln("Duration:", report.Duration.String()))
+
+	sb.WriteString("Audio: ")
+	sb.WriteString(report.Header.String())
+	sb.WriteByte('\n')
+
+	if report.VBR && report.AverageBitRate > 0 {
+		sb.WriteString(fmt.Sprintf("Bit rate: VBR ~%d kbps (first frame %d)\n", report.AverageBitRate, report.Header.BitRate))
+	}
+
+	sb.WriteString(fmt.Sprintf("ID3 Tag total size: %s\n", formatSize(int64(report.TagSize))))
+
+	if report.GapAfterTag > 0 {
+		sb.WriteString(fmt.Sprintf("Gap after tag: %s\n", formatSize(report.GapAfterTag)))
+	}
+
+	return sb.String()
+}
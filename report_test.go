@@ -0,0 +1,204 @@
+package mp3len
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// corpusMetadata measures a file of testdata/corpus.
+func corpusMetadata(t *testing.T, name string, opts ...Option) *Metadata {
+	f, err := os.Open(filepath.Join("testdata", "corpus", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := GetInfoAt(f, info.Size(), opts...)
+	if err != nil {
+		t.Fatalf("GetInfoAt() error = %v", err)
+	}
+
+	return metadata
+}
+
+func TestMetadata_Report(t *testing.T) {
+	tests := []struct {
+		name string
+		want Report
+	}{
+		{
+			name: "tagged.mp3",
+			want: Report{
+				Duration:        521 * time.Millisecond,
+				Confidence:      ConfidenceEstimated,
+				VBRHeader:       "none",
+				VBRHeaderOffset: -1,
+				TagSize:         28,
+				TagVersion:      "ID3v2.3",
+				TagLocation:     TagPrepended,
+			},
+		},
+		{
+			name: "id3v1.mp3",
+			want: Report{
+				Duration:        521 * time.Millisecond,
+				Confidence:      ConfidenceEstimated,
+				VBRHeader:       "none",
+				VBRHeaderOffset: -1,
+				TagLocation:     TagNone,
+				Tags: &TagReport{
+					Source: TagSourceID3v1,
+					Title:  "Corpus",
+					Genre:  "Blues",
+					Values: map[string]string{"title": "Corpus", "genre": "Blues"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := corpusMetadata(t, tt.name)
+			tt.want.Header = metadata.Header()
+
+			got := metadata.Report()
+
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Report() = %+v, want %+v", *got, tt.want)
+			}
+
+			if got.Tags != nil && !reflect.DeepEqual(*got.Tags, *tt.want.Tags) {
+				t.Errorf("Report() Tags = %+v, want %+v", *got.Tags, *tt.want.Tags)
+			}
+		})
+	}
+}
+
+func TestMetadata_Report_FullScan(t *testing.T) {
+	data := generateVBR(50)
+
+	metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)), WithFullScan())
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	got := metadata.Report()
+
+	if got.FrameCount != 100 || got.TotalSamples != metadata.TotalSamples() || got.AverageBitRate != metadata.AverageBitrate() || !got.VBR {
+		t.Errorf("Report() = %+v, want the frames, samples and bit rate of the full scan", *got)
+	}
+
+	reportJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(reportJSON, metadataJSON) {
+		t.Errorf("json.Marshal(Report()) = %s, want %s", reportJSON, metadataJSON)
+	}
+}
+
+func TestMetadata_String(t *testing.T) {
+	vbr := generateVBR(50)
+	gap := generateMP3(append(append([]byte{}, emptyTag...), make([]byte, 5000)...), testHeaderBits, testFrameLength, 100)
+
+	tests := []struct {
+		name        string
+		metadata    func(t *testing.T) *Metadata
+		want        string
+		wantVerbose string
+	}{
+		{
+			name:        "tagged",
+			metadata:    func(t *testing.T) *Metadata { return corpusMetadata(t, "tagged.mp3") },
+			want:        "521ms",
+			wantVerbose: "Duration: 521ms\nAudio: MPEG-1 Layer III, 128 kbps, 44100Hz\nID3 Tag total size: 28 B\n",
+		},
+		{
+			name:        "untagged",
+			metadata:    func(t *testing.T) *Metadata { return corpusMetadata(t, "untagged.mp3") },
+			want:        "521ms",
+			wantVerbose: "Duration: 521ms\nAudio: MPEG-1 Layer III, 128 kbps, 44100Hz\nID3 Tag total size: 0 B\n",
+		},
+		{
+			name: "VBR",
+			metadata: func(t *testing.T) *Metadata {
+				metadata, err := GetInfo(bytes.NewReader(vbr), int64(len(vbr)), WithFullScan())
+				if err != nil {
+					t.Fatal(err)
+				}
+				return metadata
+			},
+			want:        "2.612244897s",
+			wantVerbose: "Duration: 2.612244897s\nAudio: MPEG-1 Layer III, 128 kbps, 44100Hz\nBit rate: VBR ~96 kbps (first frame 128)\nID3 Tag total size: 0 B\n",
+		},
+		{
+			name: "gap after tag",
+			metadata: func(t *testing.T) *Metadata {
+				metadata, err := GetInfo(bytes.NewReader(gap), int64(len(gap)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return metadata
+			},
+			want:        "2.606s",
+			wantVerbose: "Duration: 2.606s\nAudio: MPEG-1 Layer III, 128 kbps, 44100Hz\nID3 Tag total size: 10 B\nGap after tag: 4.9 KiB\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := tt.metadata(t)
+
+			if got := metadata.String(false); got != tt.want {
+				t.Errorf("String(false) = %q, want %q", got, tt.want)
+			}
+
+			if got := metadata.String(true); got != tt.wantVerbose {
+				t.Errorf("String(true) = %q, want %q", got, tt.wantVerbose)
+			}
+		})
+	}
+}
+
+func TestMetadata_Accessors(t *testing.T) {
+	tests := []struct {
+		name         string
+		wantDuration time.Duration
+		wantTagSize  int
+	}{
+		{"tagged.mp3", 521 * time.Millisecond, 28},
+		{"untagged.mp3", 521 * time.Millisecond, 0},
+		{"vbr.mp3", 520 * time.Millisecond, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := corpusMetadata(t, tt.name)
+
+			if got := metadata.Duration(); got != tt.wantDuration {
+				t.Errorf("Duration() = %v, want %v", got, tt.wantDuration)
+			}
+
+			if got := metadata.TagSize(); got != tt.wantTagSize {
+				t.Errorf("TagSize() = %v, want %v", got, tt.wantTagSize)
+			}
+
+			if got := metadata.Header(); got.BitRate != 128 || got.SampleFreq != 44100 {
+				t.Errorf("Header() = %v, want 128 kbps at 44100Hz", got)
+			}
+		})
+	}
+}
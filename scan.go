@@ -0,0 +1,190 @@
+package mp3len
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"mp3len/internal/mp3header"
+)
+
+var errFreeFormat = errors.New("cannot walk frames of a free format stream")
+
+// ErrNoFrame is returned when no MP3 frame is found within the scan limit, see
+// GetInfoOptions.
+var ErrNoFrame = errors.New("no valid MP3 frame found")
+
+// findFrameSync reads up to and including the first frame header within
+// maxGap bytes of br. gap is the number of bytes skipped before it.
+//
+// When the input ends before a header is found, the error is the one for the
+// bytes at the start. Past maxGap, it's ErrNoFrame. At a stream of ADTS
+// frames, it's ErrNotMP3Format.
+func findFrameSync(br *bufio.Reader, maxGap int) (header mp3header.MP3Header, gap int64, err error) {
+	var firstErr error
+
+	for gap <= int64(maxGap) {
+		data, err := br.Peek(4)
+
+		if err != nil {
+			if firstErr != nil {
+				return header, gap, firstErr
+			}
+			return header, gap, err
+		}
+
+		header, err = mp3header.Parse(binary.BigEndian.Uint32(data))
+
+		if err == nil {
+			_, err = br.Discard(4)
+			return header, gap, err
+		}
+
+		// reserved layer bits, but the frames go on
+		if isADTSStream(br) {
+			return header, gap, ErrNotMP3Format{DetectedFormat: "aac"}
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+
+		if _, err = br.Discard(1); err != nil {
+			return header, gap, err
+		}
+
+		gap++
+	}
+
+	return header, gap, fmt.Errorf("%w within %d bytes", ErrNoFrame, maxGap)
+}
+
+// findUsableFrame is like findFrameSync, but when the length of the first
+// frame can't be known, e.g. it has a free format or bad bit rate, it looks
+// further for a frame of known length that is followed by another frame, and
+// returns it instead. skipped is the number of bytes from the start of the
+// first header to the returned one.
+//
+// When no such frame is found, the first header is returned as is.
+func findUsableFrame(br *bufio.Reader, maxGap int) (header mp3header.MP3Header, gap int64, skipped int64, err error) {
+	header, gap, err = findFrameSync(br, maxGap)
+
+	if err != nil || header.FrameLength() > 0 {
+		return header, gap, 0, err
+	}
+
+	for {
+		remaining := int64(maxGap) - gap - skipped - 4
+
+		if remaining < 0 {
+			return header, gap, 0, nil
+		}
+
+		candidate, candidateGap, err := findFrameSync(br, int(remaining))
+
+		if err != nil {
+			return header, gap, 0, nil
+		}
+
+		skipped += 4 + candidateGap
+
+		if candidate.FrameLength() > 0 && isFollowedByFrame(br, candidate) {
+			return candidate, gap, skipped, nil
+		}
+	}
+}
+
+// isFollowedByFrame tells whether the frame whose header was just read from br
+// is followed by another frame header, or by the end of the input.
+func isFollowedByFrame(br *bufio.Reader, header mp3header.MP3Header) bool {
+	length := header.FrameLength()
+	data, err := br.Peek(length)
+
+	if err == io.EOF {
+		return true
+	}
+
+	if err != nil {
+		return false
+	}
+
+	_, err = mp3header.Parse(binary.BigEndian.Uint32(data[length-4:]))
+
+	return err == nil
+}
+
+// continuesStream tells whether next, a header found after the frames of the
+// stream started by first, is another frame of it: of known length, and of the
+// same version, layer and sample rate. Otherwise, it's taken for junk that
+// happens to parse, e.g. in an APE tag.
+func continuesStream(first, next mp3header.MP3Header) bool {
+	return next.FrameLength() >= 4 &&
+		next.AudioVersion == first.AudioVersion &&
+		next.Layer == first.Layer &&
+		next.SampleFreq == first.SampleFreq
+}
+
+// walkFrames counts audio frames by hopping from one frame header to the next,
+// starting right after the header of first has been read. It stops at EOF or
+// at the first bytes that aren't a frame header of the stream, e.g. an ID3v1
+// trailer, see continuesStream.
+//
+// The returned count includes first. vbr reports whether any frame has a bit
+// rate different from first, or from the second frame with headerFrame, when
+// first holds a Xing, Info or VBRI header rather than audio. incomplete is the number of bytes of a last frame
+// cut short by EOF, header included, which is also counted. size is the number
+// of bytes of all the frames counted.
+func walkFrames(r io.Reader, first mp3header.MP3Header, headerFrame bool) (count int64, vbr bool, incomplete, size int64, err error) {
+	count = 1
+	header := first
+
+	// the bit rate of the other frames is compared to
+	rate := first.BitRate
+
+	for {
+		length := header.FrameLength()
+
+		if length < 4 {
+			return count, vbr, 0, size, errFreeFormat
+		}
+
+		n, err := io.CopyN(ioutil.Discard, r, int64(length-4))
+
+		if err != nil {
+			if err == io.EOF {
+				return count, vbr, 4 + n, size + 4 + n, nil
+			}
+			return count, vbr, 0, size, err
+		}
+
+		size += int64(length)
+
+		var headerBits uint32
+
+		if err = binary.Read(r, binary.BigEndian, &headerBits); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return count, vbr, 0, size, nil
+			}
+			return count, vbr, 0, size, err
+		}
+
+		next, err := mp3header.Parse(headerBits)
+
+		if err != nil || !continuesStream(first, next) {
+			// not audio anymore
+			return count, vbr, 0, size, nil
+		}
+
+		header = next
+		count++
+
+		if count == 2 && headerFrame {
+			rate = header.BitRate
+		} else if header.BitRate != rate {
+			vbr = true
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package mp3len
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mp3len/internal/mp3header"
+)
+
+// ErrInvalidTOC is returned in strict mode when the TOC of the Xing header
+// isn't non-decreasing, which would map later times to earlier offsets.
+var ErrInvalidTOC = errors.New("invalid Xing TOC")
+
+// SeekTable maps times to offsets in the input, from the TOC of the Xing header
+// of VBR files, where the bit rate can't tell them.
+type SeekTable struct {
+	// TOC holds, for each percent of the duration, the fraction of the audio
+	// bytes before it, non-decreasing and in [0, 1).
+	TOC []float64
+
+	// TOCValid tells whether the TOC was non-decreasing as read. Otherwise,
+	// entries were clamped to the previous one.
+	TOCValid bool
+
+	duration   time.Duration
+	audioStart int64
+	audioBytes int64
+}
+
+// OffsetForTime returns the offset in the input of the audio at t, between two
+// entries of the TOC. It's always within the audio, even for t out of the
+// duration.
+func (s *SeekTable) OffsetForTime(t time.Duration) int64 {
+	percent := 0.0
+
+	if s.duration > 0 {
+		percent = float64(t) / float64(s.duration) * 100
+	}
+
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+
+	i := int(percent)
+	before, after := 1.0, 1.0
+
+	if i < len(s.TOC) {
+		before = s.TOC[i]
+	}
+
+	if i+1 < len(s.TOC) {
+		after = s.TOC[i+1]
+	}
+
+	offset := s.audioStart + int64((before+(after-before)*(percent-float64(i)))*float64(s.audioBytes))
+
+	if offset < s.audioStart {
+		return s.audioStart
+	}
+
+	if end := s.audioStart + s.audioBytes; offset > end {
+		return end
+	}
+
+	return offset
+}
+
+// SeekTable returns the seek table of the TOC of the Xing header, or nil when
+// the first frame has none.
+func (metadata *Metadata) SeekTable() *SeekTable {
+	return metadata.seekTable
+}
+
+// readSeekTable sets the seek table from the Xing header x, if it has a TOC,
+// once the duration is known. An invalid TOC is repaired with a warning, or
+// rejected in strict mode.
+func (metadata *Metadata) readSeekTable(x mp3header.XingHeader, totalSize int64, o *options) error {
+	toc, valid := x.NormalizedTOC()
+
+	if toc == nil {
+		return nil
+	}
+
+	if !valid {
+		msg := "the TOC of the Xing header goes backwards, offsets clamped"
+		metadata.warnings = append(metadata.warnings, msg)
+
+		if o.strict {
+			return fmt.Errorf("%w: %s", ErrInvalidTOC, msg)
+		}
+	}
+
+	audioBytes := int64(x.Bytes)
+
+	if totalSize >= 0 {
+		if remaining := metadata.audioBytes(totalSize); audioBytes <= 0 || audioBytes > remaining {
+			audioBytes = remaining
+		}
+	}
+
+	metadata.seekTable = &SeekTable{
+		TOC:        toc,
+		TOCValid:   valid,
+		duration:   metadata.duration,
+		audioStart: metadata.audioOffset,
+		audioBytes: audioBytes,
+	}
+
+	return nil
+}
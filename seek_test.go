@@ -0,0 +1,134 @@
+package mp3len
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// generateXingMP3 returns count frames, the first of which holds a Xing header
+// with the given TOC, after tag.
+func generateXingMP3(tag []byte, toc []byte, count int) []byte {
+	data := generateMP3(tag, testHeaderBits, testFrameLength, count)
+	frame := data[len(tag):]
+
+	// after the header and the side info of MPEG-1 joint stereo
+	copy(frame[4+32:], "Xing")
+	binary.BigEndian.PutUint32(frame[4+32+4:], 0x6) // bytes and TOC
+	binary.BigEndian.PutUint32(frame[4+32+8:], uint32(testFrameLength*count))
+	copy(frame[4+32+12:], toc)
+
+	return data
+}
+
+func linearTOC() []byte {
+	toc := make([]byte, 100)
+	for i := range toc {
+		toc[i] = byte(i * 256 / 100)
+	}
+	return toc
+}
+
+func TestMetadata_SeekTable(t *testing.T) {
+	backwards := linearTOC()
+	backwards[50] = 10
+
+	tests := []struct {
+		name         string
+		toc          []byte
+		opts         []Option
+		wantTOCValid bool
+		wantWarnings int
+		wantErr      error
+	}{
+		{"linear", linearTOC(), nil, true, 0, nil},
+		{"backwards", backwards, nil, false, 1, nil},
+		{"backwards, strict", backwards, []Option{WithStrict()}, false, 1, ErrInvalidTOC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := generateXingMP3(emptyTag, tt.toc, 100)
+			metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)), tt.opts...)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := len(metadata.Warnings()); got != tt.wantWarnings {
+				t.Errorf("GetInfo() warnings = %v, want %d", metadata.Warnings(), tt.wantWarnings)
+			}
+
+			if err != nil {
+				return
+			}
+
+			table := metadata.SeekTable()
+
+			if table == nil {
+				t.Fatal("SeekTable() = nil")
+			}
+
+			if table.TOCValid != tt.wantTOCValid {
+				t.Errorf("SeekTable().TOCValid = %v, want %v", table.TOCValid, tt.wantTOCValid)
+			}
+
+			start, end := int64(len(emptyTag)), int64(len(data))
+			previous := int64(0)
+
+			for ms := -100; ms <= 3000; ms += 10 {
+				offset := table.OffsetForTime(time.Duration(ms) * time.Millisecond)
+
+				if offset < start || offset > end {
+					t.Errorf("OffsetForTime(%dms) = %d, want within [%d, %d]", ms, offset, start, end)
+				}
+
+				if offset < previous {
+					t.Errorf("OffsetForTime(%dms) = %d, before %d", ms, offset, previous)
+				}
+
+				previous = offset
+			}
+		})
+	}
+}
+
+func TestMetadata_SeekTable_None(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+	metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	if table := metadata.SeekTable(); table != nil {
+		t.Errorf("SeekTable() = %+v, want nil", table)
+	}
+}
+
+func TestSeekTable_OffsetForTime(t *testing.T) {
+	table := SeekTable{
+		TOC:        []float64{0, 0.5},
+		duration:   100 * time.Second,
+		audioStart: 10,
+		audioBytes: 1000,
+	}
+
+	tests := []struct {
+		t    time.Duration
+		want int64
+	}{
+		{0, 10},
+		{500 * time.Millisecond, 260},
+		{1 * time.Second, 510},
+		{1500 * time.Millisecond, 760},
+		{time.Hour, 1010},
+		{-time.Second, 10},
+	}
+	for _, tt := range tests {
+		if got := table.OffsetForTime(tt.t); got != tt.want {
+			t.Errorf("OffsetForTime(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
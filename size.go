@@ -0,0 +1,23 @@
+package mp3len
+
+import "fmt"
+
+// formatSize formats a byte count for humans, e.g. "322.4 KiB".
+func formatSize(n int64) string {
+	const unit = 1024
+
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n) / unit
+	suffixes := []string{"KiB", "MiB", "GiB", "TiB"}
+	i := 0
+
+	for (value >= unit || value <= -unit) && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+
+	return fmt.Sprintf("%.1f %s", value, suffixes[i])
+}
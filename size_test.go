@@ -0,0 +1,38 @@
+package mp3len
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_formatSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{330175, "322.4 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+		{2 << 50, "2048.0 TiB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.n); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMetadata_String_Verbose(t *testing.T) {
+	metadata := &Metadata{tagSize: 330175, gapAfterTag: 2}
+
+	got := metadata.String(true)
+
+	for _, want := range []string{"ID3 Tag total size: 322.4 KiB\n", "Gap after tag: 2 B\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String(true) = %q, want it to contain %q", got, want)
+		}
+	}
+}
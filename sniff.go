@@ -0,0 +1,186 @@
+package mp3len
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"mp3len/internal/id3"
+	"mp3len/internal/readers"
+)
+
+// ErrUnsupportedContainer is returned when the input is audio in a container
+// other than MP3, which needs converting first.
+type ErrUnsupportedContainer struct {
+	Format string // e.g. "webm/matroska"
+}
+
+func (e ErrUnsupportedContainer) Error() string {
+	return fmt.Sprintf("unsupported container %s, convert to MP3 first", e.Format)
+}
+
+// ErrNotMP3Format is returned when the input is audio of another format
+// whose frames could pass for MP3 ones, e.g. ADTS AAC, which shares the frame
+// sync. It wraps the ErrUnsupportedContainer of the stream.
+type ErrNotMP3Format struct {
+	DetectedFormat string // e.g. "aac"
+}
+
+func (e ErrNotMP3Format) Error() string {
+	return fmt.Sprintf("%s audio is not MP3, convert to MP3 first", e.DetectedFormat)
+}
+
+func (e ErrNotMP3Format) Unwrap() error {
+	if container, ok := formatContainers[e.DetectedFormat]; ok {
+		return ErrUnsupportedContainer{Format: container}
+	}
+
+	return ErrUnsupportedContainer{Format: e.DetectedFormat}
+}
+
+// formatContainers maps the formats of ErrNotMP3Format to the containers they
+// come in.
+var formatContainers = map[string]string{
+	"aac": "aac/adts",
+}
+
+var containerMagics = []struct {
+	magic  []byte
+	format string
+}{
+	{[]byte{0x1A, 0x45, 0xDF, 0xA3}, "webm/matroska"}, // EBML header
+}
+
+// sniffContainer returns ErrUnsupportedContainer if start, the first bytes of
+// the input, belong to a known non-MP3 container.
+func sniffContainer(start []byte) error {
+	for _, c := range containerMagics {
+		if bytes.HasPrefix(start, c.magic) {
+			return ErrUnsupportedContainer{Format: c.format}
+		}
+	}
+
+	return nil
+}
+
+// isADTS tells whether data starts with the header of an ADTS frame, i.e. AAC
+// audio. Its 12-bit sync overlaps the 11-bit one of MP3, but its layer bits
+// are 00, a value reserved in MPEG audio.
+func isADTS(data []byte) bool {
+	return adtsFrameLength(data) > 0
+}
+
+// adtsFrameLength returns the length of the ADTS frame data starts with, header
+// included, or 0 if it doesn't start with one.
+func adtsFrameLength(data []byte) int {
+	if len(data) < 7 || data[0] != 0xFF || data[1]&0xF6 != 0xF0 {
+		return 0
+	}
+
+	sampleFreqIndex := (data[2] >> 2) & 0x0F
+	frameLength := int(data[3]&0x03)<<11 | int(data[4])<<3 | int(data[5])>>5
+
+	// 7 bytes of header at least
+	if sampleFreqIndex > 12 || frameLength < 7 {
+		return 0
+	}
+
+	return frameLength
+}
+
+// isADTSStream tells whether br is at an ADTS frame followed by another one,
+// or by the end of the input. Unlike isADTS, it holds for a sync found past
+// garbage, where a lone header could be noise.
+func isADTSStream(br *bufio.Reader) bool {
+	data, _ := br.Peek(7)
+	length := adtsFrameLength(data)
+
+	if length == 0 {
+		return false
+	}
+
+	data, err := br.Peek(length + 7)
+
+	if err == io.EOF {
+		return len(data) == length
+	}
+
+	return err == nil && isADTS(data[length:])
+}
+
+// IsMP3 tells whether r starts with MP3 audio, after any ID3v2 tags: a frame
+// header followed by another one, or by the end of the input, within
+// DefaultMaxScanBytes. Unlike GetInfo, no duration is computed.
+//
+// It reads the tags, then a few KB at most. Inputs that end early, or that are
+// another container, aren't MP3, so the error is only for failed reads. Free
+// format streams, whose frame lengths aren't declared, aren't recognized.
+func IsMP3(r io.Reader) (bool, error) {
+	rec := &readErrRecorder{r: readers.GuardProgress(r)}
+	br := bufio.NewReader(rec)
+
+	isMP3, err := isMP3(br)
+
+	if rec.err != nil {
+		return false, rec.err
+	}
+
+	return isMP3, err
+}
+
+func isMP3(br *bufio.Reader) (bool, error) {
+	start, _ := br.Peek(6)
+
+	if sniffContainer(start) != nil {
+		return false, nil
+	}
+
+	for id3.HasLeadingTag(start, false) {
+		if _, err := id3.NewSkipReader(br).ReadThrough(); err != nil {
+			return false, nil
+		}
+
+		start, _ = br.Peek(6)
+	}
+
+	if data, _ := br.Peek(7); isADTS(data) {
+		return false, nil
+	}
+
+	var scanned int64
+
+	for scanned <= DefaultMaxScanBytes {
+		header, gap, err := findFrameSync(br, DefaultMaxScanBytes-int(scanned))
+
+		if err != nil {
+			// the end of the input or of the scan, or an error recorded
+			return false, nil
+		}
+
+		if header.FrameLength() > 0 && isFollowedByFrame(br, header) {
+			return true, nil
+		}
+
+		scanned += gap + 4
+	}
+
+	return false, nil
+}
+
+// readErrRecorder keeps the first error other than io.EOF returned by r, to
+// tell failed reads from inputs that end early.
+type readErrRecorder struct {
+	r   io.Reader
+	err error
+}
+
+func (rec *readErrRecorder) Read(p []byte) (int, error) {
+	n, err := rec.r.Read(p)
+
+	if err != nil && err != io.EOF && rec.err == nil {
+		rec.err = err
+	}
+
+	return n, err
+}
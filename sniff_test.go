@@ -0,0 +1,163 @@
+package mp3len
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"mp3len/internal/id3"
+)
+
+func TestGetInfo_UnsupportedContainer(t *testing.T) {
+	data := append([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x86, 0x81, 0x01}, make([]byte, 100)...)
+
+	_, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+
+	var container ErrUnsupportedContainer
+	if !errors.As(err, &container) {
+		t.Fatalf("GetInfo() error = %v, want ErrUnsupportedContainer", err)
+	}
+
+	if container.Format != "webm/matroska" {
+		t.Errorf("GetInfo() error Format = %q, want %q", container.Format, "webm/matroska")
+	}
+}
+
+// generateADTS builds count AAC-LC frames of frameLength bytes, 44100Hz stereo.
+func generateADTS(count int, frameLength int) []byte {
+	frame := make([]byte, frameLength)
+	copy(frame, []byte{
+		0xFF, 0xF1, // sync, MPEG-4, layer 00, no CRC
+		0x50, // AAC-LC, 44100Hz
+		0x80 | byte(frameLength>>11&0x03),
+		byte(frameLength >> 3),
+		byte(frameLength&0x07)<<5 | 0x1F,
+		0xFC,
+	})
+
+	return bytes.Repeat(frame, count)
+}
+
+func TestGetInfo_ADTS(t *testing.T) {
+	audio := generateADTS(100, 371)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"untagged", audio},
+		{"tagged", append(generateTag(t), audio...)},
+		{"after garbage", append(make([]byte, 100), audio...)},
+		{"single frame after garbage", append(make([]byte, 100), audio[:371]...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetInfo(bytes.NewReader(tt.data), int64(len(tt.data)))
+
+			var format ErrNotMP3Format
+			if !errors.As(err, &format) {
+				t.Fatalf("GetInfo() error = %v, want ErrNotMP3Format", err)
+			}
+
+			if format.DetectedFormat != "aac" {
+				t.Errorf("GetInfo() error DetectedFormat = %q, want %q", format.DetectedFormat, "aac")
+			}
+
+			var container ErrUnsupportedContainer
+			if !errors.As(err, &container) {
+				t.Fatalf("GetInfo() error = %v, want ErrUnsupportedContainer", err)
+			}
+
+			if container.Format != "aac/adts" {
+				t.Errorf("GetInfo() error Format = %q, want %q", container.Format, "aac/adts")
+			}
+		})
+	}
+}
+
+func Test_isADTS(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"ADTS", generateADTS(1, 371), true},
+		{"MPEG-2 ADTS with CRC", []byte{0xFF, 0xF8, 0x50, 0x80, 0x2E, 0x7F, 0xFC}, true},
+		{"MP3", generateMP3(nil, testHeaderBits, testFrameLength, 1), false},
+		{"reserved sample frequency", []byte{0xFF, 0xF1, 0x7C, 0x80, 0x2E, 0x7F, 0xFC}, false},
+		{"too short", []byte{0xFF, 0xF1, 0x50}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isADTS(tt.data); got != tt.want {
+				t.Errorf("isADTS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMP3(t *testing.T) {
+	mp3 := generateMP3(nil, testHeaderBits, testFrameLength, 100)
+	tag := generateTag(t, id3.Frame{ID: "TIT2", Data: []byte("\x00Title\x00")})
+	garbage := []byte("\xFF\xFB\x90\x44 not a frame long enough")
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"untagged", mp3, true},
+		{"tagged", append(append([]byte{}, tag...), mp3...), true},
+		{"stacked tags", append(append(append([]byte{}, tag...), tag...), mp3...), true},
+		{"single frame", mp3[:testFrameLength], true},
+		{"false sync before the audio", append(append([]byte{}, garbage...), mp3...), true},
+		{"frame cut short by the end", mp3[:100], true},
+		{"truncated tag", tag[:len(tag)-1], false},
+		{"tag only", tag, false},
+		{"text", []byte("hello, world"), false},
+		{"empty", nil, false},
+		{"ADTS", generateADTS(10, 200), false},
+		{"ADTS after garbage", append(make([]byte, 100), generateADTS(10, 200)...), false},
+		{"webm", append([]byte{0x1A, 0x45, 0xDF, 0xA3}, mp3...), false},
+		{"reserved sample rate", generateMP3(nil, 0xFFFB9C44, testFrameLength, 100), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsMP3(bytes.NewReader(tt.data))
+
+			if err != nil {
+				t.Fatalf("IsMP3() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("IsMP3() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMP3_ReadsLittle(t *testing.T) {
+	tag := generateTag(t, id3.Frame{ID: "APIC", Data: make([]byte, 100000)})
+	data := generateMP3(tag, testHeaderBits, testFrameLength, 10000)
+	r := &countingReader{r: bytes.NewReader(data)}
+
+	if got, err := IsMP3(r); !got || err != nil {
+		t.Fatalf("IsMP3() = %v, %v, want true", got, err)
+	}
+
+	if max := int64(len(tag) + 8192); r.n > max {
+		t.Errorf("IsMP3() read %d bytes, want at most %d", r.n, max)
+	}
+}
+
+func TestIsMP3_ReadError(t *testing.T) {
+	mp3 := generateMP3(nil, testHeaderBits, testFrameLength, 100)
+	failure := errors.New("connection reset")
+	r := io.MultiReader(bytes.NewReader(mp3[:10]), iotest.ErrReader(failure))
+
+	if got, err := IsMP3(r); got || !errors.Is(err, failure) {
+		t.Errorf("IsMP3() = %v, %v, want false, %v", got, err, failure)
+	}
+}
@@ -0,0 +1,146 @@
+package mp3len
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Stats summarizes the formats of many inputs. Use Add for every result; it
+// only keeps counters, never the Metadata itself.
+type Stats struct {
+	Files       int            `json:"files"`
+	Failed      int            `json:"failed"`
+	Formats     map[string]int `json:"formats"`     // e.g. "MPEG-1 Layer III"
+	BitRates    map[int]int    `json:"bitRates"`    // kbps, first frame
+	SampleRates map[int]int    `json:"sampleRates"` // Hz
+	CBR         int            `json:"cbr"`
+	VBR         int            `json:"vbr"` // only detected with WithFullScan or GetInfoAt
+	Tagged      int            `json:"tagged"`
+	Untagged    int            `json:"untagged"`
+	TagVersions map[string]int `json:"tagVersions"` // per tag found, e.g. "ID3v2.3" or "ID3v1"
+}
+
+// NewStats returns empty Stats.
+func NewStats() *Stats {
+	return &Stats{
+		Formats:     make(map[string]int),
+		BitRates:    make(map[int]int),
+		SampleRates: make(map[int]int),
+		TagVersions: make(map[string]int),
+	}
+}
+
+// Add counts one measured input. It is Tagged with any tag found, whether
+// leading, appended or ID3v1.
+func (s *Stats) Add(metadata *Metadata) {
+	s.Files++
+
+	h := metadata.mp3Header
+	s.Formats[fmt.Sprintf("MPEG-%s Layer %s", h.VersionName(), h.LayerName())]++
+	s.BitRates[h.BitRate]++
+	s.SampleRates[h.SampleFreq]++
+
+	if metadata.vbr {
+		s.VBR++
+	} else {
+		s.CBR++
+	}
+
+	versions := metadata.tagVersionNames()
+
+	if len(versions) > 0 {
+		s.Tagged++
+	} else {
+		s.Untagged++
+	}
+
+	for _, version := range versions {
+		s.TagVersions[version]++
+	}
+}
+
+// tagVersionNames returns the versions of every tag found: the leading ID3v2
+// tag, the appended one, which is always ID3v2.4 for its footer, and the
+// ID3v1 tag.
+func (metadata *Metadata) tagVersionNames() []string {
+	var versions []string
+
+	if metadata.tagSize > 0 {
+		versions = append(versions, metadata.tagVersionName())
+	}
+
+	if metadata.appendedTagSize > 0 {
+		versions = append(versions, "ID3v2.4")
+	}
+
+	if metadata.v1Tag != nil {
+		versions = append(versions, "ID3v1")
+	}
+
+	return versions
+}
+
+// AddFailure counts an input that could not be measured.
+func (s *Stats) AddFailure() {
+	s.Files++
+	s.Failed++
+}
+
+// String renders the stats as a compact table.
+func (s *Stats) String() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Files:\t%d\n", s.Files)
+	fmt.Fprintf(w, "Failed:\t%d\n", s.Failed)
+
+	writeSection(w, "Format", s.Formats)
+	writeSection(w, "Bit rate (kbps)", intKeys(s.BitRates))
+	writeSection(w, "Sample rate (Hz)", intKeys(s.SampleRates))
+	writeSection(w, "Bit rate mode", map[string]int{"CBR": s.CBR, "VBR": s.VBR})
+	writeSection(w, "Tag", map[string]int{"tagged": s.Tagged, "untagged": s.Untagged})
+	writeSection(w, "Tag version", s.TagVersions)
+
+	w.Flush()
+
+	// section titles have an empty second column, drop its padding
+	lines := strings.Split(sb.String(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func writeSection(w *tabwriter.Writer, title string, counts map[string]int) {
+	fmt.Fprintf(w, "%s:\t\n", title)
+
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		// numeric keys in numeric order
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %s\t%d\n", k, counts[k])
+	}
+}
+
+func intKeys(counts map[int]int) map[string]int {
+	m := make(map[string]int, len(counts))
+	for k, v := range counts {
+		m[strconv.Itoa(k)] = v
+	}
+	return m
+}
@@ -0,0 +1,96 @@
+package mp3len
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mp3len/internal/id3"
+)
+
+func TestStats(t *testing.T) {
+	// MPEG-1 Layer III, 320 kbps, 48000Hz
+	const highRateHeaderBits = 0xFFFBE444
+	// MPEG-2 Layer III, 64 kbps, 22050Hz
+	const mpeg2HeaderBits = 0xFFF38044
+	tagV24 := []byte("ID3\x04\x00\x00\x00\x00\x00\x00")
+
+	// 320 kbps at the 44100Hz of testHeaderBits
+	vbr := generateMP3(emptyTag, testHeaderBits, testFrameLength, 2)
+	vbr = append(vbr, generateMP3(nil, 0xFFFBE044, 1044, 2)...)
+
+	fixtures := []struct {
+		data []byte
+		opts []Option
+	}{
+		{generateMP3(emptyTag, testHeaderBits, testFrameLength, 10), nil},
+		{generateMP3(emptyTag, testHeaderBits, testFrameLength, 20), nil},
+		{generateMP3(tagV24, highRateHeaderBits, 960, 10), nil},
+		{generateMP3(tagV24, mpeg2HeaderBits, 208, 10), nil},
+		{vbr, []Option{WithFullScan()}},
+		{[]byte("not an mp3 file"), nil},
+	}
+
+	stats := NewStats()
+
+	for _, f := range fixtures {
+		metadata, err := GetInfo(bytes.NewReader(f.data), int64(len(f.data)), f.opts...)
+		if err != nil {
+			stats.AddFailure()
+		} else {
+			stats.Add(metadata)
+		}
+	}
+
+	stats.Add(&Metadata{mp3Header: mustParseHeader(t, testHeaderBits)}) // untagged
+
+	// only found by GetInfoAt
+	title := id3.Frame{ID: "TIT2"}
+	if err := title.SetText("Appended"); err != nil {
+		t.Fatal(err)
+	}
+
+	audio := generateMP3(nil, testHeaderBits, testFrameLength, 10)
+
+	for _, data := range [][]byte{
+		append(append([]byte{}, audio...), generateID3v1("Trailing")...),
+		append(append([]byte{}, audio...), generateAppendedTag(t, title)...),
+	} {
+		metadata, err := GetInfoAt(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("GetInfoAt() error = %v", err)
+		}
+		stats.Add(metadata)
+	}
+
+	want := &Stats{
+		Files:       9,
+		Failed:      1,
+		Formats:     map[string]int{"MPEG-1 Layer III": 7, "MPEG-2 Layer III": 1},
+		BitRates:    map[int]int{128: 6, 320: 1, 64: 1},
+		SampleRates: map[int]int{44100: 6, 48000: 1, 22050: 1},
+		CBR:         7,
+		VBR:         1,
+		Tagged:      7,
+		Untagged:    1,
+		TagVersions: map[string]int{"ID3v2.3": 3, "ID3v2.4": 3, "ID3v1": 1},
+	}
+
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats = %+v, want %+v", stats, want)
+	}
+
+	if _, err := json.Marshal(stats); err != nil {
+		t.Errorf("json.Marshal(Stats) error = %v", err)
+	}
+
+	table := stats.String()
+	lines := strings.Split(table, "\n")
+	for i, want := range []string{"Files: 9", "Failed: 1", "Format:", "MPEG-1 Layer III 7", "MPEG-2 Layer III 1"} {
+		if got := strings.Join(strings.Fields(lines[i]), " "); got != want {
+			t.Errorf("Stats.String() line %d = %q, want %q:\n%s", i, got, want, table)
+		}
+	}
+}
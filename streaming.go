@@ -0,0 +1,403 @@
+package mp3len
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"mp3len/internal/id3"
+	"mp3len/internal/mp3header"
+)
+
+type streamingStage int
+
+const (
+	stageTagHeader streamingStage = iota
+	stageSkipTag
+	stageFrameHeader
+	stageFirstFrame
+	stageScanHeader // of the next frame, in a full scan
+	stageScanFrame  // the rest of the frame, in a full scan
+	stageDone
+)
+
+// StreamingMeasurer measures an MP3 from bytes pushed into it, e.g. while the
+// file is being downloaded. The duration is known as soon as the ID3 tag and
+// the first frame have been written: exact when the frame holds a Xing, Info
+// or VBRI header declaring the frames, estimated otherwise.
+//
+// With WithFullScan, the frames are counted as they are written instead, and
+// the duration is known once all of them have been, i.e. totalSize bytes or up
+// to the first bytes that aren't a frame, e.g. an ID3v1 trailer.
+type StreamingMeasurer struct {
+	totalSize int64
+	o         *options
+
+	stage    streamingStage
+	buf      []byte // the structure currently being parsed
+	skip     int64  // bytes of the tag, or of a frame in a full scan, to skip
+	written  int64
+	metadata Metadata
+	err      error
+
+	headers    vbrHeaders          // of the first frame
+	frame      mp3header.MP3Header // being skipped in a full scan
+	rate       int                 // the bit rate of the frames, see walkFrames
+	frameBytes int64               // of the frames counted in a full scan
+}
+
+type streamingWriter struct {
+	m *StreamingMeasurer
+}
+
+// NewStreamingMeasurer returns a measurer for an input of totalSize bytes, and
+// the writer to feed the input to. The writer never fails, so it can be used
+// in an io.MultiWriter without interrupting a download; see Err instead.
+func NewStreamingMeasurer(totalSize int64, opts ...Option) (*StreamingMeasurer, io.Writer) {
+	m := &StreamingMeasurer{totalSize: totalSize, o: newOptions(opts)}
+
+	return m, streamingWriter{m}
+}
+
+func (w streamingWriter) Write(p []byte) (int, error) {
+	w.m.write(p)
+	return len(p), nil
+}
+
+func (m *StreamingMeasurer) write(p []byte) {
+	m.written += int64(len(p))
+
+	for len(p) > 0 && m.stage != stageDone {
+		switch m.stage {
+		case stageTagHeader:
+			// up to 6 bytes to see "ID3" behind a BOM, then the header
+			for len(m.buf) < 6 && len(p) > 0 && mayStartTag(m.buf) {
+				p = m.fill(p, len(m.buf)+1)
+			}
+
+			if !mayStartTag(m.buf) {
+				m.stage = stageFrameHeader
+				continue
+			}
+
+			if len(m.buf) < 6 {
+				return
+			}
+
+			junk := 0
+			if m.buf[0] != 'I' {
+				junk = 3
+			}
+
+			p = m.fill(p, junk+10)
+			if len(m.buf) < junk+10 {
+				return
+			}
+
+			tagSize, version, err := id3.ParseHeader(m.buf[junk:])
+			if err != nil {
+				m.fail(err)
+				return
+			}
+
+			m.metadata.tagSize = junk + tagSize
+			m.metadata.tagVersion = version
+			m.metadata.tagLocation = TagPrepended
+			m.skip = int64(m.metadata.tagSize - len(m.buf))
+			m.buf = m.buf[:0]
+			m.stage = stageSkipTag
+		case stageSkipTag:
+			n := m.skip
+			if int64(len(p)) < n {
+				n = int64(len(p))
+			}
+
+			p = p[n:]
+			m.skip -= n
+
+			if m.skip == 0 {
+				m.stage = stageFrameHeader
+			}
+		case stageFrameHeader:
+			p = m.fill(p, 4)
+			if len(m.buf) < 4 {
+				return
+			}
+
+			header, err := mp3header.Parse(binary.BigEndian.Uint32(m.buf))
+			if err != nil {
+				m.fail(err)
+				return
+			}
+
+			m.metadata.mp3Header = header
+			m.metadata.audioOffset = int64(m.metadata.tagSize)
+			m.buf = m.buf[:0]
+			m.stage = stageFirstFrame
+		case stageFirstFrame:
+			if length := m.metadata.mp3Header.FrameLength(); length > 4 {
+				p = m.fill(p, length-4)
+				if len(m.buf) < length-4 {
+					return
+				}
+			}
+
+			m.useFirstFrame()
+		case stageScanHeader:
+			p = m.fill(p, 4)
+			if len(m.buf) < 4 {
+				return
+			}
+
+			header, err := mp3header.Parse(binary.BigEndian.Uint32(m.buf))
+			if err != nil || !continuesStream(m.metadata.mp3Header, header) {
+				// not audio anymore
+				m.finishScan()
+				return
+			}
+
+			m.metadata.frameCount++
+
+			if m.metadata.frameCount == 2 && m.headers.headerFrame() {
+				m.rate = header.BitRate
+			} else if header.BitRate != m.rate {
+				m.metadata.vbr = true
+			}
+
+			m.buf = m.buf[:0]
+			m.scanFrame(header)
+		case stageScanFrame:
+			n := m.skip
+			if int64(len(p)) < n {
+				n = int64(len(p))
+			}
+
+			p = p[n:]
+			m.skip -= n
+
+			if m.skip == 0 {
+				m.frameBytes += int64(m.frame.FrameLength())
+				m.stage = stageScanHeader
+			}
+		}
+	}
+
+	if m.totalSize >= 0 && m.written >= m.totalSize {
+		m.finishInput()
+	}
+}
+
+// useFirstFrame parses the header the first frame may hold, now in m.buf, and
+// measures the audio from it, or starts the full scan.
+func (m *StreamingMeasurer) useFirstFrame() {
+	m.headers = m.metadata.parseVBRHeaders(m.buf)
+	m.buf = m.buf[:0]
+
+	var err error
+
+	switch {
+	case m.o.fullScan:
+		// frames can't be walked either, their length is unknown
+		if m.metadata.mp3Header.SampleFreq <= 0 {
+			m.fail(errZeroSampleFreq)
+			return
+		}
+
+		m.metadata.frameCount = 1
+		m.rate = m.metadata.mp3Header.BitRate
+		m.frameBytes = int64(m.metadata.mp3Header.FrameLength())
+		m.stage = stageScanHeader
+
+		return
+	case m.headers.declaresFrames():
+		err = m.metadata.useDeclaredHeader(m.headers, m.totalSize, m.o)
+	default:
+		err = m.metadata.estimate(m.totalSize, m.o)
+	}
+
+	if err == nil {
+		err = m.metadata.checkVBRHeaders(m.headers, m.totalSize, m.o)
+	}
+
+	m.done(err)
+}
+
+// scanFrame starts skipping the frame of header, whose header was read.
+func (m *StreamingMeasurer) scanFrame(header mp3header.MP3Header) {
+	length := header.FrameLength()
+
+	if length < 4 {
+		m.fail(errFreeFormat)
+		return
+	}
+
+	m.frame = header
+	m.skip = int64(length - 4)
+	m.stage = stageScanFrame
+
+	if m.skip == 0 {
+		m.frameBytes += int64(length)
+		m.stage = stageScanHeader
+	}
+}
+
+// finishInput ends the measurement once all totalSize bytes were written,
+// with a first or last frame cut short, as GetInfo would.
+func (m *StreamingMeasurer) finishInput() {
+	switch m.stage {
+	case stageFirstFrame:
+		m.useFirstFrame()
+
+		if m.stage != stageDone {
+			m.finishInput()
+		}
+	case stageScanHeader:
+		m.finishScan()
+	case stageScanFrame:
+		// the header is counted, as are the bytes written of the frame
+		incomplete := int64(m.frame.FrameLength()) - m.skip
+		m.metadata.incompleteFrameBytes = incomplete
+		m.frameBytes += incomplete
+		m.finishScan()
+	}
+}
+
+// finishScan measures the audio from the frames counted by the full scan.
+func (m *StreamingMeasurer) finishScan() {
+	err := m.metadata.useScan(m.frameBytes, m.headers, m.totalSize, m.o)
+
+	if err == nil {
+		err = m.metadata.checkVBRHeaders(m.headers, m.totalSize, m.o)
+	}
+
+	m.done(err)
+}
+
+// done ends the measurement, failed if err isn't nil.
+func (m *StreamingMeasurer) done(err error) {
+	if err != nil {
+		m.fail(err)
+		return
+	}
+
+	m.buf = nil
+	m.stage = stageDone
+}
+
+// mayStartTag tells whether b could be the beginning of a leading ID3 tag.
+func mayStartTag(b []byte) bool {
+	for _, prefix := range []string{"ID3", "\xEF\xBB\xBFID3"} {
+		n := len(b)
+		if n > len(prefix) {
+			n = len(prefix)
+		}
+
+		if string(b[:n]) == prefix[:n] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fill appends bytes from p to m.buf until it holds n bytes, and returns the
+// rest of p.
+func (m *StreamingMeasurer) fill(p []byte, n int) []byte {
+	need := n - len(m.buf)
+	if need <= 0 {
+		return p
+	}
+
+	if need > len(p) {
+		need = len(p)
+	}
+
+	m.buf = append(m.buf, p[:need]...)
+
+	return p[need:]
+}
+
+func (m *StreamingMeasurer) fail(err error) {
+	m.err = err
+	m.buf = nil
+	m.stage = stageDone
+}
+
+// Duration returns the duration, and whether enough bytes have been written
+// for it to be known.
+func (m *StreamingMeasurer) Duration() (time.Duration, bool) {
+	if m.stage != stageDone || m.err != nil {
+		return 0, false
+	}
+
+	return m.metadata.duration, true
+}
+
+// Metadata returns the metadata once Duration is known, or nil before that.
+func (m *StreamingMeasurer) Metadata() *Metadata {
+	if m.stage != stageDone || m.err != nil {
+		return nil
+	}
+
+	return &m.metadata
+}
+
+// Err returns the error that stopped measurement, if the written bytes don't
+// look like an MP3.
+func (m *StreamingMeasurer) Err() error {
+	return m.err
+}
+
+// Written returns the number of bytes written so far.
+func (m *StreamingMeasurer) Written() int64 {
+	return m.written
+}
+
+// ErrNeedMoreData is returned by Incremental.Result until the tag and the first
+// frame have been written, or all the frames with WithFullScan.
+var ErrNeedMoreData = errors.New("more data needed")
+
+// Incremental measures an MP3 received in chunks, e.g. by a server accepting
+// chunked uploads, without seeking nor buffering the input. Only the structure
+// currently being parsed is held, at most the first frame, for the Xing, Info
+// or VBRI header it may hold. See StreamingMeasurer.
+type Incremental struct {
+	m *StreamingMeasurer
+}
+
+// NewIncremental returns an Incremental for an input of totalSize bytes.
+func NewIncremental(totalSize int64, opts ...Option) *Incremental {
+	m, _ := NewStreamingMeasurer(totalSize, opts...)
+
+	return &Incremental{m: m}
+}
+
+// Write consumes the next chunk of the input, feeding the frames to the full
+// scan with WithFullScan. Once the result is known, it only counts the bytes
+// written. It never fails; see Result instead.
+func (d *Incremental) Write(p []byte) (int, error) {
+	d.m.write(p)
+	return len(p), nil
+}
+
+// Result returns the metadata once the tag and the first frame have been
+// written, or all the frames with WithFullScan, ErrNeedMoreData before that,
+// or the error that stopped measurement if the input doesn't look like an MP3.
+func (d *Incremental) Result() (*Metadata, error) {
+	if d.m.err != nil {
+		return nil, d.m.err
+	}
+
+	if d.m.stage != stageDone {
+		return nil, fmt.Errorf("%w: %d bytes written", ErrNeedMoreData, d.m.written)
+	}
+
+	return &d.m.metadata, nil
+}
+
+// Written returns the number of bytes written so far.
+func (d *Incremental) Written() int64 {
+	return d.m.written
+}
@@ -0,0 +1,177 @@
+package mp3len
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"testing"
+)
+
+func TestStreamingMeasurer(t *testing.T) {
+	tagData, err := ioutil.ReadFile("./internal/id3/testdata/id3_padded.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := map[string][]byte{
+		"tagged":   generateMP3(tagData, testHeaderBits, testFrameLength, 100),
+		"untagged": generateMP3(nil, testHeaderBits, testFrameLength, 100),
+		"BOM":      generateMP3(append([]byte{0xEF, 0xBB, 0xBF}, emptyTag...), testHeaderBits, testFrameLength, 100),
+	}
+
+	for name, data := range inputs {
+		want, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		readyAt := want.tagSize + want.mp3Header.FrameLength()
+
+		for _, chunkSize := range []int{1, 7, 1024, len(data)} {
+			t.Run(fmt.Sprintf("%s in chunks of %d", name, chunkSize), func(t *testing.T) {
+				m, w := NewStreamingMeasurer(int64(len(data)))
+
+				for offset := 0; offset < len(data); offset += chunkSize {
+					end := offset + chunkSize
+					if end > len(data) {
+						end = len(data)
+					}
+
+					if _, ok := m.Duration(); ok != (offset >= readyAt) {
+						t.Fatalf("Duration() ok = %v after %d bytes, want ready after %d", ok, offset, readyAt)
+					}
+
+					if n, err := w.Write(data[offset:end]); n != end-offset || err != nil {
+						t.Fatalf("Write() = (%v, %v)", n, err)
+					}
+				}
+
+				duration, ok := m.Duration()
+
+				if !ok || duration != want.duration {
+					t.Errorf("Duration() = (%v, %v), want (%v, true)", duration, ok, want.duration)
+				}
+
+				if m.Metadata().tagSize != want.tagSize {
+					t.Errorf("Metadata() tagSize = %v, want %v", m.Metadata().tagSize, want.tagSize)
+				}
+
+				if m.Written() != int64(len(data)) {
+					t.Errorf("Written() = %v, want %v", m.Written(), len(data))
+				}
+			})
+		}
+	}
+}
+
+func TestStreamingMeasurer_NotMP3(t *testing.T) {
+	m, w := NewStreamingMeasurer(100)
+
+	if _, err := w.Write([]byte("<html><body>Not Found</body></html>")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if m.Err() == nil {
+		t.Errorf("Err() = nil, want error")
+	}
+
+	if _, ok := m.Duration(); ok {
+		t.Errorf("Duration() ok = true, want false")
+	}
+}
+
+func TestIncremental(t *testing.T) {
+	tagData, err := ioutil.ReadFile("./internal/id3/testdata/id3_padded.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xing, err := ioutil.ReadFile("./testdata/corpus/xing.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := map[string][]byte{
+		"tagged":    generateMP3(tagData, testHeaderBits, testFrameLength, 100),
+		"untagged":  generateMP3(nil, testHeaderBits, testFrameLength, 100),
+		"Xing VBR":  xing,
+		"truncated": generateMP3(nil, testHeaderBits, testFrameLength, 100)[:100*testFrameLength-100],
+	}
+
+	options := map[string][]Option{
+		"estimate":  nil,
+		"full scan": {WithFullScan()},
+	}
+
+	for name, data := range inputs {
+		for optionsName, opts := range options {
+			want, err := GetInfo(bytes.NewReader(data), int64(len(data)), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the first frame, holding a Xing header or not, or all of them
+			readyAt := want.tagSize + want.mp3Header.FrameLength()
+			if len(opts) > 0 {
+				readyAt = len(data)
+			}
+
+			for _, chunkSize := range []int{1, 1024, len(data)} {
+				t.Run(fmt.Sprintf("%s, %s, in chunks of %d", name, optionsName, chunkSize), func(t *testing.T) {
+					d := NewIncremental(int64(len(data)), opts...)
+
+					for offset := 0; offset < len(data); offset += chunkSize {
+						end := offset + chunkSize
+						if end > len(data) {
+							end = len(data)
+						}
+
+						if _, err := d.Result(); errors.Is(err, ErrNeedMoreData) != (offset < readyAt) {
+							t.Fatalf("Result() error = %v after %d bytes, want ready after %d", err, offset, readyAt)
+						}
+
+						if n, err := d.Write(data[offset:end]); n != end-offset || err != nil {
+							t.Fatalf("Write() = (%v, %v)", n, err)
+						}
+					}
+
+					metadata, err := d.Result()
+
+					if err != nil {
+						t.Fatalf("Result() error = %v", err)
+					}
+
+					if metadata.Duration() != want.Duration() || metadata.tagSize != want.tagSize || metadata.audioOffset != want.audioOffset {
+						t.Errorf("Result() = (%v, tagSize %v, audioOffset %v), want (%v, %v, %v)",
+							metadata.Duration(), metadata.tagSize, metadata.audioOffset,
+							want.Duration(), want.tagSize, want.audioOffset)
+					}
+
+					if metadata.Confidence() != want.Confidence() || metadata.TotalSamples() != want.TotalSamples() || metadata.IsVBR() != want.IsVBR() || metadata.VBRHeaderType() != want.VBRHeaderType() {
+						t.Errorf("Result() = (%v, %v samples, VBR %v, %v header), want (%v, %v, %v, %v)",
+							metadata.Confidence(), metadata.TotalSamples(), metadata.IsVBR(), metadata.VBRHeaderType(),
+							want.Confidence(), want.TotalSamples(), want.IsVBR(), want.VBRHeaderType())
+					}
+
+					if metadata.AverageBitrate() != want.AverageBitrate() {
+						t.Errorf("Result() AverageBitrate() = %v, want %v", metadata.AverageBitrate(), want.AverageBitrate())
+					}
+
+					if d.Written() != int64(len(data)) {
+						t.Errorf("Written() = %v, want %v", d.Written(), len(data))
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestIncremental_NotMP3(t *testing.T) {
+	d := NewIncremental(100)
+	d.Write([]byte("<html><body>Not Found</body></html>"))
+
+	if _, err := d.Result(); err == nil || errors.Is(err, ErrNeedMoreData) {
+		t.Errorf("Result() error = %v, want a parse error", err)
+	}
+}
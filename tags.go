@@ -0,0 +1,203 @@
+package mp3len
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"mp3len/internal/id3"
+)
+
+// TagLocation tells where the ID3v2 tag of the input was found.
+type TagLocation int
+
+const (
+	TagNone      TagLocation = iota // no ID3v2 tag
+	TagPrepended                    // at the start of the input, the usual place
+	TagAppended                     // at the end of the input, with an ID3v2.4 footer
+)
+
+func (l TagLocation) String() string {
+	switch l {
+	case TagNone:
+		return "none"
+	case TagPrepended:
+		return "prepended"
+	case TagAppended:
+		return "appended"
+	default:
+		return fmt.Sprintf("TagLocation(%d)", int(l))
+	}
+}
+
+// TagSource tells which tag the values of Title, Artist and the like come
+// from.
+type TagSource int
+
+const (
+	TagSourceNone  TagSource = iota // no values, ID3v2 tags aren't read for them
+	TagSourceID3v1                  // the trailing ID3v1 tag, see GetInfoAt
+)
+
+func (s TagSource) String() string {
+	switch s {
+	case TagSourceNone:
+		return "none"
+	case TagSourceID3v1:
+		return "ID3v1"
+	default:
+		return fmt.Sprintf("TagSource(%d)", int(s))
+	}
+}
+
+// TagSource returns the tag Title, Artist and the like come from.
+func (metadata *Metadata) TagSource() TagSource {
+	if metadata.v1Tag != nil {
+		return TagSourceID3v1
+	}
+
+	return TagSourceNone
+}
+
+// Title returns the title of the track, or "" if unknown. See TagSource.
+func (metadata *Metadata) Title() string {
+	return metadata.v1Text(func(tag *id3.V1Tag) string { return tag.Title })
+}
+
+// Artist is like Title, for the artist.
+func (metadata *Metadata) Artist() string {
+	return metadata.v1Text(func(tag *id3.V1Tag) string { return tag.Artist })
+}
+
+// Album is like Title, for the album.
+func (metadata *Metadata) Album() string {
+	return metadata.v1Text(func(tag *id3.V1Tag) string { return tag.Album })
+}
+
+// Year is like Title, for the year of release.
+func (metadata *Metadata) Year() string {
+	return metadata.v1Text(func(tag *id3.V1Tag) string { return tag.Year })
+}
+
+// Comment is like Title, for the comment.
+func (metadata *Metadata) Comment() string {
+	return metadata.v1Text(func(tag *id3.V1Tag) string { return tag.Comment })
+}
+
+// Genre is like Title, for the name of the genre, e.g. "Rock".
+func (metadata *Metadata) Genre() string {
+	return metadata.v1Text(func(tag *id3.V1Tag) string { return id3.GenreName(tag.Genre) })
+}
+
+// Track returns the track number, or 0 if unknown. See TagSource.
+func (metadata *Metadata) Track() int {
+	if metadata.v1Tag == nil {
+		return 0
+	}
+
+	return metadata.v1Tag.Track
+}
+
+// Tags returns the tag values as a map of stable keys, like "title", "track"
+// or "genre", for templates and search indexes. Keys without value are left
+// out. Returns nil when TagSource is TagSourceNone.
+func (metadata *Metadata) Tags() map[string]string {
+	if metadata.v1Tag == nil {
+		return nil
+	}
+
+	return metadata.v1Tag.ToMap()
+}
+
+func (metadata *Metadata) v1Text(field func(tag *id3.V1Tag) string) string {
+	if metadata.v1Tag == nil {
+		return ""
+	}
+
+	return field(metadata.v1Tag)
+}
+
+// readV1Tag reads the ID3v1 tag in the last bytes of ra, if any.
+func readV1Tag(ra io.ReaderAt, size int64) (*id3.V1Tag, error) {
+	if size < id3.LenOfV1 {
+		return nil, nil
+	}
+
+	data := make([]byte, id3.LenOfV1)
+
+	if _, err := ra.ReadAt(data, size-id3.LenOfV1); err != nil {
+		return nil, err
+	}
+
+	tag, err := id3.ParseV1(data)
+
+	if err != nil {
+		// no ID3v1 tag
+		return nil, nil
+	}
+
+	return tag, nil
+}
+
+// findAppendedTag looks for an ID3v2.4 footer in the last bytes of ra, or right
+// before a trailing ID3v1 tag, where the spec places it when both are present.
+// Returns the offset and total size of the tag, or a size of 0 if there is
+// none.
+func findAppendedTag(ra io.ReaderAt, size int64) (offset int64, tagSize int64, err error) {
+	offset, tagSize, err = findTagEndingAt(ra, size)
+
+	if err != nil || tagSize > 0 || size < lenOfID3v1 {
+		return offset, tagSize, err
+	}
+
+	magic := make([]byte, len(id3v1Flag))
+
+	if _, err = ra.ReadAt(magic, size-lenOfID3v1); err != nil {
+		return 0, 0, err
+	}
+
+	if !bytes.Equal(magic, id3v1Flag) {
+		return 0, 0, nil
+	}
+
+	return findTagEndingAt(ra, size-lenOfID3v1)
+}
+
+// findTagEndingAt looks for an ID3v2.4 tag with a footer ending at end.
+func findTagEndingAt(ra io.ReaderAt, end int64) (offset int64, tagSize int64, err error) {
+	if end < 2*id3.LenOfFooter {
+		return 0, 0, nil
+	}
+
+	footer := make([]byte, id3.LenOfFooter)
+
+	if _, err = ra.ReadAt(footer, end-id3.LenOfFooter); err != nil {
+		return 0, 0, err
+	}
+
+	n, err := id3.ParseFooter(footer)
+
+	if err != nil {
+		// no footer, no appended tag
+		return 0, 0, nil
+	}
+
+	offset = end - int64(n)
+
+	if offset < 0 {
+		return 0, 0, fmt.Errorf("appended ID3 tag of %d bytes is larger than the input", n)
+	}
+
+	// the footer is a copy of the header, except for the magic
+	header := make([]byte, id3.LenOfFooter)
+
+	if _, err = ra.ReadAt(header, offset); err != nil {
+		return 0, 0, err
+	}
+
+	if !id3.HasLeadingTag(header, true) || string(header[3:]) != string(footer[3:]) {
+		return 0, 0, fmt.Errorf("ID3 footer points at %d, but no matching header found there", offset)
+	}
+
+	return offset, int64(n), nil
+}
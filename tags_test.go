@@ -0,0 +1,290 @@
+package mp3len
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"mp3len/internal/id3"
+)
+
+// generateTag builds an ID3v2.3 tag holding frames.
+func generateTag(t *testing.T, frames ...id3.Frame) []byte {
+	var tag bytes.Buffer
+
+	if err := id3.NewEncoder(&tag).Encode(&id3.Tag{Version: 3, Frames: frames}); err != nil {
+		t.Fatal(err)
+	}
+
+	return tag.Bytes()
+}
+
+// generateAppendedTag builds an ID3v2.4 tag with a footer, holding frames
+// small enough for their sizes to be the same as syncsafe integers.
+func generateAppendedTag(t *testing.T, frames ...id3.Frame) []byte {
+	var body bytes.Buffer
+
+	for _, frame := range frames {
+		b, err := frame.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body.Write(b)
+	}
+
+	size := []byte{0, 0, byte(body.Len() >> 7), byte(body.Len() & 0x7F)}
+
+	var tag bytes.Buffer
+	tag.WriteString("ID3\x04\x00\x10")
+	tag.Write(size)
+	tag.Write(body.Bytes())
+	tag.WriteString("3DI\x04\x00\x10")
+	tag.Write(size)
+
+	return tag.Bytes()
+}
+
+func TestGetInfoAt_AppendedTag(t *testing.T) {
+	title := id3.Frame{ID: "TIT2"}
+	if err := title.SetText("Appended"); err != nil {
+		t.Fatal(err)
+	}
+
+	audio := generateMP3(nil, testHeaderBits, testFrameLength, 100)
+	tag := generateAppendedTag(t, title)
+	data := append(append([]byte{}, audio...), tag...)
+
+	metadata, err := GetInfoAt(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfoAt() error = %v", err)
+	}
+
+	if metadata.TagLocation() != TagAppended {
+		t.Errorf("GetInfoAt() TagLocation() = %v, want %v", metadata.TagLocation(), TagAppended)
+	}
+
+	if metadata.appendedTagSize != int64(len(tag)) || metadata.appendedTagOffset != int64(len(audio)) {
+		t.Errorf("GetInfoAt() appended tag at %d, %d bytes, want at %d, %d bytes",
+			metadata.appendedTagOffset, metadata.appendedTagSize, len(audio), len(tag))
+	}
+
+	// same as without the tag
+	want, err := GetInfo(bytes.NewReader(audio), int64(len(audio)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.duration != want.duration {
+		t.Errorf("GetInfoAt() duration = %v, want %v", metadata.duration, want.duration)
+	}
+
+	decoded, err := id3.NewDecoder(io.NewSectionReader(bytes.NewReader(data), metadata.appendedTagOffset, metadata.appendedTagSize)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() of appended tag error = %v", err)
+	}
+
+	if len(decoded.Frames) != 1 || decoded.Frames[0].ID != "TIT2" {
+		t.Errorf("Decode() of appended tag frames = %v, want [TIT2]", decoded.Frames)
+	}
+}
+
+func TestGetInfoAt_AppendedTagPlacement(t *testing.T) {
+	title := id3.Frame{ID: "TIT2"}
+	if err := title.SetText("Appended"); err != nil {
+		t.Fatal(err)
+	}
+
+	audio := generateMP3(nil, testHeaderBits, testFrameLength, 100)
+	tag := generateAppendedTag(t, title)
+	id3v1 := append([]byte("TAG"), make([]byte, 125)...)
+
+	tests := []struct {
+		name         string
+		leading      []byte
+		trailing     []byte
+		wantLocation TagLocation
+	}{
+		{"before an ID3v1 tag", nil, id3v1, TagAppended},
+		{"with a leading tag", emptyTag, nil, TagPrepended},
+		{"with a leading tag, before an ID3v1 tag", emptyTag, id3v1, TagPrepended},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data []byte
+			data = append(data, tt.leading...)
+			data = append(data, audio...)
+			data = append(data, tag...)
+			data = append(data, tt.trailing...)
+
+			metadata, err := GetInfoAt(bytes.NewReader(data), int64(len(data)))
+
+			if err != nil {
+				t.Fatalf("GetInfoAt() error = %v", err)
+			}
+
+			if metadata.TagLocation() != tt.wantLocation {
+				t.Errorf("GetInfoAt() TagLocation() = %v, want %v", metadata.TagLocation(), tt.wantLocation)
+			}
+
+			if want := int64(len(tt.leading) + len(audio)); metadata.appendedTagOffset != want {
+				t.Errorf("GetInfoAt() appendedTagOffset = %v, want %v", metadata.appendedTagOffset, want)
+			}
+
+			if want := 2606 * time.Millisecond; metadata.Duration() != want {
+				t.Errorf("GetInfoAt() Duration() = %v, want %v", metadata.Duration(), want)
+			}
+		})
+	}
+}
+
+func TestGetInfoAt_NoTag(t *testing.T) {
+	data := generateMP3(nil, testHeaderBits, testFrameLength, 100)
+
+	metadata, err := GetInfoAt(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfoAt() error = %v", err)
+	}
+
+	if metadata.TagLocation() != TagNone {
+		t.Errorf("GetInfoAt() TagLocation() = %v, want %v", metadata.TagLocation(), TagNone)
+	}
+}
+
+func TestGetInfoAt_PrependedTag(t *testing.T) {
+	data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+
+	metadata, err := GetInfoAt(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfoAt() error = %v", err)
+	}
+
+	if metadata.TagLocation() != TagPrepended {
+		t.Errorf("GetInfoAt() TagLocation() = %v, want %v", metadata.TagLocation(), TagPrepended)
+	}
+}
+
+func TestGetInfo_PrependedTagFooter(t *testing.T) {
+	// an empty ID3v2.4 tag, but for 16 bytes of padding, and its footer
+	tag := append([]byte("ID3\x04\x00\x10\x00\x00\x00\x10"), make([]byte, 16)...)
+	tag = append(tag, []byte("3DI\x04\x00\x10\x00\x00\x00\x10")...)
+	data := generateMP3(tag, testHeaderBits, testFrameLength, 100)
+
+	metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	if metadata.tagSize != len(tag) || metadata.gapAfterTag != 0 {
+		t.Errorf("GetInfo() tagSize = %v, gapAfterTag = %v, want %v, 0", metadata.tagSize, metadata.gapAfterTag, len(tag))
+	}
+
+	d := NewIncremental(int64(len(data)))
+	d.Write(data)
+
+	if metadata, err := d.Result(); err != nil {
+		t.Errorf("Incremental Result() error = %v", err)
+	} else if metadata.tagSize != len(tag) {
+		t.Errorf("Incremental Result() tagSize = %v, want %v", metadata.tagSize, len(tag))
+	}
+}
+
+func TestGetInfo_StackedTags(t *testing.T) {
+	// a binary payload that looks like the start of another tag
+	fakeHeader := []byte("ID3\x03\x00\x00\x00\x7F\x7F\x7F")
+	outer := generateTag(t, id3.Frame{ID: "PRIV", Data: append(append([]byte{}, fakeHeader...), make([]byte, 20)...)})
+	second := generateTag(t, id3.Frame{ID: "TIT2", Data: []byte("\x00Title\x00")})
+
+	tests := []struct {
+		name        string
+		tags        []byte
+		wantTagSize int
+		wantGap     int64
+	}{
+		{"tag-like frame payload", outer, len(outer), 0},
+		{"two tags", append(append([]byte{}, outer...), second...), len(outer) + len(second), 0},
+		{"tag header too large for the input", append(append([]byte{}, outer...), fakeHeader...), len(outer), int64(len(fakeHeader))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := generateMP3(tt.tags, testHeaderBits, testFrameLength, 100)
+
+			metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)))
+
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if metadata.tagSize != tt.wantTagSize {
+				t.Errorf("GetInfo() tagSize = %v, want %v", metadata.tagSize, tt.wantTagSize)
+			}
+
+			if metadata.GapAfterTag() != tt.wantGap {
+				t.Errorf("GetInfo() GapAfterTag() = %v, want %v", metadata.GapAfterTag(), tt.wantGap)
+			}
+
+			if want := 2606 * time.Millisecond; metadata.Duration() != want {
+				t.Errorf("GetInfo() Duration() = %v, want %v", metadata.Duration(), want)
+			}
+		})
+	}
+}
+
+func TestGetInfoAt_ID3v1Only(t *testing.T) {
+	audio := generateMP3(nil, testHeaderBits, testFrameLength, 100)
+
+	v1 := generateID3v1("Title")
+	copy(v1[33:], "Artist")
+	copy(v1[63:], "Album")
+	copy(v1[93:], "1999")
+	copy(v1[97:], "Comment")
+	v1[126], v1[127] = 5, 17 // ID3v1.1 track, Rock
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantSource TagSource
+	}{
+		{"ID3v1 only", append(append([]byte{}, audio...), v1...), TagSourceID3v1},
+		{"with a leading tag", append(append(append([]byte{}, emptyTag...), audio...), v1...), TagSourceNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := GetInfoAt(bytes.NewReader(tt.data), int64(len(tt.data)))
+
+			if err != nil {
+				t.Fatalf("GetInfoAt() error = %v", err)
+			}
+
+			if metadata.TagSource() != tt.wantSource {
+				t.Fatalf("GetInfoAt() TagSource() = %v, want %v", metadata.TagSource(), tt.wantSource)
+			}
+
+			if tt.wantSource == TagSourceNone {
+				if metadata.Title() != "" || metadata.Track() != 0 {
+					t.Errorf("GetInfoAt() Title() = %q, Track() = %v, want none", metadata.Title(), metadata.Track())
+				}
+				return
+			}
+
+			got := []interface{}{metadata.Title(), metadata.Artist(), metadata.Album(), metadata.Year(), metadata.Comment(), metadata.Track(), metadata.Genre()}
+			want := []interface{}{"Title", "Artist", "Album", "1999", "Comment", 5, "Rock"}
+
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("GetInfoAt() tag values = %v, want %v", got, want)
+					break
+				}
+			}
+
+			// the 128 bytes of the tag are not audio
+			if want := 2606 * time.Millisecond; metadata.Duration() != want {
+				t.Errorf("GetInfoAt() Duration() = %v, want %v", metadata.Duration(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package mp3len
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTimestamp parses a time as users type it, in the first of these forms
+// that matches:
+//
+//   - a clock time, H:MM:SS or MM:SS, e.g. "1:02:03" or "62:03.5"
+//   - bare seconds, e.g. "3723" or "3723.5"
+//   - a Go duration, e.g. "1h2m3s" or "62m"
+//
+// Fractions of a second finer than nanoseconds are truncated. Negative times
+// and times that don't fit in a time.Duration are rejected.
+func ParseTimestamp(s string) (time.Duration, error) {
+	fail := func(reason string) (time.Duration, error) {
+		return 0, fmt.Errorf("could not parse %q: %s", s, reason)
+	}
+
+	trimmed := strings.TrimSpace(s)
+
+	switch {
+	case trimmed == "":
+		return fail("empty time")
+	case strings.HasPrefix(trimmed, "-"):
+		return fail("time must not be negative")
+	case strings.Contains(trimmed, ":"):
+		d, reason := parseClock(trimmed)
+		if reason != "" {
+			return fail(reason)
+		}
+		return d, nil
+	case isDecimal(trimmed):
+		d, reason := parseSeconds(trimmed)
+		if reason != "" {
+			return fail(reason)
+		}
+		return d, nil
+	}
+
+	d, err := time.ParseDuration(trimmed)
+
+	if err != nil {
+		return fail("want H:MM:SS, MM:SS, seconds or a duration like 1h2m3s")
+	}
+
+	return d, nil
+}
+
+// parseClock parses H:MM:SS or MM:SS, where seconds may have a fraction. The
+// reason is empty on success.
+func parseClock(s string) (time.Duration, string) {
+	fields := strings.Split(s, ":")
+
+	if len(fields) > 3 {
+		return 0, "want at most H:MM:SS"
+	}
+
+	seconds, reason := parseSeconds(fields[len(fields)-1])
+
+	if reason != "" {
+		return 0, reason
+	}
+
+	if seconds >= time.Minute {
+		return 0, "seconds must be < 60"
+	}
+
+	minutes, reason := parseWhole(fields[len(fields)-2], "minutes")
+
+	if reason != "" {
+		return 0, reason
+	}
+
+	var hours int64
+
+	if len(fields) == 3 {
+		if minutes >= 60 {
+			return 0, "minutes must be < 60"
+		}
+
+		if hours, reason = parseWhole(fields[0], "hours"); reason != "" {
+			return 0, reason
+		}
+	}
+
+	d, ok := addScaled(seconds, minutes, time.Minute)
+
+	if ok {
+		d, ok = addScaled(d, hours, time.Hour)
+	}
+
+	if !ok {
+		return 0, "time is too long"
+	}
+
+	return d, ""
+}
+
+// parseWhole parses a field of a clock time other than seconds.
+func parseWhole(s string, name string) (int64, string) {
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, name + " must be a whole number"
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+
+	if err != nil {
+		return 0, "time is too long"
+	}
+
+	return n, ""
+}
+
+// isDecimal tells whether s is digits with at most one decimal point.
+func isDecimal(s string) bool {
+	return strings.Trim(s, ".0123456789") == "" &&
+		strings.Count(s, ".") <= 1 &&
+		strings.ContainsAny(s, "0123456789")
+}
+
+// parseSeconds parses a decimal number of seconds, e.g. "3" or "3.25". The
+// reason is empty on success.
+func parseSeconds(s string) (time.Duration, string) {
+	if !isDecimal(s) {
+		return 0, "seconds must be a number"
+	}
+
+	whole, fraction := s, ""
+
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+
+	var d time.Duration
+
+	if len(fraction) > 9 {
+		fraction = fraction[:9]
+	}
+
+	if fraction != "" {
+		// digits only, of 9 at most, so it fits
+		n, _ := strconv.ParseInt(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64)
+		d = time.Duration(n)
+	}
+
+	if whole == "" {
+		return d, ""
+	}
+
+	n, err := strconv.ParseInt(whole, 10, 64)
+
+	if err != nil {
+		return 0, "time is too long"
+	}
+
+	d, ok := addScaled(d, n, time.Second)
+
+	if !ok {
+		return 0, "time is too long"
+	}
+
+	return d, ""
+}
+
+// addScaled returns d + n*unit, or false when it overflows. d and n must not
+// be negative.
+func addScaled(d time.Duration, n int64, unit time.Duration) (time.Duration, bool) {
+	if n > (math.MaxInt64-int64(d))/int64(unit) {
+		return 0, false
+	}
+
+	return d + time.Duration(n)*unit, true
+}
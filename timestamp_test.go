@@ -0,0 +1,101 @@
+package mp3len
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	hms := time.Hour + 2*time.Minute + 3*time.Second
+
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr string
+	}{
+		// clock
+		{"1:02:03", hms, ""},
+		{"01:02:03", hms, ""},
+		{"1:02:03.5", hms + 500*time.Millisecond, ""},
+		{"1:02:03.250", hms + 250*time.Millisecond, ""},
+		{"62:03", hms, ""},
+		{"0:00", 0, ""},
+		{"0:59.999", 59999 * time.Millisecond, ""},
+		{"100:00:00", 100 * time.Hour, ""},
+		{"1:75", 0, "seconds must be < 60"},
+		{"1:60:00", 0, "minutes must be < 60"},
+		{"1:02:60", 0, "seconds must be < 60"},
+		{"1:2:3:4", 0, "want at most H:MM:SS"},
+		{"1:02:", 0, "seconds must be a number"},
+		{":30", 0, "minutes must be a whole number"},
+		{"1.5:00", 0, "minutes must be a whole number"},
+		{"a:02:03", 0, "hours must be a whole number"},
+		{"1:02:0x", 0, "seconds must be a number"},
+		{"1:-02:03", 0, "minutes must be a whole number"},
+		{"2562047:47:16.854775807", 2562047*time.Hour + 47*time.Minute + 16854775807*time.Nanosecond, ""},
+		{"2562047:47:16.854775808", 0, "time is too long"},
+		{"99999999999999999999:00:00", 0, "time is too long"},
+
+		// bare seconds
+		{"3723", hms, ""},
+		{"3723.5", hms + 500*time.Millisecond, ""},
+		{"0.001", time.Millisecond, ""},
+		{".5", 500 * time.Millisecond, ""},
+		{"5.", 5 * time.Second, ""},
+		{"0.1234567891", 123456789, ""},
+		{"  42  ", 42 * time.Second, ""},
+		{"9223372036", 9223372036 * time.Second, ""},
+		{"9223372037", 0, "time is too long"},
+		{"99999999999999999999", 0, "time is too long"},
+
+		// Go durations
+		{"1h2m3s", hms, ""},
+		{"62m", 62 * time.Minute, ""},
+		{"1.5h", 90 * time.Minute, ""},
+		{"+3s", 3 * time.Second, ""},
+		{"300ms", 300 * time.Millisecond, ""},
+		{"3x", 0, "want H:MM:SS, MM:SS, seconds or a duration"},
+		{"1.2.3", 0, "want H:MM:SS, MM:SS, seconds or a duration"},
+		{"3000000h", 0, "want H:MM:SS, MM:SS, seconds or a duration"},
+
+		// negative and empty
+		{"-1", 0, "must not be negative"},
+		{"-1:00", 0, "must not be negative"},
+		{"-1h", 0, "must not be negative"},
+		{"+-1h", 0, "want H:MM:SS, MM:SS, seconds or a duration"},
+		{"", 0, "empty time"},
+		{"   ", 0, "empty time"},
+		{".", 0, "want H:MM:SS, MM:SS, seconds or a duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.input)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseTimestamp() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseTimestamp() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimestamp_ErrorMessage(t *testing.T) {
+	_, err := ParseTimestamp("1:75")
+
+	want := `could not parse "1:75": seconds must be < 60`
+
+	if err == nil || err.Error() != want {
+		t.Errorf("ParseTimestamp() error = %v, want %q", err, want)
+	}
+}
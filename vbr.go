@@ -0,0 +1,140 @@
+package mp3len
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"mp3len/internal/mp3header"
+)
+
+// quickVBRFrames is how many frames GetInfoAt samples to detect VBR.
+const quickVBRFrames = 16
+
+// QuickVBRCheck tells whether the bit rate varies over the first k frames,
+// starting with the frame of header h at firstFrameOffset. Only the 4-byte
+// headers are read, at the offsets predicted by FrameLength, i.e. k-1 reads of
+// ra. distinctRates lists the bit rates seen in ascending order.
+//
+// Fewer frames are sampled when the input ends, or stops looking like audio,
+// before the k-th frame.
+func QuickVBRCheck(ra io.ReaderAt, firstFrameOffset int64, h mp3header.MP3Header, k int) (isVBR bool, distinctRates []int, err error) {
+	rates, err := sampleBitRates(ra, firstFrameOffset, h, k)
+
+	if err != nil {
+		return false, nil, err
+	}
+
+	seen := make(map[int]bool)
+
+	for _, rate := range rates {
+		if !seen[rate] {
+			seen[rate] = true
+			distinctRates = append(distinctRates, rate)
+		}
+	}
+
+	sort.Ints(distinctRates)
+
+	return len(distinctRates) > 1, distinctRates, nil
+}
+
+// sampleBitRates returns the bit rates of up to k frames, starting with h.
+func sampleBitRates(ra io.ReaderAt, offset int64, h mp3header.MP3Header, k int) ([]int, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	rates := []int{h.BitRate}
+	data := make([]byte, 4)
+
+	for len(rates) < k {
+		length := h.FrameLength()
+
+		if length <= 0 {
+			break
+		}
+
+		offset += int64(length)
+
+		if _, err := ra.ReadAt(data, offset); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		next, err := mp3header.Parse(binary.BigEndian.Uint32(data))
+
+		if err != nil {
+			// e.g. an ID3v1 tag
+			break
+		}
+
+		h = next
+		rates = append(rates, h.BitRate)
+	}
+
+	return rates, nil
+}
+
+// sampleVBR samples the first frames of ra, setting vbr and the bit rates used
+// to estimate the duration when it varies. With headerFrame, the first frame
+// holds a Xing, Info or VBRI header, and sampling starts at the second one: the
+// bit rate of the header frame is often another, e.g. 320 kbps in a 128 kbps
+// CBR stream.
+func (metadata *Metadata) sampleVBR(ra io.ReaderAt, headerFrame bool) error {
+	offset, first := metadata.audioOffset, metadata.mp3Header
+
+	if headerFrame {
+		offset += int64(first.FrameLength())
+		data := make([]byte, 4)
+
+		if _, err := ra.ReadAt(data, offset); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		next, err := mp3header.Parse(binary.BigEndian.Uint32(data))
+
+		if err != nil {
+			// no audio after the header frame
+			return nil
+		}
+
+		first = next
+	}
+
+	rates, err := sampleBitRates(ra, offset, first, quickVBRFrames)
+
+	if err != nil {
+		return err
+	}
+
+	sum := 0
+
+	for _, rate := range rates {
+		sum += rate
+
+		if rate != first.BitRate {
+			metadata.vbr = true
+		}
+	}
+
+	// the duration is estimated from the bit rate of the audio, not that of
+	// the header frame
+	if metadata.vbr || first.BitRate != metadata.mp3Header.BitRate {
+		metadata.sampledFrames = len(rates)
+		metadata.sampledBitRateSum = sum
+	}
+
+	if metadata.vbr {
+		metadata.warnings = append(metadata.warnings, fmt.Sprintf(
+			"bit rate varies over the first %d frames, duration estimated from their average of %d kbps",
+			len(rates), sum/len(rates),
+		))
+	}
+
+	return nil
+}
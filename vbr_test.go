@@ -0,0 +1,468 @@
+package mp3len
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"mp3len/internal/mp3header"
+)
+
+// MPEG-1 Layer III, 64 kbps, 44100Hz, joint stereo, no CRC
+const testLowHeaderBits = 0xFFFB5044
+const testLowFrameLength = 208 // 144 * 64000 / 44100
+
+// generateVBR builds pairs of 128 and 64 kbps frames.
+func generateVBR(pairs int) []byte {
+	var buf bytes.Buffer
+
+	for i := 0; i < pairs; i++ {
+		buf.Write(generateMP3(nil, testHeaderBits, testFrameLength, 1))
+		buf.Write(generateMP3(nil, testLowHeaderBits, testLowFrameLength, 1))
+	}
+
+	return buf.Bytes()
+}
+
+// countingReaderAt counts the calls to ReadAt.
+type countingReaderAt struct {
+	ra    io.ReaderAt
+	calls int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.calls++
+	return c.ra.ReadAt(p, off)
+}
+
+func TestQuickVBRCheck(t *testing.T) {
+	header, err := mp3header.Parse(testHeaderBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		k         int
+		wantVBR   bool
+		wantRates []int
+		wantCalls int
+	}{
+		{"CBR", generateMP3(nil, testHeaderBits, testFrameLength, 100), 10, false, []int{128}, 9},
+		{"VBR", generateVBR(50), 10, true, []int{64, 128}, 9},
+		{"fewer frames than k", generateMP3(nil, testHeaderBits, testFrameLength, 3), 10, false, []int{128}, 3},
+		{"followed by an ID3v1 tag", append(generateVBR(1), append([]byte("TAG"), make([]byte, 125)...)...), 10, true, []int{64, 128}, 2},
+		{"first frame only", generateVBR(50), 1, false, []int{128}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ra := &countingReaderAt{ra: bytes.NewReader(tt.data)}
+
+			isVBR, rates, err := QuickVBRCheck(ra, 0, header, tt.k)
+
+			if err != nil {
+				t.Fatalf("QuickVBRCheck() error = %v", err)
+			}
+
+			if isVBR != tt.wantVBR {
+				t.Errorf("QuickVBRCheck() isVBR = %v, want %v", isVBR, tt.wantVBR)
+			}
+
+			if !reflect.DeepEqual(rates, tt.wantRates) {
+				t.Errorf("QuickVBRCheck() distinctRates = %v, want %v", rates, tt.wantRates)
+			}
+
+			if ra.calls != tt.wantCalls {
+				t.Errorf("QuickVBRCheck() called ReadAt %d times, want %d", ra.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetInfoAt_VBR(t *testing.T) {
+	data := append(append([]byte{}, emptyTag...), generateVBR(50)...)
+
+	metadata, err := GetInfoAt(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfoAt() error = %v", err)
+	}
+
+	if !metadata.IsVBR() {
+		t.Errorf("GetInfoAt() IsVBR() = false, want true")
+	}
+
+	// 31250 bytes at 96 kbps on average
+	if want := 2604 * time.Millisecond; metadata.Duration() != want {
+		t.Errorf("GetInfoAt() Duration() = %v, want %v", metadata.Duration(), want)
+	}
+
+	if len(metadata.Warnings()) != 1 {
+		t.Errorf("GetInfoAt() Warnings() = %v, want 1 warning", metadata.Warnings())
+	}
+
+	// a plain io.Reader is not sampled
+	metadata, err = GetInfo(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	if metadata.IsVBR() {
+		t.Errorf("GetInfo() IsVBR() = true, want false")
+	}
+}
+
+func TestGetInfoAt_MonoVBR(t *testing.T) {
+	var data bytes.Buffer
+	data.Write(emptyTag)
+
+	// generateVBR, but mono: the frames are as long
+	for i := 0; i < 50; i++ {
+		data.Write(generateMP3(nil, testHeaderBits|0xC0, testFrameLength, 1))
+		data.Write(generateMP3(nil, testLowHeaderBits|0xC0, testLowFrameLength, 1))
+	}
+
+	metadata, err := GetInfoAt(bytes.NewReader(data.Bytes()), int64(data.Len()))
+
+	if err != nil {
+		t.Fatalf("GetInfoAt() error = %v", err)
+	}
+
+	// the average bit rate makes the duration, not doubled like the estimate
+	if want := 2604 * time.Millisecond; metadata.Duration() != want {
+		t.Errorf("GetInfoAt() Duration() = %v, want %v", metadata.Duration(), want)
+	}
+}
+
+func TestMetadata_AverageBitrate(t *testing.T) {
+	// 31250 bytes of 128 and 64 kbps frames, 100 frames of 1152 samples
+	data := append(append([]byte{}, emptyTag...), generateVBR(50)...)
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		want        int
+		wantVerbose string
+	}{
+		// 31250 * 8 bits / 2612.24ms
+		{"full scan", []Option{WithFullScan()}, 96, "Bit rate: VBR ~96 kbps (first frame 128)\n"},
+		{"estimated", nil, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := GetInfo(bytes.NewReader(data), int64(len(data)), tt.opts...)
+
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if metadata.AverageBitrate() != tt.want {
+				t.Errorf("AverageBitrate() = %v, want %v", metadata.AverageBitrate(), tt.want)
+			}
+
+			if metadata.NominalBitrate() != 128 {
+				t.Errorf("NominalBitrate() = %v, want %v", metadata.NominalBitrate(), 128)
+			}
+
+			verbose := metadata.String(true)
+
+			if tt.wantVerbose != "" && !strings.Contains(verbose, tt.wantVerbose) || tt.wantVerbose == "" && strings.Contains(verbose, "Bit rate:") {
+				t.Errorf("String(true) = %q, want %q", verbose, tt.wantVerbose)
+			}
+
+			b, err := json.Marshal(metadata)
+
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+
+			var decoded struct {
+				Audio map[string]interface{} `json:"audio"`
+			}
+
+			if err = json.Unmarshal(b, &decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			got, ok := decoded.Audio["averageBitRate"]
+
+			if ok != (tt.want > 0) || ok && got != float64(tt.want) {
+				t.Errorf("json.Marshal() averageBitRate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_averageBitRate(t *testing.T) {
+	tests := []struct {
+		size     int64
+		duration time.Duration
+		want     int
+	}{
+		{41700, 2606 * time.Millisecond, 128},
+		{1000, 3 * time.Millisecond, 2667},
+		{1000, 0, 0},
+		{0, time.Second, 0},
+	}
+	for _, tt := range tests {
+		if got := averageBitRate(tt.size, tt.duration); got != tt.want {
+			t.Errorf("averageBitRate(%v, %v) = %v, want %v", tt.size, tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestGetInfoAt_InfoFrame(t *testing.T) {
+	// a CBR 128 kbps stream whose Info frame is 320 kbps
+	info := generateMP3(nil, 0xFFFBE044, 1044, 1)
+	copy(info[4+32:], "Info")
+	data := append(info, generateMP3(nil, testHeaderBits, testFrameLength, 100)...)
+
+	metadata, err := GetInfoAt(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatalf("GetInfoAt() error = %v", err)
+	}
+
+	if metadata.IsVBR() {
+		t.Errorf("GetInfoAt() IsVBR() = true, want false")
+	}
+
+	if got := metadata.sampledBitRateSum / metadata.sampledFrames; got != 128 || metadata.sampledBitRateSum%metadata.sampledFrames != 0 {
+		t.Errorf("GetInfoAt() sampled %d frames of %d kbps in total, want an average of exactly 128", metadata.sampledFrames, metadata.sampledBitRateSum)
+	}
+
+	// 42744 bytes at 128 kbps
+	if want := 2671 * time.Millisecond; metadata.Duration() != want {
+		t.Errorf("GetInfoAt() Duration() = %v, want %v", metadata.Duration(), want)
+	}
+
+	if len(metadata.Warnings()) != 0 {
+		t.Errorf("GetInfoAt() Warnings() = %v, want none", metadata.Warnings())
+	}
+
+	metadata, err = GetInfo(bytes.NewReader(data), int64(len(data)), WithFullScan())
+
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+
+	if metadata.IsVBR() {
+		t.Errorf("GetInfo() with full scan IsVBR() = true, want false")
+	}
+}
+
+func TestGetInfo_XingFrames(t *testing.T) {
+	// generateXing writes a Xing header with the given flags, frames and bytes
+	// in the first of 100 frames of 128 kbps.
+	generateXing := func(magic string, flags uint32, frames, size int) []byte {
+		data := generateMP3(nil, testHeaderBits, testFrameLength, 100)
+		copy(data[4+32:], magic)
+		binary.BigEndian.PutUint32(data[4+32+4:], flags)
+		binary.BigEndian.PutUint32(data[4+32+8:], uint32(frames))
+		binary.BigEndian.PutUint32(data[4+32+12:], uint32(size))
+		return data
+	}
+
+	header, _ := mp3header.Parse(testHeaderBits)
+
+	tests := []struct {
+		name           string
+		data           []byte
+		wantFromXing   bool
+		wantDuration   time.Duration
+		wantVBR        bool
+		wantAverage    int
+		wantConfidence Confidence
+	}{
+		{
+			name:           "Xing with frames",
+			data:           generateXing("Xing", 0x3, 200, 41700),
+			wantFromXing:   true,
+			wantDuration:   ExactDuration(header, 200, 0, 0),
+			wantVBR:        true,
+			wantAverage:    63,
+			wantConfidence: ConfidenceExact,
+		},
+		{
+			name:           "Info with frames",
+			data:           generateXing("Info", 0x1, 99, 0),
+			wantFromXing:   true,
+			wantDuration:   ExactDuration(header, 99, 0, 0),
+			wantAverage:    128,
+			wantConfidence: ConfidenceExact,
+		},
+		{
+			name:           "Xing without frames",
+			data:           generateXing("Xing", 0x2, 0, 41700),
+			wantDuration:   2606 * time.Millisecond,
+			wantConfidence: ConfidenceEstimated,
+		},
+		{
+			name:           "no Xing header",
+			data:           generateMP3(nil, testHeaderBits, testFrameLength, 100),
+			wantDuration:   2606 * time.Millisecond,
+			wantConfidence: ConfidenceEstimated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := GetInfo(bytes.NewReader(tt.data), int64(len(tt.data)))
+
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if metadata.DurationFromXing() != tt.wantFromXing {
+				t.Errorf("GetInfo() DurationFromXing() = %v, want %v", metadata.DurationFromXing(), tt.wantFromXing)
+			}
+
+			if metadata.Duration() != tt.wantDuration || metadata.Confidence() != tt.wantConfidence {
+				t.Errorf("GetInfo() = %v (%v), want %v (%v)", metadata.Duration(), metadata.Confidence(), tt.wantDuration, tt.wantConfidence)
+			}
+
+			if metadata.IsVBR() != tt.wantVBR {
+				t.Errorf("GetInfo() IsVBR() = %v, want %v", metadata.IsVBR(), tt.wantVBR)
+			}
+
+			if metadata.AverageBitrate() != tt.wantAverage {
+				t.Errorf("GetInfo() AverageBitrate() = %v, want %v", metadata.AverageBitrate(), tt.wantAverage)
+			}
+		})
+	}
+}
+
+func TestGetInfo_VBRI(t *testing.T) {
+	// generateVBRI writes a VBRI header of the given version, frames and bytes
+	// in the first of 100 frames of 128 kbps.
+	generateVBRI := func(version, frames, size int) []byte {
+		data := generateMP3(nil, testHeaderBits, testFrameLength, 100)
+		copy(data[4+32:], "VBRI")
+		binary.BigEndian.PutUint16(data[4+32+4:], uint16(version))
+		binary.BigEndian.PutUint32(data[4+32+10:], uint32(size))
+		binary.BigEndian.PutUint32(data[4+32+14:], uint32(frames))
+		return data
+	}
+
+	header, _ := mp3header.Parse(testHeaderBits)
+
+	tests := []struct {
+		name           string
+		data           []byte
+		opts           []Option
+		wantFromXing   bool
+		wantDuration   time.Duration
+		wantConfidence Confidence
+		wantWarnings   int
+		wantErr        bool
+	}{
+		{
+			name:           "with frames",
+			data:           generateVBRI(1, 200, 41700),
+			wantFromXing:   true,
+			wantDuration:   ExactDuration(header, 200, 0, 0),
+			wantConfidence: ConfidenceExact,
+		},
+		{
+			name:           "without frames",
+			data:           generateVBRI(1, 0, 41700),
+			wantDuration:   2606 * time.Millisecond,
+			wantConfidence: ConfidenceEstimated,
+		},
+		{
+			name:           "unknown version",
+			data:           generateVBRI(2, 200, 41700),
+			wantDuration:   2606 * time.Millisecond,
+			wantConfidence: ConfidenceSuspect,
+			wantWarnings:   1,
+		},
+		{
+			name:    "unknown version, strict",
+			data:    generateVBRI(2, 200, 41700),
+			opts:    []Option{WithStrict()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := GetInfo(bytes.NewReader(tt.data), int64(len(tt.data)), tt.opts...)
+
+			if tt.wantErr {
+				var version ErrVBRIVersion
+
+				if !errors.As(err, &version) || version.Version != 2 {
+					t.Fatalf("GetInfo() error = %v, want ErrVBRIVersion{2}", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if metadata.DurationFromXing() != tt.wantFromXing {
+				t.Errorf("GetInfo() DurationFromXing() = %v, want %v", metadata.DurationFromXing(), tt.wantFromXing)
+			}
+
+			if metadata.Duration() != tt.wantDuration || metadata.Confidence() != tt.wantConfidence {
+				t.Errorf("GetInfo() = %v (%v), want %v (%v)", metadata.Duration(), metadata.Confidence(), tt.wantDuration, tt.wantConfidence)
+			}
+
+			if !metadata.IsVBR() && tt.wantFromXing {
+				t.Errorf("GetInfo() IsVBR() = false, want true")
+			}
+
+			if len(metadata.Warnings()) != tt.wantWarnings {
+				t.Errorf("GetInfo() Warnings() = %q, want %d", metadata.Warnings(), tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestMetadata_VBRHeaderType(t *testing.T) {
+	withMagic := func(magic string) []byte {
+		data := generateMP3(emptyTag, testHeaderBits, testFrameLength, 100)
+		copy(data[len(emptyTag)+4+32:], magic)
+		binary.BigEndian.PutUint16(data[len(emptyTag)+4+36:], 1) // VBRI version
+		return data
+	}
+
+	// after the tag, the frame header and the side info of MPEG-1 joint stereo
+	offset := int64(len(emptyTag) + 4 + 32)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		want       string
+		wantOffset int64
+	}{
+		{"Xing", withMagic("Xing"), "Xing", offset},
+		{"Info", withMagic("Info"), "Info", offset},
+		{"VBRI", withMagic("VBRI"), "VBRI", offset},
+		{"none", generateMP3(emptyTag, testHeaderBits, testFrameLength, 100), "none", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := GetInfo(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if err != nil {
+				t.Fatalf("GetInfo() error = %v", err)
+			}
+
+			if got := metadata.VBRHeaderType(); got != tt.want {
+				t.Errorf("VBRHeaderType() = %v, want %v", got, tt.want)
+			}
+
+			if got := metadata.VBRHeaderOffset(); got != tt.wantOffset {
+				t.Errorf("VBRHeaderOffset() = %v, want %v", got, tt.wantOffset)
+			}
+		})
+	}
+}